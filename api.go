@@ -0,0 +1,601 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// JSON representation of a catalog entry, as served by the v1 API.
+type apiItem struct {
+	ID           string     `json:"id"`
+	Category     string     `json:"category"`
+	Brand        string     `json:"brand"`
+	Color        string     `json:"color"`
+	Pattern      string     `json:"pattern"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Price        *float64   `json:"price"`
+	Currency     string     `json:"currency"` // e.g EUR, the wardrobe's when empty
+	LastActivity *time.Time `json:"last_activity"`
+	LastNote     string     `json:"last_note"`
+	Hidden       bool       `json:"hidden"`
+	Size         string     `json:"size"`
+	Material     string     `json:"material"`
+	Seasons      []string   `json:"seasons"`
+	Purchased    string     `json:"purchased"` // YYYY-MM-DD
+	Retailer     string     `json:"retailer"`
+	Condition    string     `json:"condition"`
+	State        string     `json:"state"`
+	MinTemp      *float64   `json:"min_temp"` // °C
+	MaxTemp      *float64   `json:"max_temp"` // °C
+	RainOk       *bool      `json:"rain_ok"`
+	// Set with PUT catalog/{id}/lifecycle.
+	Lifecycle     string   `json:"lifecycle"`
+	Disposed      string   `json:"disposed"` // YYYY-MM-DD
+	ResalePrice   *float64 `json:"resale_price"`
+	LifecycleNote string   `json:"lifecycle_note"`
+}
+
+// JSON representation of a suggested outfit.
+type apiSuggestion struct {
+	Items []apiItem `json:"items"`
+	Score float64   `json:"score"`
+}
+
+// JSON representation of a single usage of a catalog entry.
+type apiUsage struct {
+	ID   string    `json:"id"`
+	CID  string    `json:"cid"`
+	Time time.Time `json:"time"`
+	Note string    `json:"note"`
+}
+
+func toAPIItem(c persist.Catalog) apiItem {
+	i := apiItem{
+		ID:            strings.TrimSpace(c.ID),
+		Category:      strings.TrimSpace(c.Category.String),
+		Brand:         strings.TrimSpace(c.Brand.String),
+		Color:         strings.TrimSpace(c.Color.String),
+		Pattern:       strings.TrimSpace(c.Pattern.String),
+		Title:         c.Title.String,
+		Description:   c.Description.String,
+		Currency:      c.Currency,
+		LastNote:      c.LastNote.String,
+		Hidden:        c.Hidden,
+		Size:          c.Size.String,
+		Material:      c.Material.String,
+		Seasons:       []string{},
+		Purchased:     formatDate(c.Purchased),
+		Retailer:      c.Retailer.String,
+		Condition:     c.Condition.String,
+		State:         c.State,
+		Lifecycle:     c.Lifecycle,
+		Disposed:      formatDate(c.Disposed),
+		LifecycleNote: c.LifecycleNote.String,
+	}
+	if c.Seasons.String != "" {
+		i.Seasons = strings.Split(c.Seasons.String, ",")
+	}
+	if c.Price.Valid {
+		i.Price = &c.Price.Float64
+	}
+	if c.LastActivity.Valid {
+		i.LastActivity = &c.LastActivity.Time
+	}
+	if c.MinTemp.Valid {
+		i.MinTemp = &c.MinTemp.Float64
+	}
+	if c.MaxTemp.Valid {
+		i.MaxTemp = &c.MaxTemp.Float64
+	}
+	if c.RainOk.Valid {
+		i.RainOk = &c.RainOk.Bool
+	}
+	if c.ResalePrice.Valid {
+		i.ResalePrice = &c.ResalePrice.Float64
+	}
+	return i
+}
+
+func toAPIUsage(a persist.Activity) apiUsage {
+	return apiUsage{
+		ID:   strings.TrimSpace(a.ID),
+		CID:  strings.TrimSpace(a.CID),
+		Time: a.Ts,
+		Note: a.Note.String,
+	}
+}
+
+// An error with an associated HTTP status, reported to API clients as JSON.
+type apiError struct {
+	Status int
+	Err    error
+}
+
+func (e apiError) Error() string {
+	return e.Err.Error()
+}
+
+func (e apiError) Unwrap() error {
+	return e.Err
+}
+
+// Returns an error which will be reported with the given status.
+func statusErr(status int, format string, args ...interface{}) error {
+	return apiError{Status: status, Err: fmt.Errorf(format, args...)}
+}
+
+// Like HandlerFuncE, but reports errors as a JSON body with a meaningful status code.
+// Clients sign in by posting username and password to /login, and sending back the session cookie.
+func APIFuncE(f func(http.ResponseWriter, *http.Request) error) http.Handler {
+	return requireSession(http.HandlerFunc(func(response http.ResponseWriter, req *http.Request) {
+		err := f(response, req)
+		if err == nil {
+			return
+		}
+		status := http.StatusInternalServerError
+		var ae apiError
+		if errors.As(err, &ae) {
+			status = ae.Status
+		} else if errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		writeJSON(response, status, map[string]string{"error": err.Error()})
+	}), func(response http.ResponseWriter, req *http.Request) {
+		writeJSON(response, http.StatusUnauthorized, map[string]string{"error": errNoSession.Error()})
+	})
+}
+
+func writeJSON(response http.ResponseWriter, status int, v interface{}) error {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	err := json.NewEncoder(response).Encode(v)
+	if err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+	return nil
+}
+
+func readJSON(req *http.Request, v interface{}) error {
+	dec := json.NewDecoder(req.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err != nil {
+		return statusErr(http.StatusBadRequest, "decode request body: %w", err)
+	}
+	return nil
+}
+
+// Routes requests under /api/v1/. Paths:
+//
+//	GET    catalog?search=q         list (or search, see parseSearch) the catalog
+//	POST   catalog                  create a catalog entry
+//	GET    catalog/{id}             fetch an entry
+//	PUT    catalog/{id}             create or replace an entry
+//	DELETE catalog/{id}             delete an entry and its usage history
+//	PUT    catalog/{id}/hidden      set whether the entry is hidden
+//	PUT    catalog/{id}/lifecycle   set where the entry is in its life, e.g sold, see lifecycles
+//	PUT    catalog/{id}/state       move the entry to another state, e.g laundry, see nextStates
+//	GET    catalog/{id}/usage       list the usage history of an entry
+//	POST   catalog/{id}/usage       mark the entry as used now
+//	PUT    catalog/{id}/note        set the note on the latest usage of an entry
+//	GET    usage                    list all usage
+//	GET    usage/{id}               fetch a single usage
+//	PUT    usage/{id}               update the time and note of a usage
+//	DELETE usage/{id}               delete a usage
+//	GET    suggest?n=5              suggest outfits and items to wear next, see suggest
+func handleAPI(response http.ResponseWriter, req *http.Request) error {
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1"), "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "catalog":
+		switch req.Method {
+		case http.MethodGet:
+			return apiListCatalog(response, req)
+		case http.MethodPost:
+			return apiPutItem(response, req, "")
+		}
+	case len(parts) == 2 && parts[0] == "catalog":
+		switch req.Method {
+		case http.MethodGet:
+			return apiGetItem(response, req, parts[1])
+		case http.MethodPut:
+			return apiPutItem(response, req, parts[1])
+		case http.MethodDelete:
+			return apiDeleteItem(response, req, parts[1])
+		}
+	case len(parts) == 3 && parts[0] == "catalog" && parts[2] == "hidden":
+		if req.Method == http.MethodPut {
+			return apiSetHidden(response, req, parts[1])
+		}
+	case len(parts) == 3 && parts[0] == "catalog" && parts[2] == "lifecycle":
+		if req.Method == http.MethodPut {
+			return apiSetLifecycle(response, req, parts[1])
+		}
+	case len(parts) == 3 && parts[0] == "catalog" && parts[2] == "state":
+		if req.Method == http.MethodPut {
+			return apiSetState(response, req, parts[1])
+		}
+	case len(parts) == 3 && parts[0] == "catalog" && parts[2] == "usage":
+		switch req.Method {
+		case http.MethodGet:
+			return apiItemUsage(response, req, parts[1])
+		case http.MethodPost:
+			return apiUse(response, req, parts[1])
+		}
+	case len(parts) == 3 && parts[0] == "catalog" && parts[2] == "note":
+		if req.Method == http.MethodPut {
+			return apiUseNote(response, req, parts[1])
+		}
+	case len(parts) == 1 && parts[0] == "usage":
+		if req.Method == http.MethodGet {
+			return apiListUsage(response, req)
+		}
+	case len(parts) == 2 && parts[0] == "usage":
+		switch req.Method {
+		case http.MethodGet:
+			return apiGetUsage(response, req, parts[1])
+		case http.MethodPut:
+			return apiPutUsage(response, req, parts[1])
+		case http.MethodDelete:
+			return apiDeleteUsage(response, req, parts[1])
+		}
+	case len(parts) == 1 && parts[0] == "suggest":
+		if req.Method == http.MethodGet {
+			return apiSuggest(response, req)
+		}
+	default:
+		return statusErr(http.StatusNotFound, "no such endpoint: %v", req.URL.Path)
+	}
+	return statusErr(http.StatusMethodNotAllowed, "method %v not allowed on %v", req.Method, req.URL.Path)
+}
+
+func apiListCatalog(response http.ResponseWriter, req *http.Request) error {
+	wid := currentWardrobe(req.Context())
+	search := req.URL.Query().Get("search")
+	var cs []persist.Catalog
+	var weatherErr string
+	var err error
+	if search == "" {
+		cs, err = store.ListCatalog(req.Context(), wid)
+	} else {
+		cs, weatherErr, err = searchCatalog(req.Context(), wid, parseSearch(search))
+	}
+	if err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	if weatherErr != "" {
+		// The weather filter was dropped; the results are still worth returning.
+		response.Header().Set("X-Weather-Error", weatherErr)
+	}
+	items := make([]apiItem, 0, len(cs))
+	for _, c := range cs {
+		items = append(items, toAPIItem(c))
+	}
+	return writeJSON(response, http.StatusOK, items)
+}
+
+func apiGetItem(response http.ResponseWriter, req *http.Request, id string) error {
+	c, err := store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	return writeJSON(response, http.StatusOK, toAPIItem(c))
+}
+
+// Creates or replaces a catalog entry. If id is empty, a new entry is created.
+func apiPutItem(response http.ResponseWriter, req *http.Request, id string) error {
+	wid := currentWardrobe(req.Context())
+	var in apiItem
+	err := readJSON(req, &in)
+	if err != nil {
+		return err
+	}
+	status := http.StatusOK
+	if id == "" {
+		id = uuid.NewString()
+		status = http.StatusCreated
+	} else if _, err := store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: wid}); errors.Is(err, sql.ErrNoRows) {
+		status = http.StatusCreated
+	} else if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	price := nf(in.Price)
+	purchased, err := parseDate(in.Purchased)
+	if err != nil {
+		return statusErr(http.StatusBadRequest, "%w", err)
+	}
+	home, err := homeCurrency(req.Context())
+	if err != nil {
+		return err
+	}
+	currency, err := parseCurrency(in.Currency, home)
+	if err != nil {
+		return statusErr(http.StatusBadRequest, "%w", err)
+	}
+	_, err = store.PutItem(req.Context(), persist.PutItemParams{
+		ID:          id,
+		Category:    ns([]string{in.Category}),
+		Brand:       ns([]string{in.Brand}),
+		Color:       ns([]string{in.Color}),
+		Pattern:     ns([]string{in.Pattern}),
+		Title:       ns([]string{in.Title}),
+		Description: ns([]string{in.Description}),
+		Price:       price,
+		WID:         wid,
+		Size:        ns([]string{in.Size}),
+		Material:    ns([]string{in.Material}),
+		Seasons:     joinSeasons(in.Seasons),
+		Purchased:   purchased,
+		Retailer:    ns([]string{in.Retailer}),
+		Condition:   ns([]string{in.Condition}),
+		MinTemp:     nf(in.MinTemp),
+		MaxTemp:     nf(in.MaxTemp),
+		RainOk:      nb(in.RainOk),
+		Currency:    currency,
+	})
+	if err != nil {
+		return fmt.Errorf("put item %v: %w", id, err)
+	}
+	c, err := store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	return writeJSON(response, status, toAPIItem(c))
+}
+
+func nf(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Valid: true, Float64: *f}
+}
+
+func nb(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Valid: true, Bool: *b}
+}
+
+func apiDeleteItem(response http.ResponseWriter, req *http.Request, id string) error {
+	_, err := store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	err = deleteItem(req.Context(), id)
+	if err != nil {
+		return fmt.Errorf("delete %v: %w", id, err)
+	}
+	response.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func apiSetHidden(response http.ResponseWriter, req *http.Request, id string) error {
+	wid := currentWardrobe(req.Context())
+	var in struct {
+		Hidden bool `json:"hidden"`
+	}
+	err := readJSON(req, &in)
+	if err != nil {
+		return err
+	}
+	_, err = store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	err = setHidden(req.Context(), id, in.Hidden)
+	if err != nil {
+		return fmt.Errorf("set %v hidden (%v): %w", id, in.Hidden, err)
+	}
+	return apiGetItem(response, req, id)
+}
+
+func apiSetLifecycle(response http.ResponseWriter, req *http.Request, id string) error {
+	var in struct {
+		Lifecycle   string   `json:"lifecycle"`
+		Disposed    string   `json:"disposed"` // YYYY-MM-DD
+		ResalePrice *float64 `json:"resale_price"`
+		Note        string   `json:"lifecycle_note"`
+	}
+	err := readJSON(req, &in)
+	if err != nil {
+		return err
+	}
+	if !validLifecycle(in.Lifecycle) {
+		return statusErr(http.StatusBadRequest, "unknown lifecycle %q", in.Lifecycle)
+	}
+	l := lifecycleChange{Stage: in.Lifecycle, Resale: nf(in.ResalePrice), Note: ns([]string{in.Note})}
+	l.Date, err = parseDate(in.Disposed)
+	if err != nil {
+		return statusErr(http.StatusBadRequest, "%w", err)
+	}
+	err = store.InTx(req.Context(), func(store Store) error {
+		return setLifecycle(req.Context(), store, id, l)
+	})
+	if err != nil {
+		return err
+	}
+	return apiGetItem(response, req, id)
+}
+
+func apiSetState(response http.ResponseWriter, req *http.Request, id string) error {
+	var in struct {
+		State string `json:"state"`
+	}
+	err := readJSON(req, &in)
+	if err != nil {
+		return err
+	}
+	c, err := store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	if in.State != c.State && !containsString(nextStates(c.State), in.State) {
+		return statusErr(http.StatusConflict, "can't go from %v to %q", c.State, in.State)
+	}
+	err = moveItems(req.Context(), []string{id}, in.State)
+	if err != nil {
+		return fmt.Errorf("move %v to %v: %w", id, in.State, err)
+	}
+	return apiGetItem(response, req, id)
+}
+
+func apiItemUsage(response http.ResponseWriter, req *http.Request, id string) error {
+	wid := currentWardrobe(req.Context())
+	_, err := store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	as, err := store.GetAllUsage(req.Context(), persist.GetAllUsageParams{CID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("load history %v: %w", id, err)
+	}
+	return writeUsage(response, as)
+}
+
+func apiUse(response http.ResponseWriter, req *http.Request, id string) error {
+	wid := currentWardrobe(req.Context())
+	_, err := store.GetCatalog(req.Context(), persist.GetCatalogParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	err = addUsage(req.Context(), store, id)
+	if err != nil {
+		return fmt.Errorf("saving usage: %w", err)
+	}
+	a, err := store.GetLastUsage(req.Context(), persist.GetLastUsageParams{CID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get last usage %v: %w", id, err)
+	}
+	return writeJSON(response, http.StatusCreated, toAPIUsage(a))
+}
+
+func apiUseNote(response http.ResponseWriter, req *http.Request, id string) error {
+	var in struct {
+		Note string `json:"note"`
+	}
+	err := readJSON(req, &in)
+	if err != nil {
+		return err
+	}
+	err = addUsageNote(req.Context(), id, in.Note)
+	if err != nil {
+		return fmt.Errorf("saving usage note: %w", err)
+	}
+	a, err := store.GetLastUsage(req.Context(), persist.GetLastUsageParams{CID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("get last usage %v: %w", id, err)
+	}
+	return writeJSON(response, http.StatusOK, toAPIUsage(a))
+}
+
+func apiListUsage(response http.ResponseWriter, req *http.Request) error {
+	as, err := store.ListUsage(req.Context(), currentWardrobe(req.Context()))
+	if err != nil {
+		return fmt.Errorf("list activity: %w", err)
+	}
+	return writeUsage(response, as)
+}
+
+func apiGetUsage(response http.ResponseWriter, req *http.Request, id string) error {
+	a, err := store.GetUsage(req.Context(), persist.GetUsageParams{ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("get usage %v: %w", id, err)
+	}
+	return writeJSON(response, http.StatusOK, toAPIUsage(a))
+}
+
+func apiPutUsage(response http.ResponseWriter, req *http.Request, id string) error {
+	wid := currentWardrobe(req.Context())
+	var in struct {
+		Time *time.Time `json:"time"`
+		Note *string    `json:"note"`
+	}
+	err := readJSON(req, &in)
+	if err != nil {
+		return err
+	}
+	a, err := store.GetUsage(req.Context(), persist.GetUsageParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get usage %v: %w", id, err)
+	}
+	if in.Time != nil {
+		a.Ts = in.Time.UTC()
+	}
+	if in.Note != nil {
+		a.Note = ns([]string{*in.Note})
+	}
+	_, err = store.PutUsage(req.Context(), persist.PutUsageParams{
+		Note: a.Note,
+		Ts:   a.Ts,
+		ID:   id,
+		WID:  wid,
+	})
+	if err != nil {
+		return fmt.Errorf("put usage: %w", err)
+	}
+	err = refreshLastUse(req.Context(), store, a.CID)
+	if err != nil {
+		return fmt.Errorf("refreshing catalog usage %v: %w", a.CID, err)
+	}
+	return writeJSON(response, http.StatusOK, toAPIUsage(a))
+}
+
+func apiDeleteUsage(response http.ResponseWriter, req *http.Request, id string) error {
+	_, err := deleteUsage(req.Context(), id)
+	if err != nil {
+		return fmt.Errorf("delete usage %v: %w", id, err)
+	}
+	response.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func writeUsage(response http.ResponseWriter, as []persist.Activity) error {
+	us := make([]apiUsage, 0, len(as))
+	for _, a := range as {
+		us = append(us, toAPIUsage(a))
+	}
+	return writeJSON(response, http.StatusOK, us)
+}
+
+func apiSuggest(response http.ResponseWriter, req *http.Request) error {
+	n := 5
+	if q := req.URL.Query().Get("n"); q != "" {
+		var err error
+		n, err = strconv.Atoi(q)
+		if err != nil || n < 1 {
+			return statusErr(http.StatusBadRequest, "n must be a positive number, got %q", q)
+		}
+	}
+	s, err := suggestForWeather(req.Context(), n)
+	if err != nil {
+		return fmt.Errorf("suggest: %w", err)
+	}
+	out := struct {
+		Outfits    []apiSuggestion `json:"outfits"`
+		Items      []apiItem       `json:"items"`
+		Weather    *forecast       `json:"weather"`
+		WeatherErr string          `json:"weather_error,omitempty"`
+	}{Outfits: []apiSuggestion{}, Items: []apiItem{}, Weather: s.Weather, WeatherErr: s.WeatherErr}
+	for _, o := range s.Outfits {
+		as := apiSuggestion{Score: o.Score}
+		for _, i := range o.Items {
+			as.Items = append(as.Items, toAPIItem(i.Catalog))
+		}
+		out.Outfits = append(out.Outfits, as)
+	}
+	for _, i := range s.Items {
+		out.Items = append(out.Items, toAPIItem(i.Catalog))
+	}
+	return writeJSON(response, http.StatusOK, out)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The seasons an item can be marked as suitable for, in the order they're shown.
+var seasons = []string{"spring", "summer", "autumn", "winter"}
+
+// The conditions an item can be in, from best to worst.
+var conditions = []string{"new", "like new", "good", "fair", "worn"}
+
+// Format of purchase dates in forms and csvs.
+const dateFormat = "2006-01-02"
+
+// Parses a purchase date, e.g 2022-04-30. Empty is null.
+func parseDate(s string) (sql.NullTime, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(dateFormat, s)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("parse date %q: expected YYYY-MM-DD", s)
+	}
+	return sql.NullTime{Valid: true, Time: t}, nil
+}
+
+func formatDate(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(dateFormat)
+}
+
+// Joins the seasons checked in a form into how they're stored, e.g "spring,summer".
+func joinSeasons(ss []string) sql.NullString {
+	var picked []string
+	for _, s := range seasons {
+		if containsString(ss, s) {
+			picked = append(picked, s)
+		}
+	}
+	return ns([]string{strings.Join(picked, ",")})
+}
+
+// Parses an amount of money, e.g 30.99. Empty is null.
+func parsePrice(s string) (sql.NullFloat64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return sql.NullFloat64{}, nil
+	}
+	p, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return sql.NullFloat64{}, fmt.Errorf("parse price %q: expected e.g 30.99", s)
+	}
+	return sql.NullFloat64{Valid: true, Float64: p}, nil
+}
+
+// Formats an amount of money as parsePrice reads it. Null is empty.
+func formatPrice(p sql.NullFloat64) string {
+	if !p.Valid {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", p.Float64)
+}
+
+// Parses the temperature, in °C, an item is good down or up to. Empty is null.
+func parseTemp(s string) (sql.NullFloat64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return sql.NullFloat64{}, nil
+	}
+	t, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return sql.NullFloat64{}, fmt.Errorf("parse temperature %q: expected °C", s)
+	}
+	return sql.NullFloat64{Valid: true, Float64: t}, nil
+}
+
+func formatTemp(t sql.NullFloat64) string {
+	if !t.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(t.Float64, 'f', -1, 64)
+}
+
+// Parses whether an item can be worn in the rain, as true or false. Empty is null, for either.
+func parseRain(s string) (sql.NullBool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return sql.NullBool{}, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return sql.NullBool{}, fmt.Errorf("parse rain ok %q: expected true or false", s)
+	}
+	return sql.NullBool{Valid: true, Bool: b}, nil
+}
+
+func formatRain(b sql.NullBool) string {
+	if !b.Valid {
+		return ""
+	}
+	return strconv.FormatBool(b.Bool)
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	sessionCookie = "gorgina_session"
+	// How long a sign in lasts.
+	sessionTTL        = 30 * 24 * time.Hour
+	minPasswordLength = 8
+)
+
+// Returned by sessionUser when the request isn't signed in, or its session has expired.
+var errNoSession = errors.New("not signed in")
+
+type ctxKey int
+
+const (
+	userKey ctxKey = iota
+	sessionKey
+)
+
+// The signed in user, for requests which went through requireSession.
+func currentUser(ctx context.Context) persist.User {
+	u, _ := ctx.Value(userKey).(persist.User)
+	return u
+}
+
+// The session of the signed in user, for requests which went through requireSession.
+func currentSession(ctx context.Context) persist.Session {
+	s, _ := ctx.Value(sessionKey).(persist.Session)
+	return s
+}
+
+// The wardrobe the signed in user is looking at. Everything they see and change belongs to it.
+func currentWardrobe(ctx context.Context) string {
+	return currentSession(ctx).WID
+}
+
+// Acts as the user looking at the wardrobe, for work done outside of a request, e.g from the command line.
+func withWardrobe(ctx context.Context, u persist.User, wid string) context.Context {
+	ctx = context.WithValue(ctx, userKey, u)
+	return context.WithValue(ctx, sessionKey, persist.Session{UID: u.ID, WID: wid})
+}
+
+// Serves h only to signed in users, who can then be found with currentUser. Everyone else gets unauthorized.
+func requireSession(h http.Handler, unauthorized http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, req *http.Request) {
+		u, s, err := sessionUser(req)
+		if errors.Is(err, errNoSession) {
+			unauthorized(response, req)
+			return
+		} else if err != nil {
+			response.WriteHeader(502)
+			response.Write([]byte("Error: load session: " + err.Error()))
+			return
+		}
+		ctx := context.WithValue(req.Context(), userKey, u)
+		ctx = context.WithValue(ctx, sessionKey, s)
+		h.ServeHTTP(response, req.WithContext(ctx))
+	})
+}
+
+// Sends the browser to the login page. HTMX requests are redirected as a whole page, rather than
+// swapping the login page into a component.
+func redirectToLogin(response http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("HX-Request") == "true" {
+		response.Header().Set("HX-Redirect", "/login")
+		response.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	http.Redirect(response, req, "/login", http.StatusSeeOther)
+}
+
+// Finds the session on the request, and the user owning it.
+func sessionUser(req *http.Request) (persist.User, persist.Session, error) {
+	ctx := req.Context()
+	c, err := req.Cookie(sessionCookie)
+	if err != nil {
+		return persist.User{}, persist.Session{}, errNoSession
+	}
+	s, err := store.GetSession(ctx, hashToken(c.Value))
+	if errors.Is(err, sql.ErrNoRows) {
+		return persist.User{}, s, errNoSession
+	} else if err != nil {
+		return persist.User{}, s, fmt.Errorf("get session: %w", err)
+	}
+	if time.Now().After(s.Expires) {
+		return persist.User{}, s, errNoSession
+	}
+	u, err := store.GetUser(ctx, s.UID)
+	if err != nil {
+		return u, s, fmt.Errorf("get user %v: %w", s.UID, err)
+	}
+	// The user may have left the wardrobe from another session.
+	member, err := store.IsWardrobeMember(ctx, persist.IsWardrobeMemberParams{WID: s.WID, UID: u.ID})
+	if err != nil {
+		return u, s, fmt.Errorf("check wardrobe membership: %w", err)
+	}
+	if !member {
+		s.WID, err = defaultWardrobe(ctx, store, u.ID)
+		if err != nil {
+			return u, s, err
+		}
+		err = store.SetSessionWardrobe(ctx, persist.SetSessionWardrobeParams{WID: s.WID, ID: s.ID})
+		if err != nil {
+			return u, s, fmt.Errorf("set session wardrobe: %w", err)
+		}
+	}
+	return u, s, nil
+}
+
+// The wardrobe new sessions start in: the user's oldest.
+func defaultWardrobe(ctx context.Context, store Store, uid string) (string, error) {
+	ws, err := store.ListUserWardrobes(ctx, uid)
+	if err != nil {
+		return "", fmt.Errorf("list wardrobes: %w", err)
+	}
+	if len(ws) == 0 {
+		return "", fmt.Errorf("user %v has no wardrobe", uid)
+	}
+	return ws[0].ID, nil
+}
+
+// Session ids are stored hashed, so the database alone can't be used to sign in.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// A random secret, e.g for a session cookie.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Whether the request came over https. Behind a proxy which terminates TLS, such as fly's, the
+// proxy says what was used.
+func isHTTPS(req *http.Request) bool {
+	return req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// Starts a new session for the user, and sets its cookie on the response.
+func startSession(ctx context.Context, response http.ResponseWriter, req *http.Request, uid string) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	wid, err := defaultWardrobe(ctx, store, uid)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	err = store.DeleteExpiredSessions(ctx, now)
+	if err != nil {
+		return fmt.Errorf("delete expired sessions: %w", err)
+	}
+	err = store.CreateSession(ctx, persist.CreateSessionParams{
+		ID:      hashToken(token),
+		UID:     uid,
+		Created: now,
+		Expires: now.Add(sessionTTL),
+		WID:     wid,
+	})
+	if err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	http.SetCookie(response, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  now.Add(sessionTTL),
+		HttpOnly: true,
+		Secure:   isHTTPS(req),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// Compared against when the username doesn't exist, so that failed logins take as long either way.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("gorgina"), bcrypt.DefaultCost)
+
+// Checks the credentials, returning the user they belong to.
+func checkPassword(ctx context.Context, username, password string) (persist.User, bool, error) {
+	u, err := store.GetUserByName(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return u, false, nil
+	} else if err != nil {
+		return u, false, fmt.Errorf("get user %v: %w", username, err)
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
+	return u, err == nil, nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %v characters", minPasswordLength)
+	}
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(h), nil
+}
+
+// Creates an account, along with its private wardrobe. Usernames are unique.
+func addUser(ctx context.Context, store Store, username, password string) (persist.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return persist.User{}, fmt.Errorf("username is required")
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return persist.User{}, err
+	}
+	u := persist.User{
+		ID:           uuid.NewString(),
+		Username:     username,
+		PasswordHash: hash,
+		Created:      time.Now().UTC(),
+	}
+	_, err = store.GetUserByName(ctx, username)
+	if err == nil {
+		return u, fmt.Errorf("user %v already exists", username)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return u, fmt.Errorf("get user %v: %w", username, err)
+	}
+	err = store.CreateUser(ctx, persist.CreateUserParams{
+		ID:           u.ID,
+		Username:     u.Username,
+		PasswordHash: u.PasswordHash,
+		Created:      u.Created,
+	})
+	if err != nil {
+		return u, fmt.Errorf("create user: %w", err)
+	}
+	_, err = createWardrobe(ctx, store, u.ID, username)
+	if err != nil {
+		return u, err
+	}
+	return u, nil
+}
+
+// The login page, which offers to create the first account when there are none yet.
+func handleLogin(response http.ResponseWriter, req *http.Request) error {
+	n, err := store.CountUsers(req.Context())
+	if err != nil {
+		return fmt.Errorf("count users: %w", err)
+	}
+	v := loginView{Signup: n == 0}
+	if req.Method == http.MethodPost {
+		v.Username = req.FormValue("username")
+		password := req.FormValue("password")
+		var u persist.User
+		var ok bool
+		if v.Signup {
+			// Only the first account can be created from the web, the rest are made with 'gorgina user add'.
+			err = store.InTx(req.Context(), func(store Store) error {
+				n, err := store.CountUsers(req.Context())
+				if err != nil {
+					return fmt.Errorf("count users: %w", err)
+				}
+				if n > 0 {
+					return fmt.Errorf("an account already exists, sign in instead")
+				}
+				u, err = addUser(req.Context(), store, v.Username, password)
+				if err != nil {
+					return err
+				}
+				// Take over anything from before accounts existed.
+				err = store.ClaimOrphanWardrobes(req.Context(), u.ID)
+				if err != nil {
+					return fmt.Errorf("claim wardrobes: %w", err)
+				}
+				return nil
+			})
+			ok = err == nil
+			if err != nil {
+				v.Error = err.Error()
+			}
+		} else {
+			u, ok, err = checkPassword(req.Context(), v.Username, password)
+			if err != nil {
+				return fmt.Errorf("check password: %w", err)
+			}
+			if !ok {
+				v.Error = "Incorrect username or password"
+			}
+		}
+		if ok {
+			err = startSession(req.Context(), response, req, u.ID)
+			if err != nil {
+				return fmt.Errorf("start session: %w", err)
+			}
+			http.Redirect(response, req, "/", http.StatusSeeOther)
+			return nil
+		}
+		response.WriteHeader(http.StatusUnauthorized)
+	}
+	r, err := loginPage(v)
+	if err != nil {
+		return fmt.Errorf("render login: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+// Ends the current session. POST only, so other sites can't sign users out.
+func handleLogout(response http.ResponseWriter, req *http.Request) error {
+	if req.Method != http.MethodPost {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return nil
+	}
+	if c, err := req.Cookie(sessionCookie); err == nil {
+		err = store.DeleteSession(req.Context(), hashToken(c.Value))
+		if err != nil {
+			return fmt.Errorf("delete session: %w", err)
+		}
+	}
+	http.SetCookie(response, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	http.Redirect(response, req, "/login", http.StatusSeeOther)
+	return nil
+}
+
+// Entry point for 'gorgina user <add|passwd> <username>'. The password is read from stdin.
+func runUser(args []string) error {
+	ctx := context.Background()
+	if len(args) != 2 {
+		return fmt.Errorf("usage: user <add|passwd> <username>")
+	}
+	s, err := openStore(os.Getenv(dbUrlKey))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	ms, err := s.migrations()
+	if err != nil {
+		return err
+	}
+	err = migrateUp(ctx, s.db, ms)
+	if err != nil {
+		return fmt.Errorf("migrate DB: %w", err)
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("read password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	switch args[0] {
+	case "add":
+		return s.InTx(ctx, func(store Store) error {
+			_, err := addUser(ctx, store, args[1], password)
+			return err
+		})
+	case "passwd":
+		u, err := s.GetUserByName(ctx, args[1])
+		if err != nil {
+			return fmt.Errorf("get user %v: %w", args[1], err)
+		}
+		hash, err := hashPassword(password)
+		if err != nil {
+			return err
+		}
+		return s.InTx(ctx, func(store Store) error {
+			err := store.SetPassword(ctx, persist.SetPasswordParams{PasswordHash: hash, ID: u.ID})
+			if err != nil {
+				return fmt.Errorf("set password: %w", err)
+			}
+			// Sign out everywhere, in case the old password leaked.
+			err = store.DeleteUserSessions(ctx, u.ID)
+			if err != nil {
+				return fmt.Errorf("delete sessions: %w", err)
+			}
+			return nil
+		})
+	default:
+		return fmt.Errorf("unknown user command %q, expected add or passwd", args[0])
+	}
+}
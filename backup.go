@@ -0,0 +1,538 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Version of the backup format written by writeBackup. Bump it when the archive changes, and
+// teach restoreBackup to read the old versions.
+const backupVersion = 10
+
+// Name of the JSON document within a backup archive. Photos sit beside it under photos/.
+const backupManifest = "backup.json"
+
+// Everything in a wardrobe, row for row. Accounts and sessions are not included, so a backup
+// can be restored by anyone into any wardrobe.
+type backup struct {
+	Version        int
+	Created        time.Time
+	Wardrobe       string
+	Catalog        []persist.Catalog
+	Activity       []persist.Activity
+	Photos         []persist.ItemPhoto
+	Outfits        []persist.Outfit
+	OutfitItems    []persist.OutfitItem
+	OutfitActivity []persist.OutfitActivity
+	// Since version 2.
+	Tags        []persist.Tag
+	CatalogTags []persist.CatalogTag
+	// Since version 3. Older backups keep the categories the wardrobe already has. Categories have
+	// an outfit slot since version 10.
+	Categories []persist.Category
+	// Since version 4.
+	Plans []persist.PlannedUsage
+	// Since version 5. Items in older backups are clean.
+	StateChanges []persist.StateChange
+	// Since version 6.
+	Loans []persist.Loan
+	// Since version 8.
+	Wishlist []persist.Wish
+	// Since version 9. Older backups keep the currency and rates the wardrobe already has.
+	Currency string
+	Rates    []persist.ExchangeRate
+}
+
+// Writes a zip of the current wardrobe: backup.json, and the files of each photo.
+func writeBackup(ctx context.Context, w io.Writer) error {
+	wid := currentWardrobe(ctx)
+	b := backup{Version: backupVersion, Created: time.Now().UTC()}
+	ward, err := store.GetWardrobe(ctx, wid)
+	if err != nil {
+		return fmt.Errorf("get wardrobe: %w", err)
+	}
+	b.Wardrobe = ward.Name
+	b.Currency = ward.Currency
+	if b.Catalog, err = store.ListCatalog(ctx, wid); err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	if b.Activity, err = store.ListUsage(ctx, wid); err != nil {
+		return fmt.Errorf("list usage: %w", err)
+	}
+	if b.Photos, err = store.ListAllPhotos(ctx, wid); err != nil {
+		return fmt.Errorf("list photos: %w", err)
+	}
+	if b.Outfits, err = store.ListOutfits(ctx, wid); err != nil {
+		return fmt.Errorf("list outfits: %w", err)
+	}
+	if b.OutfitItems, err = store.ListAllOutfitItems(ctx, wid); err != nil {
+		return fmt.Errorf("list outfit items: %w", err)
+	}
+	if b.OutfitActivity, err = store.ListAllOutfitUsage(ctx, wid); err != nil {
+		return fmt.Errorf("list outfit usage: %w", err)
+	}
+	if b.Tags, err = store.ListTags(ctx, wid); err != nil {
+		return fmt.Errorf("list tags: %w", err)
+	}
+	if b.CatalogTags, err = store.ListAllCatalogTags(ctx, wid); err != nil {
+		return fmt.Errorf("list item tags: %w", err)
+	}
+	if b.Categories, err = store.ListCategories(ctx, wid); err != nil {
+		return fmt.Errorf("list categories: %w", err)
+	}
+	if b.Plans, err = store.ListAllPlans(ctx, wid); err != nil {
+		return fmt.Errorf("list plans: %w", err)
+	}
+	if b.StateChanges, err = store.ListAllStateChanges(ctx, wid); err != nil {
+		return fmt.Errorf("list state changes: %w", err)
+	}
+	if b.Loans, err = store.ListLoans(ctx, wid); err != nil {
+		return fmt.Errorf("list loans: %w", err)
+	}
+	if b.Wishlist, err = store.ListWishlist(ctx, wid); err != nil {
+		return fmt.Errorf("list wishlist: %w", err)
+	}
+	if b.Rates, err = store.ListRates(ctx, wid); err != nil {
+		return fmt.Errorf("list rates: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(backupManifest)
+	if err != nil {
+		return fmt.Errorf("create %v: %w", backupManifest, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "\t")
+	err = enc.Encode(b)
+	if err != nil {
+		return fmt.Errorf("encode backup: %w", err)
+	}
+	for _, p := range b.Photos {
+		id := strings.TrimSpace(p.ID)
+		for _, thumb := range []bool{false, true} {
+			err = copyToZip(zw, backupPhotoName(id, thumb), photoPath(id, thumb))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err = zw.Close()
+	if err != nil {
+		return fmt.Errorf("close zip: %w", err)
+	}
+	return nil
+}
+
+// Where a photo file is kept within a backup archive.
+func backupPhotoName(id string, thumb bool) string {
+	return "photos/" + filepath.Base(photoPath(id, thumb))
+}
+
+func copyToZip(zw *zip.Writer, name string, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %v: %w", path, err)
+	}
+	defer src.Close()
+	dst, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %v: %w", name, err)
+	}
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		return fmt.Errorf("copy %v: %w", path, err)
+	}
+	return nil
+}
+
+// Loads a backup archive into the current wardrobe, which must be empty. Rows keep their ids, so
+// restoring into the database the backup came from fails unless the originals were deleted.
+func restoreBackup(ctx context.Context, zr *zip.Reader) error {
+	wid := currentWardrobe(ctx)
+	mf, err := zr.Open(backupManifest)
+	if err != nil {
+		return fmt.Errorf("open %v: %w", backupManifest, err)
+	}
+	defer mf.Close()
+	var b backup
+	err = json.NewDecoder(mf).Decode(&b)
+	if err != nil {
+		return fmt.Errorf("decode %v: %w", backupManifest, err)
+	}
+	if b.Version < 1 || b.Version > backupVersion {
+		return fmt.Errorf("unsupported backup version %v, expected at most %v", b.Version, backupVersion)
+	}
+
+	err = checkBackupRefs(b)
+	if err != nil {
+		return err
+	}
+
+	var written []string
+	err = store.InTx(ctx, func(store Store) error {
+		cs, err := store.ListCatalog(ctx, wid)
+		if err != nil {
+			return fmt.Errorf("list catalog: %w", err)
+		}
+		outfits, err := store.ListOutfits(ctx, wid)
+		if err != nil {
+			return fmt.Errorf("list outfits: %w", err)
+		}
+		if len(cs) > 0 || len(outfits) > 0 {
+			return fmt.Errorf("backups can only be restored into an empty wardrobe")
+		}
+
+		for _, c := range b.Catalog {
+			if c.State == "" {
+				c.State = stateClean
+			}
+			// Items backed up before version 7 are active, or archived when hidden.
+			if c.Lifecycle == "" {
+				c.Lifecycle = lifecycleActive
+				if c.Hidden {
+					c.Lifecycle = lifecycleArchived
+				}
+			}
+			// Prices were in dollars before version 9.
+			if c.Currency == "" {
+				c.Currency = "USD"
+			}
+			err = store.RestoreItem(ctx, persist.RestoreItemParams{
+				ID:              c.ID,
+				Category:        c.Category,
+				Brand:           c.Brand,
+				Color:           c.Color,
+				Pattern:         c.Pattern,
+				Title:           c.Title,
+				Description:     c.Description,
+				Price:           c.Price,
+				LastActivity:    c.LastActivity,
+				LastNote:        c.LastNote,
+				Hidden:          c.Hidden,
+				WID:             wid,
+				Size:            c.Size,
+				Material:        c.Material,
+				Seasons:         c.Seasons,
+				Purchased:       c.Purchased,
+				Retailer:        c.Retailer,
+				Condition:       c.Condition,
+				SkipSuggest:     c.SkipSuggest,
+				MinTemp:         c.MinTemp,
+				MaxTemp:         c.MaxTemp,
+				RainOk:          c.RainOk,
+				State:           c.State,
+				WearsSinceClean: c.WearsSinceClean,
+				Disposed:        c.Disposed,
+				Lifecycle:       c.Lifecycle,
+				ResalePrice:     c.ResalePrice,
+				LifecycleNote:   c.LifecycleNote,
+				Currency:        c.Currency,
+			})
+			if err != nil {
+				return fmt.Errorf("restore item %v: %w", c.ID, err)
+			}
+		}
+		for _, o := range b.Outfits {
+			err = store.RestoreOutfit(ctx, persist.RestoreOutfitParams{ID: o.ID, Name: o.Name, LastActivity: o.LastActivity, WID: wid})
+			if err != nil {
+				return fmt.Errorf("restore outfit %v: %w", o.ID, err)
+			}
+		}
+		for _, oi := range b.OutfitItems {
+			err = store.AddOutfitItem(ctx, persist.AddOutfitItemParams{OID: oi.OID, CID: oi.CID})
+			if err != nil {
+				return fmt.Errorf("restore outfit item %v: %w", oi.CID, err)
+			}
+		}
+		for _, oa := range b.OutfitActivity {
+			err = store.RestoreOutfitUsage(ctx, persist.RestoreOutfitUsageParams{ID: oa.ID, OID: oa.OID, Ts: oa.Ts, Note: oa.Note})
+			if err != nil {
+				return fmt.Errorf("restore outfit usage %v: %w", oa.ID, err)
+			}
+		}
+		for _, a := range b.Activity {
+			err = store.RestoreUsage(ctx, persist.RestoreUsageParams{ID: a.ID, CID: a.CID, Ts: a.Ts, Note: a.Note, OaID: a.OaID, WID: wid})
+			if err != nil {
+				return fmt.Errorf("restore usage %v: %w", a.ID, err)
+			}
+		}
+		if b.Version >= 3 {
+			err = store.DeleteAllCategories(ctx, wid)
+			if err != nil {
+				return fmt.Errorf("clear categories: %w", err)
+			}
+			for _, c := range b.Categories {
+				// Suggestions went by the default names before version 10.
+				if b.Version < 10 {
+					c.Slot = defaultSlot(c.Name)
+				}
+				err = store.CreateCategory(ctx, persist.CreateCategoryParams{
+					WID:             wid,
+					Name:            c.Name,
+					Icon:            c.Icon,
+					SortOrder:       c.SortOrder,
+					WearsBeforeWash: c.WearsBeforeWash,
+					MonthlyBudget:   c.MonthlyBudget,
+					YearlyBudget:    c.YearlyBudget,
+					Slot:            c.Slot,
+				})
+				if err != nil {
+					return fmt.Errorf("restore category %v: %w", c.Name, err)
+				}
+			}
+		}
+		if b.Version >= 9 {
+			err = store.SetWardrobeCurrency(ctx, persist.SetWardrobeCurrencyParams{Currency: b.Currency, ID: wid})
+			if err != nil {
+				return fmt.Errorf("restore currency: %w", err)
+			}
+			err = store.DeleteAllRates(ctx, wid)
+			if err != nil {
+				return fmt.Errorf("clear rates: %w", err)
+			}
+			for _, r := range b.Rates {
+				err = store.PutRate(ctx, persist.PutRateParams{WID: wid, Currency: r.Currency, Rate: r.Rate, Updated: r.Updated})
+				if err != nil {
+					return fmt.Errorf("restore rate %v: %w", r.Currency, err)
+				}
+			}
+		}
+		for _, t := range b.Tags {
+			err = store.CreateTag(ctx, persist.CreateTagParams{ID: t.ID, Name: t.Name, WID: wid})
+			if err != nil {
+				return fmt.Errorf("restore tag %v: %w", t.Name, err)
+			}
+		}
+		for _, ct := range b.CatalogTags {
+			err = store.AddItemTag(ctx, persist.AddItemTagParams{CID: ct.CID, TID: ct.TID})
+			if err != nil {
+				return fmt.Errorf("restore item tag %v: %w", ct.CID, err)
+			}
+		}
+		for _, sc := range b.StateChanges {
+			err = store.LogStateChange(ctx, persist.LogStateChangeParams{ID: sc.ID, CID: sc.CID, WID: wid, Ts: sc.Ts, FromState: sc.FromState, ToState: sc.ToState})
+			if err != nil {
+				return fmt.Errorf("restore state change %v: %w", sc.ID, err)
+			}
+		}
+		for _, l := range b.Loans {
+			err = store.RestoreLoan(ctx, persist.RestoreLoanParams{ID: l.ID, CID: l.CID, WID: wid, Borrower: l.Borrower, Lent: l.Lent, Due: l.Due, Returned: l.Returned})
+			if err != nil {
+				return fmt.Errorf("restore loan %v: %w", l.ID, err)
+			}
+		}
+		for _, w := range b.Wishlist {
+			err = store.RestoreWish(ctx, persist.RestoreWishParams{
+				ID:          w.ID,
+				WID:         wid,
+				Title:       w.Title,
+				Category:    w.Category,
+				Brand:       w.Brand,
+				TargetPrice: w.TargetPrice,
+				Link:        w.Link,
+				Priority:    w.Priority,
+				Notes:       w.Notes,
+				Added:       w.Added,
+				Bought:      w.Bought,
+				CID:         w.CID,
+			})
+			if err != nil {
+				return fmt.Errorf("restore wish %v: %w", w.ID, err)
+			}
+		}
+		for _, p := range b.Plans {
+			err = store.PutPlan(ctx, persist.PutPlanParams{ID: p.ID, WID: wid, Day: p.Day, CID: p.CID, OID: p.OID, Note: p.Note})
+			if err != nil {
+				return fmt.Errorf("restore plan %v: %w", p.ID, err)
+			}
+		}
+		for _, p := range b.Photos {
+			id := strings.TrimSpace(p.ID)
+			// The id names the photo's files, so it mustn't lead out of photoDir.
+			if _, err := uuid.Parse(id); err != nil {
+				return fmt.Errorf("restore photo %q: not a photo id", id)
+			}
+			err = store.AddPhoto(ctx, persist.AddPhotoParams{ID: id, CID: p.CID, ContentType: p.ContentType, Ts: p.Ts})
+			if err != nil {
+				return fmt.Errorf("restore photo %v: %w", id, err)
+			}
+			written = append(written, id)
+			err = restorePhotoFiles(zr, id)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, id := range written {
+			removePhotoFiles(id)
+		}
+		return err
+	}
+	return nil
+}
+
+// Fails unless every row of the backup which refers to an item, outfit, outfit usage or tag refers
+// to one in the backup, so a restore can't reach into other wardrobes.
+func checkBackupRefs(b backup) error {
+	items := make(map[string]bool)
+	for _, c := range b.Catalog {
+		items[strings.TrimSpace(c.ID)] = true
+	}
+	outfits := make(map[string]bool)
+	for _, o := range b.Outfits {
+		outfits[strings.TrimSpace(o.ID)] = true
+	}
+	outfitUses := make(map[string]bool)
+	for _, oa := range b.OutfitActivity {
+		outfitUses[strings.TrimSpace(oa.ID)] = true
+	}
+	tags := make(map[string]bool)
+	for _, t := range b.Tags {
+		tags[strings.TrimSpace(t.ID)] = true
+	}
+	check := func(kind string, id string, known map[string]bool, what string) error {
+		if !known[strings.TrimSpace(id)] {
+			return fmt.Errorf("%v refers to %v %q, which isn't in the backup", kind, what, id)
+		}
+		return nil
+	}
+	checkNull := func(kind string, id sql.NullString, known map[string]bool, what string) error {
+		if !id.Valid {
+			return nil
+		}
+		return check(kind, id.String, known, what)
+	}
+
+	var errs []error
+	for _, a := range b.Activity {
+		errs = append(errs, check("usage "+a.ID, a.CID, items, "item"), checkNull("usage "+a.ID, a.OaID, outfitUses, "outfit usage"))
+	}
+	for _, oi := range b.OutfitItems {
+		errs = append(errs, check("outfit item", oi.OID, outfits, "outfit"), check("outfit item", oi.CID, items, "item"))
+	}
+	for _, oa := range b.OutfitActivity {
+		errs = append(errs, check("outfit usage "+oa.ID, oa.OID, outfits, "outfit"))
+	}
+	for _, ct := range b.CatalogTags {
+		errs = append(errs, check("item tag", ct.CID, items, "item"), check("item tag", ct.TID, tags, "tag"))
+	}
+	for _, p := range b.Photos {
+		errs = append(errs, check("photo "+p.ID, p.CID, items, "item"))
+	}
+	for _, l := range b.Loans {
+		errs = append(errs, check("loan "+l.ID, l.CID, items, "item"))
+	}
+	for _, p := range b.Plans {
+		errs = append(errs, checkNull("plan "+p.ID, p.CID, items, "item"), checkNull("plan "+p.ID, p.OID, outfits, "outfit"))
+	}
+	for _, sc := range b.StateChanges {
+		errs = append(errs, check("state change "+sc.ID, sc.CID, items, "item"))
+	}
+	for _, w := range b.Wishlist {
+		errs = append(errs, checkNull("wish "+w.ID, w.CID, items, "item"))
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copies a photo and its thumbnail out of a backup archive into photoDir.
+func restorePhotoFiles(zr *zip.Reader, id string) error {
+	err := os.MkdirAll(photoDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("create photo dir: %w", err)
+	}
+	for _, thumb := range []bool{false, true} {
+		path := photoPath(id, thumb)
+		name := backupPhotoName(id, thumb)
+		src, err := zr.Open(name)
+		if err != nil {
+			return fmt.Errorf("open %v: %w", name, err)
+		}
+		data, err := io.ReadAll(src)
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("read %v: %w", name, err)
+		}
+		err = os.WriteFile(path, data, 0o644)
+		if err != nil {
+			return fmt.Errorf("write %v: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Downloads a backup of the current wardrobe.
+func handleBackup(response http.ResponseWriter, req *http.Request) error {
+	name := fmt.Sprintf("gorgina-%v.zip", time.Now().Format("2006-01-02"))
+	response.Header().Set("Content-Type", "application/zip")
+	response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	return writeBackup(req.Context(), response)
+}
+
+// Restores an uploaded backup into the current wardrobe, and reloads the page.
+func handleRestore(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseMultipartForm(maxUploadBytes)
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	f, fh, err := req.FormFile("backup")
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer f.Close()
+	zr, err := zip.NewReader(f, fh.Size)
+	if err != nil {
+		return fmt.Errorf("read zip: %w", err)
+	}
+	err = restoreBackup(req.Context(), zr)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	response.Header().Set("HX-Refresh", "true")
+	return nil
+}
+
+// Entry point for 'gorgina restore -user <username> [-wardrobe <name>] <backup.zip>'
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	username := fs.String("user", "", "account to restore as")
+	wardrobe := fs.String("wardrobe", "", "name of the empty wardrobe to restore into, defaults to the account's oldest")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *username == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("usage: restore -user <username> [-wardrobe <name>] <backup.zip>")
+	}
+	ctx, s, err := openCLIWardrobe(context.Background(), *username, *wardrobe)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	zr, err := zip.OpenReader(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer zr.Close()
+	return restoreBackup(ctx, &zr.Reader)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Zips a backup.json of b along with the given files.
+func zipBackup(t *testing.T, b backup, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(backupManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.NewEncoder(w).Encode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = w.Write([]byte(contents))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+func TestCheckBackupRefs(t *testing.T) {
+	valid := func() backup {
+		return backup{
+			Catalog:        []persist.Catalog{{ID: "item"}},
+			Outfits:        []persist.Outfit{{ID: "outfit"}},
+			OutfitItems:    []persist.OutfitItem{{OID: "outfit", CID: "item"}},
+			OutfitActivity: []persist.OutfitActivity{{ID: "worn", OID: "outfit"}},
+			Activity:       []persist.Activity{{ID: "use", CID: "item", OaID: sql.NullString{Valid: true, String: "worn"}}},
+			Tags:           []persist.Tag{{ID: "tag"}},
+			CatalogTags:    []persist.CatalogTag{{CID: "item", TID: "tag"}},
+			Photos:         []persist.ItemPhoto{{ID: "photo", CID: "item"}},
+			Loans:          []persist.Loan{{ID: "loan", CID: "item"}},
+			Plans:          []persist.PlannedUsage{{ID: "plan", OID: sql.NullString{Valid: true, String: "outfit"}}},
+			StateChanges:   []persist.StateChange{{ID: "change", CID: "item"}},
+			Wishlist:       []persist.Wish{{ID: "wish"}, {ID: "bought", CID: sql.NullString{Valid: true, String: "item"}}},
+		}
+	}
+	for _, tc := range []struct {
+		name    string
+		edit    func(b *backup)
+		wantErr string
+	}{
+		{name: "valid", edit: func(b *backup) {}},
+		{name: "padded ids", edit: func(b *backup) { b.Catalog[0].ID = "item    " }},
+		{name: "usage of another item", edit: func(b *backup) { b.Activity[0].CID = "other" }, wantErr: `usage use refers to item "other"`},
+		{name: "usage of another outfit usage", edit: func(b *backup) { b.Activity[0].OaID.String = "other" }, wantErr: `refers to outfit usage "other"`},
+		{name: "outfit item of another outfit", edit: func(b *backup) { b.OutfitItems[0].OID = "other" }, wantErr: `refers to outfit "other"`},
+		{name: "outfit usage of another outfit", edit: func(b *backup) { b.OutfitActivity[0].OID = "other" }, wantErr: `refers to outfit "other"`},
+		{name: "another tag", edit: func(b *backup) { b.CatalogTags[0].TID = "other" }, wantErr: `refers to tag "other"`},
+		{name: "photo of another item", edit: func(b *backup) { b.Photos[0].CID = "other" }, wantErr: `photo photo refers to item "other"`},
+		{name: "loan of another item", edit: func(b *backup) { b.Loans[0].CID = "other" }, wantErr: `loan loan refers to item "other"`},
+		{name: "plan of another outfit", edit: func(b *backup) { b.Plans[0].OID.String = "other" }, wantErr: `plan plan refers to outfit "other"`},
+		{name: "state change of another item", edit: func(b *backup) { b.StateChanges[0].CID = "other" }, wantErr: `refers to item "other"`},
+		{name: "wish bought as another item", edit: func(b *backup) { b.Wishlist[1].CID.String = "other" }, wantErr: `wish bought refers to item "other"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := valid()
+			tc.edit(&b)
+			err := checkBackupRefs(b)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkBackupRefs: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("checkBackupRefs: got %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	const photo = "0b6f1f64-3c5e-4a5e-9d47-6a2b1f8c7e10"
+	for _, tc := range []struct {
+		name    string
+		photo   string
+		wantErr string
+	}{
+		{name: "photo", photo: photo},
+		{name: "photo id leaving the photo dir", photo: "../../escaped", wantErr: "not a photo id"},
+		{name: "photo id of a path", photo: "/tmp/escaped", wantErr: "not a photo id"},
+		{name: "photo without files", photo: "9c1e9d3a-2f0b-4b8e-8f7e-3d6a5c4b2a19", wantErr: "open photos/"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			b := backup{
+				Version:  backupVersion,
+				Created:  time.Now().UTC(),
+				Currency: "USD",
+				Catalog:  []persist.Catalog{{ID: "item", Title: sql.NullString{Valid: true, String: "Coat"}}},
+				Photos:   []persist.ItemPhoto{{ID: tc.photo, CID: "item", ContentType: "image/jpeg", Ts: time.Now().UTC()}},
+			}
+			zr := zipBackup(t, b, map[string]string{
+				backupPhotoName(photo, false): "photo",
+				backupPhotoName(photo, true):  "thumb",
+			})
+			err := restoreBackup(ctx, zr)
+			cs, lerr := store.ListCatalog(ctx, currentWardrobe(ctx))
+			if lerr != nil {
+				t.Fatal(lerr)
+			}
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("restoreBackup: %v", err)
+				}
+				if len(cs) != 1 {
+					t.Fatalf("restored %v items, want 1", len(cs))
+				}
+				got, err := os.ReadFile(photoPath(photo, true))
+				if err != nil || string(got) != "thumb" {
+					t.Fatalf("thumbnail: got %q, %v", got, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("restoreBackup: got %v, want an error containing %q", err, tc.wantErr)
+			}
+			if len(cs) != 0 {
+				t.Fatalf("restored %v items of a failed restore", len(cs))
+			}
+			entries, _ := os.ReadDir(photoDir)
+			if len(entries) != 0 {
+				t.Fatalf("left %v files in the photo dir", len(entries))
+			}
+			if _, err := os.Stat(filepath.Join(photoDir, tc.photo)); err == nil {
+				t.Fatalf("wrote %v", tc.photo)
+			}
+		})
+	}
+}
+
+// A backup can't take another wardrobe's item, by logging a usage of it.
+func TestRestoreBackupOtherWardrobe(t *testing.T) {
+	ctx := openTestStore(t)
+	other := testWardrobe(t, "them")
+	_, err := store.PutItem(other, persist.PutItemParams{ID: "theirs", WID: currentWardrobe(other), Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := backup{
+		Version:  backupVersion,
+		Currency: "USD",
+		Activity: []persist.Activity{{ID: "use", CID: "theirs", Ts: time.Now().UTC()}},
+	}
+	err = restoreBackup(ctx, zipBackup(t, b, nil))
+	if err == nil || !strings.Contains(err.Error(), `refers to item "theirs"`) {
+		t.Fatalf("restoreBackup: got %v, want the usage refused", err)
+	}
+	as, err := store.GetAllUsage(other, persist.GetAllUsageParams{CID: "theirs", WID: currentWardrobe(other)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 0 {
+		t.Fatalf("their item has %v usages, want 0", len(as))
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// What was spent on a category in a month and in the year it's in, against its limits.
+type categoryBudget struct {
+	Name         string
+	Icon         string
+	Month        float64
+	Year         float64
+	MonthlyLimit sql.NullFloat64
+	YearlyLimit  sql.NullFloat64
+}
+
+func (b categoryBudget) OverMonth() bool {
+	return b.MonthlyLimit.Valid && b.Month > b.MonthlyLimit.Float64
+}
+
+func (b categoryBudget) OverYear() bool {
+	return b.YearlyLimit.Valid && b.Year > b.YearlyLimit.Float64
+}
+
+// Spending in a month and its year, by category, in the wardrobe's currency. Items count towards
+// the month they were purchased in, so those without a purchase date aren't counted, nor are those
+// priced in the Unrated currencies.
+type budgetView struct {
+	Currency string
+	Unrated  []string
+	// The first day of the month shown.
+	Month      time.Time
+	Prev, Next time.Time
+	Categories []categoryBudget
+	MonthTotal float64
+	YearTotal  float64
+}
+
+// Totals spending in the month of day, and its year, against each category's limits.
+func loadBudget(ctx context.Context, day time.Time) (budgetView, error) {
+	wid := currentWardrobe(ctx)
+	v := budgetView{Month: time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)}
+	v.Prev, v.Next = v.Month.AddDate(0, -1, 0), v.Month.AddDate(0, 1, 0)
+	year := time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+
+	x, err := loadExchange(ctx)
+	if err != nil {
+		return v, err
+	}
+	cats, err := store.ListCategories(ctx, wid)
+	if err != nil {
+		return v, fmt.Errorf("list categories: %w", err)
+	}
+	byName := make(map[string]int)
+	for _, c := range cats {
+		byName[c.Name] = len(v.Categories)
+		v.Categories = append(v.Categories, categoryBudget{Name: c.Name, Icon: c.Icon, MonthlyLimit: c.MonthlyBudget, YearlyLimit: c.YearlyBudget})
+	}
+	cs, err := store.ListPurchases(ctx, persist.ListPurchasesParams{WID: wid, FromDay: year, ToDay: year.AddDate(1, 0, 0)})
+	if err != nil {
+		return v, fmt.Errorf("list purchases: %w", err)
+	}
+	for _, c := range cs {
+		name := strings.TrimSpace(c.Category.String)
+		i, ok := byName[name]
+		if !ok {
+			// Items outside of any category still count towards the totals.
+			i = len(v.Categories)
+			byName[name] = i
+			v.Categories = append(v.Categories, categoryBudget{Name: name})
+		}
+		b := &v.Categories[i]
+		price := x.toHome(c.Price.Float64, c.Currency)
+		b.Year += price
+		v.YearTotal += price
+		if !c.Purchased.Time.Before(v.Month) && c.Purchased.Time.Before(v.Next) {
+			b.Month += price
+			v.MonthTotal += price
+		}
+	}
+	v.Currency, v.Unrated = x.Home, x.Unrated
+	return v, nil
+}
+
+// Describes how far over its budgets a category is, for the month of day and its year.
+func budgetWarnings(ctx context.Context, category string, day time.Time) ([]string, error) {
+	v, err := loadBudget(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	var ws []string
+	for _, b := range v.Categories {
+		if b.Name != strings.TrimSpace(category) {
+			continue
+		}
+		if b.OverMonth() {
+			ws = append(ws, fmt.Sprintf("%v is %v over its budget of %v for %v", b.Name, formatMoney(v.Currency, b.Month-b.MonthlyLimit.Float64), formatMoney(v.Currency, b.MonthlyLimit.Float64), v.Month.Format("January 2006")))
+		}
+		if b.OverYear() {
+			ws = append(ws, fmt.Sprintf("%v is %v over its budget of %v for %v", b.Name, formatMoney(v.Currency, b.Year-b.YearlyLimit.Float64), formatMoney(v.Currency, b.YearlyLimit.Float64), v.Month.Year()))
+		}
+	}
+	return ws, nil
+}
+
+// Renders the budget for the request's month, e.g month=2022-05, or this month.
+func renderBudget(response http.ResponseWriter, req *http.Request) error {
+	day := time.Now()
+	if s := req.FormValue("month"); s != "" {
+		m, err := time.Parse("2006-01", s)
+		if err != nil {
+			return fmt.Errorf("parse month %q: expected YYYY-MM", s)
+		}
+		day = m
+	}
+	v, err := loadBudget(req.Context(), day)
+	if err != nil {
+		return err
+	}
+	r, err := listBudget(v)
+	if err != nil {
+		return fmt.Errorf("render budget: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleBudget(response http.ResponseWriter, req *http.Request) error {
+	return renderBudget(response, req)
+}
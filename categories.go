@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// The parts of an outfit, which suggestions fill with an item each. A category names the slot its
+// items fill, if they're part of outfits at all.
+const (
+	slotTop    = "top"
+	slotBottom = "bottom"
+	slotDress  = "dress"
+	slotShoes  = "shoes"
+)
+
+// Every slot, in the order they're offered, along with how they read. The first is for
+// categories whose items aren't part of suggested outfits.
+var slots = []struct{ Name, Label string }{
+	{"", "not in outfits"},
+	{slotTop, "top half"},
+	{slotBottom, "bottom half"},
+	{slotDress, "whole outfit, e.g a dress"},
+	{slotShoes, "shoes"},
+}
+
+func validSlot(slot string) bool {
+	for _, s := range slots {
+		if s.Name == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// The categories every new wardrobe starts with.
+var defaultCategories = []persist.Category{
+	{Name: "tops", Icon: "👚", SortOrder: 1, Slot: slotTop},
+	{Name: "bottoms", Icon: "👖", SortOrder: 2, Slot: slotBottom},
+	{Name: "dresses", Icon: "👗", SortOrder: 3, Slot: slotDress},
+	{Name: "accessories", Icon: "💍", SortOrder: 4},
+	{Name: "shoes", Icon: "👠", SortOrder: 5, Slot: slotShoes},
+}
+
+// The slot of the default category with the name, if there is one.
+func defaultSlot(name string) string {
+	for _, c := range defaultCategories {
+		if c.Name == name {
+			return c.Slot
+		}
+	}
+	return ""
+}
+
+// Gives a new wardrobe the default categories.
+func addDefaultCategories(ctx context.Context, store Store, wid string) error {
+	for _, c := range defaultCategories {
+		err := store.CreateCategory(ctx, persist.CreateCategoryParams{WID: wid, Name: c.Name, Icon: c.Icon, SortOrder: c.SortOrder, Slot: c.Slot})
+		if err != nil {
+			return fmt.Errorf("create category %v: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// The icon of each category in the current wardrobe, by name.
+func categoryIcons(ctx context.Context) (map[string]string, error) {
+	cs, err := store.ListCategories(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list categories: %w", err)
+	}
+	icons := make(map[string]string, len(cs))
+	for _, c := range cs {
+		icons[c.Name] = c.Icon
+	}
+	return icons, nil
+}
+
+func renderCategories(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	cs, err := store.ListCategories(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return fmt.Errorf("list categories: %w", err)
+	}
+	r, err := listCategories(cs)
+	if err != nil {
+		return fmt.Errorf("render categories: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleCategories(response http.ResponseWriter, req *http.Request) error {
+	return renderCategories(response, req)
+}
+
+// Adds a category, or updates the one named by old. wash is how many wears its items take before
+// they're marked worn, or empty to never mark them, monthly and yearly are how much may be spent
+// on it, or empty for no limit, and slot is the part of suggested outfits its items fill.
+// Renaming a category moves its items along with it.
+func handlePutCategory(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	wid := currentWardrobe(ctx)
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	old := req.Form.Get("old")
+	name := strings.ToLower(strings.TrimSpace(req.Form.Get("name")))
+	icon := strings.TrimSpace(req.Form.Get("icon"))
+	if name == "" {
+		return fmt.Errorf("category name is required")
+	}
+	var sort int
+	if s := strings.TrimSpace(req.Form.Get("sort")); s != "" {
+		sort, err = strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("parse sort order %q: %w", s, err)
+		}
+	} else {
+		// New categories go last.
+		cs, err := store.ListCategories(ctx, wid)
+		if err != nil {
+			return fmt.Errorf("list categories: %w", err)
+		}
+		if len(cs) > 0 {
+			sort = int(cs[len(cs)-1].SortOrder) + 1
+		}
+	}
+	var wash sql.NullInt32
+	if s := strings.TrimSpace(req.Form.Get("wash")); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return fmt.Errorf("wears before washing must be a positive number, got %q", s)
+		}
+		wash = sql.NullInt32{Valid: true, Int32: int32(n)}
+	}
+	monthly, err := parsePrice(req.Form.Get("monthly"))
+	if err != nil {
+		return fmt.Errorf("monthly budget: %w", err)
+	}
+	yearly, err := parsePrice(req.Form.Get("yearly"))
+	if err != nil {
+		return fmt.Errorf("yearly budget: %w", err)
+	}
+	slot := req.Form.Get("slot")
+	if !validSlot(slot) {
+		return fmt.Errorf("unknown outfit slot %q", slot)
+	}
+	if old == "" {
+		err = store.CreateCategory(ctx, persist.CreateCategoryParams{
+			WID:             wid,
+			Name:            name,
+			Icon:            icon,
+			SortOrder:       int32(sort),
+			WearsBeforeWash: wash,
+			MonthlyBudget:   monthly,
+			YearlyBudget:    yearly,
+			Slot:            slot,
+		})
+		if err != nil {
+			return fmt.Errorf("create category %v: %w", name, err)
+		}
+		return renderCategories(response, req)
+	}
+	err = store.InTx(ctx, func(store Store) error {
+		res, err := store.UpdateCategory(ctx, persist.UpdateCategoryParams{
+			Name:            name,
+			Icon:            icon,
+			SortOrder:       int32(sort),
+			WearsBeforeWash: wash,
+			MonthlyBudget:   monthly,
+			YearlyBudget:    yearly,
+			Slot:            slot,
+			WID:             wid,
+			OldName:         old,
+		})
+		if err != nil {
+			return fmt.Errorf("update category %v: %w", old, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return fmt.Errorf("no category %v", old)
+		}
+		if name != old {
+			err = store.RenameItemCategory(ctx, persist.RenameItemCategoryParams{NewName: ns([]string{name}), WID: wid, OldName: old})
+			if err != nil {
+				return fmt.Errorf("move items to %v: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return renderCategories(response, req)
+}
+
+// Deletes a category which no items are in. e.g api/category/delete?name=...
+func handleDeleteCategory(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	wid := currentWardrobe(ctx)
+	name := strings.Join(req.URL.Query()["name"], "")
+	n, err := store.CountCategoryItems(ctx, persist.CountCategoryItemsParams{WID: wid, Name: name})
+	if err != nil {
+		return fmt.Errorf("count items in %v: %w", name, err)
+	}
+	if n > 0 {
+		return fmt.Errorf("%v items are still in %v, move them to another category first", n, name)
+	}
+	err = store.DeleteCategory(ctx, persist.DeleteCategoryParams{WID: wid, Name: name})
+	if err != nil {
+		return fmt.Errorf("delete category %v: %w", name, err)
+	}
+	return renderCategories(response, req)
+}
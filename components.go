@@ -2,41 +2,122 @@ package main
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"html/template"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hherman1/gorgina/db/persist"
 )
 
-func putForm(existing persist.Catalog) (string, error) {
+// A catalog item being added or edited.
+type itemForm struct {
+	persist.Catalog
+	Tags []string
+	// Every tag in the wardrobe, offered as suggestions.
+	AllTags    []string
+	Categories []persist.Category
+	// Currencies with a known rate, which the price may be in.
+	Currencies []string
+}
+
+func putForm(existing itemForm) (string, error) {
 	existing.Price.Float64 = math.Round(existing.Price.Float64*100) / 100
 	const tmpl = `
 <div class="grid place-items-center">
-<form hx-post="api/put" hx-target="#viewport" class="w-96 grid grid-cols-1 place-content-center">
+<form hx-post="api/put" hx-target="#viewport" hx-encoding="multipart/form-data" class="w-96 grid grid-cols-1 place-content-center">
 	<input type="hidden" name="id" value="{{.ID}}" />
 	<label for="title"> Title </label> <input type="text" id="title" name="title" class="border-2 p-2" value="{{.Title.String}}"> </input> <br/>
 	<label for="description"> Description </label> <br /> <textarea name="description" id="description" class="border-2 p-2" value="{{.Description.String}}">{{.Description.String}}</textarea><br/>
-	<label for="category"> Category </label> <select name="category" id="category" class="border-2 p-2">
-		<option value="tops" {{ if eq "tops" (trim .Category.String)}}selected{{end}}>Tops</option>
-		<option value="bottoms" {{ if eq "bottoms" (trim .Category.String)}}selected{{end}}>Bottoms</option>
-		<option value="dresses" {{ if eq "dresses" (trim .Category.String)}}selected{{end}}>Dresses</option>
-		<option value="accessories" {{ if eq "accessories" (trim .Category.String)}}selected{{end}}>Accessories</option>
-		<option value="shoes" {{ if eq "shoes" (trim .Category.String)}}selected{{end}}>Shoes</option>
+	<label for="category"> Category <a hx-get="component/categories" hx-target="#viewport" class="text-sm text-slate-400 cursor-pointer hover:underline"> edit </a> </label> <select name="category" id="category" class="border-2 p-2">
+		{{- $current := trim .Category.String }}
+		{{- $found := false }}
+		{{- range .Categories }}
+		<option value="{{.Name}}" {{ if eq .Name $current }}selected{{ $found = true }}{{end}}>{{.Icon}} {{.Name}}</option>
+		{{- end }}
+		{{- if and $current (not $found) }}
+		<option value="{{$current}}" selected>{{$current}}</option>
+		{{- end }}
 	</select> <br />
 	<label for="brand"> Brand </label> <input type="text" name="brand" id="brand" class="border-2 p-2" value="{{.Brand.String}}"/> <br/>
 	<label for="color"> Color </label> <input type="text" name="color" id="color" class="border-2 p-2" value="{{.Color.String}}"/> <br/>
 	<label for="pattern"> Pattern </label> <input type="text" name="pattern" id="pattern" class="border-2 p-2" value="{{.Pattern.String}}"/> <br/>
+	<label for="size"> Size </label> <input type="text" name="size" id="size" class="border-2 p-2" value="{{.Size.String}}" placeholder="M, 8, 32x30"/> <br/>
+	<label for="material"> Material </label> <input type="text" name="material" id="material" class="border-2 p-2" value="{{.Material.String}}" placeholder="cotton"/> <br/>
+	<span> Seasons </span>
+	<div class="border-2 p-2">
+		{{- $seasons := .Seasons.String }}
+		{{- range seasons }}
+		<input type="checkbox" name="seasons" id="season-{{.}}" value="{{.}}" {{if contains $seasons .}}checked{{end}}/> <label for="season-{{.}}" class="mr-2"> {{.}} </label>
+		{{- end }}
+	</div> <br/>
+	<label for="condition"> Condition </label> <select name="condition" id="condition" class="border-2 p-2">
+		<option value=""></option>
+		{{- $condition := .Condition.String }}
+		{{- range conditions }}
+		<option value="{{.}}" {{if eq . $condition}}selected{{end}}>{{.}}</option>
+		{{- end }}
+	</select> <br/>
+	<label for="min_temp"> Warm enough down to </label> <input type="number" step="any" name="min_temp" id="min_temp" class="border-2 p-2 w-20" value="{{temp .MinTemp}}"/> °C <br/>
+	<label for="max_temp"> Cool enough up to </label> <input type="number" step="any" name="max_temp" id="max_temp" class="border-2 p-2 w-20" value="{{temp .MaxTemp}}"/> °C <br/>
+	<label for="rain_ok"> Rain </label> <select name="rain_ok" id="rain_ok" class="border-2 p-2">
+		<option value=""></option>
+		<option value="true" {{if and .RainOk.Valid .RainOk.Bool}}selected{{end}}>good in the rain</option>
+		<option value="false" {{if and .RainOk.Valid (not .RainOk.Bool)}}selected{{end}}>keep dry</option>
+	</select> <br/>
+	<label for="retailer"> Retailer </label> <input type="text" name="retailer" id="retailer" class="border-2 p-2" value="{{.Retailer.String}}" placeholder="Where it came from"/> <br/>
+	<label for="purchased"> Purchased </label> <input type="date" name="purchased" id="purchased" class="border-2 p-2" value="{{date .Purchased}}"/> <br/>
+	<label for="tags"> Tags </label> <input type="text" name="tags" id="tags" class="border-2 p-2" value="{{join .Tags ", "}}" placeholder="work, summer"/>
+	{{- if .AllTags }}
+	<div class="text-sm py-1">
+		{{- range .AllTags }}
+		<button type="button" class="px-2 m-1 rounded-full bg-indigo-50 text-indigo-700 hover:bg-indigo-100" onclick="const t = document.getElementById('tags'); t.value = (t.value.trim() ? t.value.trim().replace(/,$/, '') + ', ' : '') + {{.}}">#{{.}}</button>
+		{{- end }}
+	</div>
+	{{- end }} <br/>
 	<label for="price"> Price </label> <input type="text" name="price" id="price" class="border-2 p-2" value="{{printf "%.2f" .Price.Float64}}" placeholder="30.99" /> <br/>
+	<label for="currency"> Currency <a hx-get="component/currencies" hx-target="#viewport" class="text-sm text-slate-400 cursor-pointer hover:underline"> rates </a> </label> <input type="text" name="currency" id="currency" list="currencies" maxlength="3" class="border-2 p-2 uppercase" value="{{.Currency}}"/>
+	<datalist id="currencies">
+		{{- range .Currencies }}
+		<option value="{{.}}"></option>
+		{{- end }}
+	</datalist> <br/>
+	<label for="photo"> Photos </label> <input type="file" name="photo" id="photo" accept="image/jpeg,image/png,image/gif" multiple class="border-2 p-2"/> <br/>
 	<div class="border-2 p-2"> <input type="checkbox" name="used" id="used" value="true"/> <label for="used"> Use now </label> </div> <br/>
 	<input type="submit"  class="border-2 p-2 rounded-full text-blue-100 bg-blue-600 hover:bg-blue-500 mb-4 cursor-pointer"/>
 	<button class="border-2 p-2 rounded-full bg-slate-50 hover:bg-slate-100" hx-get="component/list" hx-target="#viewport"> Cancel </button>
+	{{- if .ID }}
+	<button type="button" class="border-2 p-2 mt-4 rounded-full text-red-100 bg-red-500 hover:bg-red-400" hx-post="api/delete?id={{.ID}}" hx-target="#viewport" hx-confirm="Delete this item and all of its history?"> Delete </button>
+	{{- end }}
+</form>
+{{- if .ID }}
+<form hx-post="api/lifecycle" hx-target="#viewport" class="w-96 grid grid-cols-1 place-content-center mt-6 pt-4 border-t-2">
+	<input type="hidden" name="id" value="{{.ID}}" />
+	<label for="lifecycle"> Lifecycle </label> <select name="lifecycle" id="lifecycle" class="border-2 p-2">
+		{{- $lifecycle := .Lifecycle }}
+		{{- range lifecycles }}
+		<option value="{{.}}" {{if eq . $lifecycle}}selected{{end}}>{{.}}</option>
+		{{- end }}
+	</select> <br/>
+	<label for="disposed"> Since </label> <input type="date" name="disposed" id="disposed" class="border-2 p-2" value="{{date .Disposed}}"/> <br/>
+	<label for="resale_price"> Resale price </label> <input type="text" name="resale_price" id="resale_price" class="border-2 p-2" value="{{if .ResalePrice.Valid}}{{printf "%.2f" .ResalePrice.Float64}}{{end}}" placeholder="What it sold for"/> <br/>
+	<label for="lifecycle_note"> Notes </label> <input type="text" name="lifecycle_note" id="lifecycle_note" class="border-2 p-2" value="{{.LifecycleNote.String}}" placeholder="Who to, where"/> <br/>
+	<input type="submit" value="Save lifecycle" class="border-2 p-2 rounded-full text-slate-700 bg-slate-100 hover:bg-slate-200 cursor-pointer"/>
 </form>
+{{- end }}
 </div>`
 	t := template.Must(template.New("add").Funcs(template.FuncMap{
-		"trim": strings.TrimSpace,
+		"trim":       strings.TrimSpace,
+		"join":       strings.Join,
+		"contains":   strings.Contains,
+		"date":       formatDate,
+		"temp":       formatTemp,
+		"seasons":    func() []string { return seasons },
+		"conditions": func() []string { return conditions },
+		"lifecycles": func() interface{} { return lifecycles },
 	}).Parse(tmpl))
 	var bs bytes.Buffer
 	err := t.Execute(&bs, existing)
@@ -46,21 +127,46 @@ func putForm(existing persist.Catalog) (string, error) {
 	return bs.String(), nil
 }
 
-func listCatalog(items []persist.Catalog) (string, error) {
+// A catalog item along with the related data shown alongside it.
+type catalogView struct {
+	persist.Catalog
+	// ID of the photo shown for the item, if it has any.
+	Photo string
+	Tags  []string
+	// Icon of the item's category, if it has one.
+	Icon string
+	// The open loan of the item, while it's lent out.
+	Loan *persist.Loan
+}
+
+// Lists items. weatherErr is why the weather was ignored by a weather:today search, if it was.
+func listCatalog(items []catalogView, weatherErr string) (string, error) {
 	const tmpl = `
+{{- if .WeatherErr }}
+<p class="p-2 m-3 rounded-lg bg-amber-50 text-amber-800"> Couldn't get the weather, so it's ignored: {{.WeatherErr}} </p>
+{{- end }}
 <div class="flex flex-wrap">
-	{{- range .}}
+	{{- range .Items}}
 	{{template "item" .}}
 	{{- end}}
 </div>`
 
 	t := template.Must(template.New("catalog").Parse(tmpl))
 	template.Must(t.New("item").Funcs(template.FuncMap{
-		"used": itemUsedRecently,
-		"trim": strings.TrimSpace,
+		"used":       itemUsedRecently,
+		"trim":       strings.TrimSpace,
+		"date":       formatDate,
+		"temp":       formatTemp,
+		"stateLabel": stateLabel,
+		"nextStates": nextStates,
+		"overdue":    func(l *persist.Loan) bool { return overdue(*l) },
+		"money":      formatMoney,
 	}).Parse(itemTmpl))
 	var bs bytes.Buffer
-	err := t.Execute(&bs, items)
+	err := t.Execute(&bs, struct {
+		Items      []catalogView
+		WeatherErr string
+	}{items, weatherErr})
 	if err != nil {
 		return "", fmt.Errorf("execute tmpl: %w", err)
 	}
@@ -73,6 +179,9 @@ func itemUsedRecently(t time.Time) bool {
 
 const itemTmpl = `
 	<div class="p-3 m-3 max-w-xs" id="list-{{.ID}}">
+		{{- if .Photo }}
+		<img src="data/photo?id={{.Photo}}&thumb=true" alt="{{.Title.String}}" class="rounded-lg mb-2 cursor-pointer" hx-target="#viewport" hx-get="component/useHistory?id={{.ID}}"/>
+		{{- end}}
 		<div class="text-lg {{ if used .LastActivity.Time }}decoration-green-500 underline decoration-2{{end}}"> <b>{{.Title.String}}</b> </div>
 		{{if .LastActivity.Valid}}
 		<div class="text-sm p-1">
@@ -80,19 +189,34 @@ const itemTmpl = `
 			<span hx-target="#viewport" hx-get="component/useHistory?id={{.ID}}" class="cursor-pointer bg-slate-100 p-1 rounded-lg hover:bg-slate-50">⏱</span>
 		</div>
 		{{end}}
+		<div class="p-1 text-sm">
+			<select name="to" hx-post="api/state?id={{.ID}}" hx-target="#list-{{.ID}}" hx-swap="outerHTML" title="Where it is" class="p-1 rounded-lg {{if eq .State "clean"}}bg-green-50 text-green-800{{else}}bg-amber-100 text-amber-800{{end}}">
+				<option value="{{.State}}" selected> {{stateLabel .State}} </option>
+				{{- range nextStates .State }}
+				<option value="{{.}}"> {{stateLabel .}} </option>
+				{{- end }}
+			</select>
+		</div>
+		{{- if .Loan }}
+		<div class="p-1 text-sm {{if overdue .Loan}}text-red-700 font-bold{{else}}text-amber-800{{end}}">
+			🤝 Lent to {{.Loan.Borrower}} {{- if .Loan.Due.Valid }}, due {{date .Loan.Due}}{{end}}
+			<button hx-post="api/loan/return?id={{.Loan.ID}}&item={{.ID}}" hx-target="#list-{{.ID}}" hx-swap="outerHTML" class="p-1 ml-1 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> ✓ Returned </button>
+		</div>
+		{{- else if ne .State "lent" }}
+		<details class="p-1 text-sm">
+			<summary class="cursor-pointer text-slate-500"> 🤝 Lend </summary>
+			<form hx-post="api/loan/lend?id={{.ID}}" hx-target="#list-{{.ID}}" hx-swap="outerHTML" class="flex flex-wrap gap-1 pt-1">
+				<input type="text" name="borrower" placeholder="Who to" required class="p-1 border-2 rounded-lg w-28"/>
+				<input type="date" name="due" title="Back by" class="p-1 border-2 rounded-lg"/>
+				<button class="p-1 rounded-lg text-amber-800 bg-amber-100 hover:bg-amber-200"> Lend </button>
+			</form>
+		</details>
+		{{- end }}
 		<div class="p-1"> {{.Description.String}} </div>
 		<div class="p-1 italic">
-			<span class="not-italic">
-			{{- if eq (trim .Category.String) "bottoms" }}
-			👖
-			{{- else if eq (trim .Category.String) "tops" }}
-			👚
-			{{- else if eq (trim .Category.String) "accessories"}}
-			💍
-			{{- else if eq (trim .Category.String) "shoes"}}
-			👠
-			{{- else if eq (trim .Category.String) "dresses"}}
-			👗
+			<span class="not-italic" title="{{trim .Category.String}}">
+			{{- if .Icon }}
+			{{.Icon}}
 			{{- else }}
 			{{.Category.String}}
 			{{- end}}
@@ -100,16 +224,52 @@ const itemTmpl = `
 
 			 ⸱ {{.Brand.String}} ⸱ {{.Color.String}} ⸱ {{.Pattern.String}}
 		</div>
-		<div class="p-1 text-green-800"> ${{printf "%.2f" .Price.Float64}} </div>
+		{{- if .Tags }}
+		<div class="p-1 text-sm">
+			{{- range .Tags }}
+			<span hx-get="component/list?search={{printf "tag:%q" .}}" hx-target="#viewport" class="cursor-pointer px-2 rounded-full bg-indigo-50 text-indigo-700 hover:bg-indigo-100">#{{.}}</span>
+			{{- end }}
+		</div>
+		{{- end }}
+		{{- if or .Size.Valid .Material.Valid .Seasons.Valid }}
+		<div class="p-1 text-sm">
+			{{- if .Size.Valid }} Size {{.Size.String}} {{- end }}
+			{{- if .Material.Valid }} ⸱ {{.Material.String}} {{- end }}
+			{{- if .Seasons.Valid }} ⸱ {{.Seasons.String}} {{- end }}
+		</div>
+		{{- end }}
+		{{- if or .MinTemp.Valid .MaxTemp.Valid .RainOk.Valid }}
+		<div class="p-1 text-sm">
+			{{- if and .MinTemp.Valid .MaxTemp.Valid }} 🌡 {{temp .MinTemp}}–{{temp .MaxTemp}}°C
+			{{- else if .MinTemp.Valid }} 🌡 {{temp .MinTemp}}°C and up
+			{{- else if .MaxTemp.Valid }} 🌡 up to {{temp .MaxTemp}}°C {{- end }}
+			{{- if .RainOk.Valid }} {{if .RainOk.Bool}}☔ good in the rain{{else}}🌂 keep dry{{end}} {{- end }}
+		</div>
+		{{- end }}
+		{{- if or .Purchased.Valid .Retailer.Valid .Condition.Valid }}
+		<div class="p-1 text-sm text-slate-500">
+			{{- if .Purchased.Valid }} Bought {{date .Purchased}} {{- end }}
+			{{- if .Retailer.Valid }} at {{.Retailer.String}} {{- end }}
+			{{- if .Condition.Valid }} ⸱ {{.Condition.String}} {{- end }}
+		</div>
+		{{- end }}
+		<div class="p-1 text-green-800"> {{money .Currency .Price.Float64}} </div>
+		{{- if ne .Lifecycle "active" }}
+		<div class="p-1 text-sm text-slate-500">
+			{{.Lifecycle}} {{date .Disposed}}
+			{{- if .ResalePrice.Valid }} for {{money .Currency .ResalePrice.Float64}} {{- end }}
+			{{- if .LifecycleNote.Valid }} ⸱ {{.LifecycleNote.String}} {{- end }}
+		</div>
+		{{- end }}
 
 		<button hx-target="#viewport" hx-get="component/putCatalog?id={{.ID}}" class="p-2 text-slate-500 rounded-lg bg-slate-50 hover:bg-slate-100"> Edit </button>
 		{{- if eq (used .LastActivity.Time) false }}
-		<button hx-target="#list-{{.ID}}" hx-get="api/use?id={{.ID}}" hx-swap="outerHTML" class="p-2 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> Use </button>
+		<button hx-target="#list-{{.ID}}" hx-post="api/use?id={{.ID}}" hx-swap="outerHTML" class="p-2 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> Use </button>
 		{{- end}}
 		{{- if not .Hidden }}
-		<button hx-target="#viewport" hx-get="api/hide?hidden=true&id={{.ID}}" class="p-2 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> Hide </button>
+		<button hx-target="#viewport" hx-post="api/hide?hidden=true&id={{.ID}}" class="p-2 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> Hide </button>
 		{{- else }}
-		<button hx-target="#viewport" hx-get="api/hide?hidden=false&id={{.ID}}" class="p-2 rounded-lg text-red-100 bg-red-500 hover:bg-red-400"> Unhide </button>
+		<button hx-target="#viewport" hx-post="api/hide?hidden=false&id={{.ID}}" class="p-2 rounded-lg text-red-100 bg-red-500 hover:bg-red-400"> Unhide </button>
 		{{- end }}
 
 		<!-- Allows setting a description on the last use if there was something noteworthy -->
@@ -119,10 +279,16 @@ const itemTmpl = `
 
 	</div>`
 
-func renderCatalogItem(item persist.Catalog) (string, error) {
+func renderCatalogItem(item catalogView) (string, error) {
 	t := template.Must(template.New("item").Funcs(template.FuncMap{
-		"used": itemUsedRecently,
-		"trim": strings.TrimSpace,
+		"used":       itemUsedRecently,
+		"trim":       strings.TrimSpace,
+		"date":       formatDate,
+		"temp":       formatTemp,
+		"stateLabel": stateLabel,
+		"nextStates": nextStates,
+		"overdue":    func(l *persist.Loan) bool { return overdue(*l) },
+		"money":      formatMoney,
 	}).Parse(itemTmpl))
 	var bs bytes.Buffer
 	err := t.Execute(&bs, item)
@@ -132,16 +298,29 @@ func renderCatalogItem(item persist.Catalog) (string, error) {
 	return bs.String(), nil
 }
 
-func renderEditableHistory(item persist.Catalog, history []persist.Activity) (string, error) {
+func renderEditableHistory(item persist.Catalog, history []persist.Activity, photos []persist.ItemPhoto, changes []persist.StateChange, loans []persist.Loan) (string, error) {
 	dot := struct {
 		Item    persist.Catalog
 		History []persist.Activity
-	}{item, history}
+		Photos  []persist.ItemPhoto
+		Changes []persist.StateChange
+		Loans   []persist.Loan
+	}{item, history, photos, changes, loans}
 	const tmpl = `
 <div class="p-4">
 	<h2 class="font-bold text-lg"> {{.Item.Title.String}} </h2>
+	{{- if .Photos }}
+	<div class="flex flex-wrap">
+		{{- range .Photos }}
+		<div class="m-2">
+			<a href="data/photo?id={{.ID}}" target="_blank"><img src="data/photo?id={{.ID}}" class="max-h-96 rounded-lg"/></a>
+			<button hx-post="api/photo/delete?id={{.ID}}" hx-target="#viewport" hx-confirm="Delete this photo?" class="p-2 mt-1 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> 🗑 </button>
+		</div>
+		{{- end }}
+	</div>
+	{{- end }}
 	<table>
-		<tr> <th class="p-2"> Time </th> <th class="p-2"> Note </th> </tr>
+		<tr> <th class="p-2"> Time </th> <th class="p-2"> Note </th> <th></th> </tr>
 		{{- range .History }}
 		<tr>
 			<form id="form-{{.ID}}" hx-swap="none" hx-post="api/putUse" hx-trigger="input"/> </form>
@@ -149,9 +328,160 @@ func renderEditableHistory(item persist.Catalog, history []persist.Activity) (st
 			<input type="hidden" name="timezoneMs" form="form-{{.ID}}"/>
 			<td class="p-2"> <input type="datetime-local" timestamp="{{.Ts.UnixMilli}}" hx-swap="none" hx-post="api/use/put" hx-trigger="input" name="time" form="form-{{.ID}}" hx-include="[form=form-{{.ID}}]"/> </td>
 			<td class="p-2"> <input type="text" hx-swap="none" hx-post="api/use/put" hx-trigger="input" name="note" form="form-{{.ID}}" hx-include="[form=form-{{.ID}}]" value="{{.Note.String}}" class="p-2"/> </td>
+			<td class="p-2"> <button hx-post="api/use/delete?id={{.ID}}" hx-target="#viewport" hx-confirm="Delete this use?" class="p-2 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> 🗑 </button> </td>
+		</tr>
+		{{- end}}
+	</table>
+	{{- if .Loans }}
+	<h3 class="font-bold mt-4"> Loans </h3>
+	<table class="text-sm">
+		<tr> <th class="p-2"> Lent to </th> <th class="p-2"> Out </th> <th class="p-2"> Due </th> <th class="p-2"> Back </th> </tr>
+		{{- range .Loans }}
+		<tr class="{{if overdue .}}text-red-700{{end}}">
+			<td class="p-2"> {{.Borrower}} </td>
+			<td class="p-2"> {{.Lent.Format "2006-01-02"}} </td>
+			<td class="p-2"> {{date .Due}} </td>
+			<td class="p-2"> {{if .Returned.Valid}}{{date .Returned}}{{else}}still out{{end}} </td>
 		</tr>
+		{{- end }}
+	</table>
+	{{- end }}
+	{{- if .Changes }}
+	<h3 class="font-bold mt-4"> Now {{stateLabel .Item.State}} </h3>
+	<ul class="p-2 text-sm">
+		{{- range .Changes }}
+		<li> <span class="italic text-slate-400" timestamp="{{.Ts.UnixMilli}}"> </span> {{stateLabel .FromState}} → {{stateLabel .ToState}} </li>
+		{{- end }}
+	</ul>
+	{{- end }}
+</div>
+<script type="text/javascript">
+document.querySelectorAll("[name=timezoneMs]").forEach(el => {
+	el.value = new Date().getTimezoneOffset() * 60 * 1000
+})
+</script>
+`
+	t := template.Must(template.New("history").Funcs(template.FuncMap{
+		"stateLabel": stateLabel,
+		"date":       formatDate,
+		"overdue":    overdue,
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, dot)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+// An outfit along with the items in it.
+type outfitView struct {
+	persist.Outfit
+	Items []catalogView
+}
+
+func listOutfits(outfits []outfitView) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<button hx-get="component/putOutfit" hx-target="#viewport" class="p-2 mb-2 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> New outfit </button>
+	<div class="flex flex-wrap">
+		{{- range .}}
+		<div class="p-3 m-3 max-w-xs" id="outfit-{{.ID}}">
+			<div class="text-lg {{ if used .LastActivity.Time }}decoration-green-500 underline decoration-2{{end}}"> <b>{{.Name}}</b> </div>
+			{{if .LastActivity.Valid}}
+			<div class="text-sm p-1">
+				<span class="italic text-slate-400" timestamp="{{.LastActivity.Time.UnixMilli}}"> </span>
+				<span hx-target="#viewport" hx-get="component/outfitHistory?id={{.ID}}" class="cursor-pointer bg-slate-100 p-1 rounded-lg hover:bg-slate-50">⏱</span>
+			</div>
+			{{end}}
+			<div class="flex flex-wrap p-1">
+				{{- range .Items}}
+				<div class="m-1 text-sm">
+					{{- if .Photo }}
+					<img src="data/photo?id={{.Photo}}&thumb=true" alt="{{.Title.String}}" class="w-16 rounded-lg"/>
+					{{- end}}
+					{{.Title.String}}
+				</div>
+				{{- end}}
+			</div>
+			<button hx-target="#viewport" hx-get="component/putOutfit?id={{.ID}}" class="p-2 text-slate-500 rounded-lg bg-slate-50 hover:bg-slate-100"> Edit </button>
+			{{- if eq (used .LastActivity.Time) false }}
+			<button hx-target="#viewport" hx-post="api/outfit/wear?id={{.ID}}" class="p-2 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> Wear </button>
+			{{- end}}
+		</div>
 		{{- end}}
 	</div>
+</div>`
+	t := template.Must(template.New("outfits").Funcs(template.FuncMap{
+		"used": itemUsedRecently,
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, outfits)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func putOutfitForm(existing persist.Outfit, items []catalogView, selected map[string]bool) (string, error) {
+	dot := struct {
+		Outfit   persist.Outfit
+		Items    []catalogView
+		Selected map[string]bool
+	}{existing, items, selected}
+	const tmpl = `
+<div class="grid place-items-center">
+<form hx-post="api/outfit/put" hx-target="#viewport" class="w-96 grid grid-cols-1 place-content-center">
+	<input type="hidden" name="id" value="{{.Outfit.ID}}" />
+	<label for="name"> Name </label> <input type="text" id="name" name="name" class="border-2 p-2" value="{{.Outfit.Name}}"/> <br/>
+	<fieldset class="border-2 p-2">
+		<legend> Items </legend>
+		{{- range .Items }}
+		<div class="p-1">
+			<input type="checkbox" name="item" id="item-{{.ID}}" value="{{.ID}}" {{ if index $.Selected .ID }}checked{{end}}/>
+			<label for="item-{{.ID}}"> {{.Title.String}} <span class="italic text-slate-400"> {{trim .Category.String}} ⸱ {{.Brand.String}} ⸱ {{.Color.String}} </span> </label>
+		</div>
+		{{- end }}
+	</fieldset> <br/>
+	<input type="submit" class="border-2 p-2 rounded-full text-blue-100 bg-blue-600 hover:bg-blue-500 mb-4 cursor-pointer"/>
+	<button type="button" class="border-2 p-2 rounded-full bg-slate-50 hover:bg-slate-100" hx-get="component/outfits" hx-target="#viewport"> Cancel </button>
+	{{- if .Outfit.ID }}
+	<button type="button" class="border-2 p-2 mt-4 rounded-full text-red-100 bg-red-500 hover:bg-red-400" hx-post="api/outfit/delete?id={{.Outfit.ID}}" hx-target="#viewport" hx-confirm="Delete this outfit? The history of its items is kept."> Delete </button>
+	{{- end }}
+</form>
+</div>`
+	t := template.Must(template.New("putOutfit").Funcs(template.FuncMap{
+		"trim": strings.TrimSpace,
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, dot)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func renderEditableOutfitHistory(outfit persist.Outfit, history []persist.OutfitActivity) (string, error) {
+	dot := struct {
+		Outfit  persist.Outfit
+		History []persist.OutfitActivity
+	}{outfit, history}
+	const tmpl = `
+<div class="p-4">
+	<h2 class="font-bold text-lg"> {{.Outfit.Name}} </h2>
+	<table>
+		<tr> <th class="p-2"> Time </th> <th class="p-2"> Note </th> <th></th> </tr>
+		{{- range .History }}
+		<tr>
+			<form id="form-{{.ID}}" hx-swap="none" hx-post="api/outfit/use/put" hx-trigger="input"/> </form>
+			<input type="hidden" name="id" value="{{.ID}}" form="form-{{.ID}}"/>
+			<input type="hidden" name="timezoneMs" form="form-{{.ID}}"/>
+			<td class="p-2"> <input type="datetime-local" timestamp="{{.Ts.UnixMilli}}" hx-swap="none" hx-post="api/outfit/use/put" hx-trigger="input" name="time" form="form-{{.ID}}" hx-include="[form=form-{{.ID}}]"/> </td>
+			<td class="p-2"> <input type="text" hx-swap="none" hx-post="api/outfit/use/put" hx-trigger="input" name="note" form="form-{{.ID}}" hx-include="[form=form-{{.ID}}]" value="{{.Note.String}}" class="p-2"/> </td>
+			<td class="p-2"> <button hx-post="api/outfit/use/delete?id={{.ID}}" hx-target="#viewport" hx-confirm="Delete this use? The use of each item in the outfit is deleted too." class="p-2 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> 🗑 </button> </td>
+		</tr>
+		{{- end}}
+	</table>
 </div>
 <script type="text/javascript">
 document.querySelectorAll("[name=timezoneMs]").forEach(el => {
@@ -159,7 +489,7 @@ document.querySelectorAll("[name=timezoneMs]").forEach(el => {
 })
 </script>
 `
-	t := template.Must(template.New("history").Parse(tmpl))
+	t := template.Must(template.New("outfitHistory").Parse(tmpl))
 	var bs bytes.Buffer
 	err := t.Execute(&bs, dot)
 	if err != nil {
@@ -167,3 +497,835 @@ document.querySelectorAll("[name=timezoneMs]").forEach(el => {
 	}
 	return bs.String(), nil
 }
+
+func renderStats(stats statsView) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<div class="flex flex-wrap">
+		<div class="p-3 m-2 rounded-lg bg-slate-50"> <div class="text-sm text-slate-500"> Items </div> <div class="text-2xl font-bold"> {{.Items}} </div> </div>
+		<div class="p-3 m-2 rounded-lg bg-slate-50"> <div class="text-sm text-slate-500"> Wears </div> <div class="text-2xl font-bold"> {{.Wears}} </div> </div>
+		<div class="p-3 m-2 rounded-lg bg-slate-50"> <div class="text-sm text-slate-500"> Spent </div> <div class="text-2xl font-bold text-green-800"> {{money .Currency .Spend}} </div> </div>
+		{{- if .Resale }}
+		<div class="p-3 m-2 rounded-lg bg-slate-50"> <div class="text-sm text-slate-500"> Resold for </div> <div class="text-2xl font-bold text-green-800"> {{money .Currency .Resale}} </div> </div>
+		<div class="p-3 m-2 rounded-lg bg-slate-50"> <div class="text-sm text-slate-500"> Net cost </div> <div class="text-2xl font-bold text-green-800"> {{money .Currency .NetCost}} </div> </div>
+		{{- end }}
+		{{- if .CostPerWear.Valid }}
+		<div class="p-3 m-2 rounded-lg bg-slate-50"> <div class="text-sm text-slate-500"> Cost per wear </div> <div class="text-2xl font-bold text-green-800"> {{money .Currency .CostPerWear.Float64}} </div> </div>
+		{{- end }}
+	</div>
+	{{- if .Unrated }}
+	<p class="p-2 m-2 rounded-lg bg-amber-50 text-amber-800"> Prices in {{join .Unrated ", "}} are left out of the totals until they have an <a hx-get="component/currencies" hx-target="#viewport" class="underline cursor-pointer">exchange rate</a>. </p>
+	{{- end }}
+
+	<div class="flex flex-wrap">
+		<div class="p-2 m-2">
+			<h2 class="font-bold text-lg"> Most worn </h2>
+			<ol class="list-decimal pl-6">
+				{{- range .MostWorn }}
+				<li> {{.Title.String}} <span class="text-slate-400"> ({{.Wears}}) </span> </li>
+				{{- end }}
+			</ol>
+		</div>
+		<div class="p-2 m-2">
+			<h2 class="font-bold text-lg"> Least worn </h2>
+			<ol class="list-decimal pl-6">
+				{{- range .LeastWorn }}
+				<li> {{.Title.String}} <span class="text-slate-400"> ({{.Wears}}) </span> </li>
+				{{- end }}
+			</ol>
+		</div>
+	</div>
+
+	<h2 class="font-bold text-lg mt-4"> Wears per month </h2>
+	<table>
+		{{- range .Months }}
+		<tr>
+			<td class="p-1 text-slate-500"> {{.Month}} </td>
+			<td class="p-1 w-96"> <div class="bg-green-500 rounded h-4" style="width: {{percent .Wears $.MaxMonthWears}}%"></div> </td>
+			<td class="p-1"> {{.Wears}} </td>
+		</tr>
+		{{- end }}
+	</table>
+
+	<h2 class="font-bold text-lg mt-4"> Cost per wear </h2>
+	<table>
+		<tr> <th class="p-2 text-left"> Item </th> <th class="p-2"> Price </th> <th class="p-2"> Wears </th> <th class="p-2"> Per wear </th> </tr>
+		{{- range .ByItem }}
+		<tr>
+			<td class="p-2"> {{.Title.String}} </td>
+			<td class="p-2 text-right"> {{if .Price.Valid}}{{money .Currency .Price.Float64}}{{end}} </td>
+			<td class="p-2 text-right"> {{.Wears}} </td>
+			<td class="p-2 text-right text-green-800"> {{if .CostPerWear.Valid}}{{money .Currency .CostPerWear.Float64}}{{end}} </td>
+		</tr>
+		{{- end }}
+	</table>
+
+	{{- if .Gone }}
+	<h2 class="font-bold text-lg mt-4"> Let go </h2>
+	<table>
+		<tr> <th class="p-2 text-left"> Item </th> <th class="p-2"> </th> <th class="p-2"> Price </th> <th class="p-2"> Resale </th> <th class="p-2"> Net cost </th> <th class="p-2"> Wears </th> <th class="p-2"> Per wear </th> </tr>
+		{{- range .Gone }}
+		<tr>
+			<td class="p-2"> {{.Title.String}} </td>
+			<td class="p-2 text-slate-500"> {{.Lifecycle}} </td>
+			<td class="p-2 text-right"> {{if .Price.Valid}}{{money .Currency .Price.Float64}}{{end}} </td>
+			<td class="p-2 text-right"> {{if .ResalePrice.Valid}}{{money .Currency .ResalePrice.Float64}}{{end}} </td>
+			<td class="p-2 text-right"> {{if .NetCost.Valid}}{{money .Currency .NetCost.Float64}}{{end}} </td>
+			<td class="p-2 text-right"> {{.Wears}} </td>
+			<td class="p-2 text-right text-green-800"> {{if .CostPerWear.Valid}}{{money .Currency .CostPerWear.Float64}}{{end}} </td>
+		</tr>
+		{{- end }}
+	</table>
+	{{- end }}
+
+	<div class="flex flex-wrap mt-4">
+		<div class="p-2 mr-4">
+			<h2 class="font-bold text-lg"> Spend by category </h2>
+			<table>
+				{{- range .Categories }}
+				<tr> <td class="p-1"> {{or .Name "—"}} </td> <td class="p-1 text-slate-400"> {{.Items}} items </td> <td class="p-1 text-right text-green-800"> {{money $.Currency .Spend}} </td> </tr>
+				{{- end }}
+			</table>
+		</div>
+		<div class="p-2">
+			<h2 class="font-bold text-lg"> Spend by brand </h2>
+			<table>
+				{{- range .Brands }}
+				<tr> <td class="p-1"> {{or .Name "—"}} </td> <td class="p-1 text-slate-400"> {{.Items}} items </td> <td class="p-1 text-right text-green-800"> {{money $.Currency .Spend}} </td> </tr>
+				{{- end }}
+			</table>
+		</div>
+	</div>
+</div>`
+	t := template.Must(template.New("stats").Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"money": formatMoney,
+		"percent": func(n, max int64) int64 {
+			if max == 0 {
+				return 0
+			}
+			return n * 100 / max
+		},
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, stats)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+// What the login page shows.
+type loginView struct {
+	// Set when there are no accounts yet, so the form creates the first one.
+	Signup   bool
+	Username string
+	Error    string
+}
+
+func loginPage(v loginView) (string, error) {
+	const tmpl = `<html>
+	<head>
+		<script src="https://cdn.tailwindcss.com"></script>
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<title> Gorgina </title>
+	</head>
+	<body>
+		<div class="p-5 max-w-sm mx-auto">
+			<h1 class="text-red-600 font-bold text-2xl mb-4"> Gorgina 💝 </h1>
+			{{- if .Signup }}
+			<p class="mb-4 text-slate-600"> Welcome! Create your account to get started. </p>
+			{{- end }}
+			{{- if .Error }}
+			<p class="mb-4 p-2 rounded-lg bg-red-100 text-red-800"> {{.Error}} </p>
+			{{- end }}
+			<form method="post" action="/login" class="flex flex-col">
+				<input type="text" name="username" value="{{.Username}}" placeholder="Username" autocomplete="username" required autofocus class="border-2 p-2 mb-2 rounded-lg"/>
+				<input type="password" name="password" placeholder="Password" {{if .Signup}}autocomplete="new-password" minlength="8"{{else}}autocomplete="current-password"{{end}} required class="border-2 p-2 mb-2 rounded-lg"/>
+				<button class="p-2 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> {{if .Signup}}Create account{{else}}Sign in{{end}} </button>
+			</form>
+		</div>
+	</body>
+</html>`
+	t := template.Must(template.New("login").Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, v)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+// A wardrobe the signed in user belongs to.
+type wardrobeView struct {
+	persist.Wardrobe
+	Members []persist.ListWardrobeMembersRow
+	// Whether this is the wardrobe being viewed.
+	Current bool
+}
+
+func listWardrobes(wardrobes []wardrobeView) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<form hx-post="api/wardrobe/put" hx-target="#viewport" class="mb-4">
+		<input type="text" name="name" placeholder="e.g Household" required class="border-2 p-2 rounded-lg"/>
+		<button class="p-2 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> New wardrobe </button>
+	</form>
+	<div class="flex flex-wrap">
+		{{- range .}}
+		<div class="p-3 m-3 max-w-xs rounded-lg {{if .Current}}bg-sky-50{{end}}">
+			<form hx-post="api/wardrobe/put" hx-target="#viewport" hx-trigger="change">
+				<input type="hidden" name="id" value="{{.ID}}"/>
+				<input type="text" name="name" value="{{.Name}}" class="text-lg font-bold p-1 rounded-lg"/>
+			</form>
+			<div class="text-sm p-1 text-slate-500">
+				{{- if eq (len .Members) 1 }} 🔒 Private {{- else }} 👪 {{ range $i, $m := .Members }}{{if $i}}, {{end}}{{$m.Username}}{{end}} {{- end }}
+			</div>
+			<form hx-post="api/wardrobe/member/add" hx-target="#viewport" class="text-sm">
+				<input type="hidden" name="id" value="{{.ID}}"/>
+				<input type="text" name="username" placeholder="Share with username" required class="border-2 p-1 rounded-lg"/>
+				<button class="p-1 rounded-lg bg-slate-50 hover:bg-slate-100"> Share </button>
+			</form>
+			{{- if .Current }}
+			<span class="p-2 inline-block text-sky-700 font-bold"> Viewing </span>
+			{{- else }}
+			<button hx-post="api/wardrobe/switch?id={{.ID}}" class="p-2 mt-2 rounded-lg text-sky-700 bg-sky-100 hover:bg-sky-200"> Open </button>
+			{{- end }}
+			{{- if gt (len .Members) 1 }}
+			<button hx-post="api/wardrobe/leave?id={{.ID}}" hx-target="#viewport" hx-confirm="Leave {{.Name}}? The other members keep its contents." class="p-2 mt-2 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> Leave </button>
+			{{- end }}
+		</div>
+		{{- end}}
+	</div>
+</div>`
+	t := template.Must(template.New("wardrobes").Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, wardrobes)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+// Exports of the current wardrobe, and a form to load them back in.
+const dataComponent = `
+<div class="p-4">
+	<h2 class="text-lg font-bold mb-2"> Export </h2>
+	<a href="data/catalog.csv" class="p-2 mr-2 inline-block rounded-lg bg-slate-50 hover:bg-slate-100"> catalog.csv </a>
+	<a href="data/activity.csv" class="p-2 mr-2 inline-block rounded-lg bg-slate-50 hover:bg-slate-100"> activity.csv </a>
+	<a href="data/backup" class="p-2 mr-2 inline-block rounded-lg bg-slate-50 hover:bg-slate-100" title="Everything in this wardrobe, including hidden items, notes, outfits and photos"> Full backup (zip) </a>
+	<h2 class="text-lg font-bold mt-6 mb-2"> Import </h2>
+	<p class="text-sm text-slate-500 mb-2"> Rows are matched by id, so an edited export updates items in place. Rows without an id are added. </p>
+	<form hx-encoding="multipart/form-data" hx-target="#import-report">
+		<label class="block mb-2"> Catalog <input type="file" name="catalog" accept=".csv,text/csv"/></label>
+		<label class="block mb-2"> Activity <input type="file" name="activity" accept=".csv,text/csv"/></label>
+		<button hx-post="api/import?dryRun=true" class="p-2 rounded-lg bg-slate-50 hover:bg-slate-100"> Preview </button>
+		<button hx-post="api/import" class="p-2 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> Import </button>
+	</form>
+	<div id="import-report" class="mt-4"></div>
+	<h2 class="text-lg font-bold mt-6 mb-2"> Restore </h2>
+	<p class="text-sm text-slate-500 mb-2"> Loads a full backup into this wardrobe, which must be empty. </p>
+	<form hx-post="api/restore" hx-encoding="multipart/form-data">
+		<input type="file" name="backup" accept=".zip,application/zip" required/>
+		<button class="p-2 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> Restore </button>
+	</form>
+</div>`
+
+func renderImportReport(r importReport) (string, error) {
+	const tmpl = `
+<div>
+	<div class="font-bold p-2 {{if .Errors}}text-red-700{{else}}text-green-700{{end}}">
+		{{- if .Errors }} {{.Errors}} invalid lines, nothing was imported.
+		{{- else if .Committed }} Imported: {{.Created}} added, {{.Updated}} updated.
+		{{- else }} Preview: {{.Created}} would be added, {{.Updated}} updated.
+		{{- end }}
+	</div>
+	<table class="text-sm">
+		{{- range .Lines }}
+		<tr class="{{if .Err}}bg-red-50{{end}}">
+			<td class="p-1 text-slate-500"> {{.File}}:{{.Line}} </td>
+			{{- if .Err }}
+			<td class="p-1 text-red-700" colspan="2"> {{.Err}} </td>
+			{{- else }}
+			<td class="p-1"> {{if eq .Action "create"}}➕ add{{else}}✏️ update{{end}} </td>
+			<td class="p-1 text-slate-500"> {{.ID}} </td>
+			{{- end }}
+		</tr>
+		{{- end }}
+	</table>
+</div>`
+	t := template.Must(template.New("importReport").Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, r)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listCategories(categories []persist.Category) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<table>
+		<tr class="text-left text-slate-500"> <th class="p-1"> Icon </th> <th class="p-1"> Name </th> <th class="p-1"> Order </th> <th class="p-1"> Wears before washing </th> <th class="p-1"> Budget a month </th> <th class="p-1"> Budget a year </th> <th class="p-1"> In suggested outfits as </th> </tr>
+		{{- range .Categories}}
+		<tr>
+			<td colspan="7">
+				<form hx-post="api/category/put" hx-target="#viewport" hx-trigger="change" class="m-0">
+					<input type="hidden" name="old" value="{{.Name}}"/>
+					<input type="text" name="icon" value="{{.Icon}}" class="border-2 p-1 w-12 rounded-lg"/>
+					<input type="text" name="name" value="{{.Name}}" required class="border-2 p-1 rounded-lg"/>
+					<input type="number" name="sort" value="{{.SortOrder}}" class="border-2 p-1 w-16 rounded-lg"/>
+					<input type="number" name="wash" min="1" value="{{if .WearsBeforeWash.Valid}}{{.WearsBeforeWash.Int32}}{{end}}" placeholder="never" class="border-2 p-1 w-20 rounded-lg"/>
+					<input type="text" name="monthly" value="{{if .MonthlyBudget.Valid}}{{printf "%.2f" .MonthlyBudget.Float64}}{{end}}" placeholder="no limit" class="border-2 p-1 w-24 rounded-lg"/>
+					<input type="text" name="yearly" value="{{if .YearlyBudget.Valid}}{{printf "%.2f" .YearlyBudget.Float64}}{{end}}" placeholder="no limit" class="border-2 p-1 w-24 rounded-lg"/>
+					<select name="slot" class="border-2 p-1 rounded-lg">
+						{{- $slot := .Slot }}
+						{{- range $.Slots }}
+						<option value="{{.Name}}" {{if eq .Name $slot}}selected{{end}}> {{.Label}} </option>
+						{{- end }}
+					</select>
+					<button type="button" hx-post="api/category/delete?name={{.Name}}" hx-target="#viewport" hx-confirm="Delete {{.Name}}?" class="p-1 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> Delete </button>
+				</form>
+			</td>
+		</tr>
+		{{- end}}
+		<tr>
+			<td colspan="7">
+				<form hx-post="api/category/put" hx-target="#viewport" class="m-0 mt-4">
+					<input type="text" name="icon" placeholder="🧥" class="border-2 p-1 w-12 rounded-lg"/>
+					<input type="text" name="name" placeholder="e.g outerwear" required class="border-2 p-1 rounded-lg"/>
+					<input type="number" name="sort" placeholder="last" class="border-2 p-1 w-16 rounded-lg"/>
+					<input type="number" name="wash" min="1" placeholder="never" class="border-2 p-1 w-20 rounded-lg"/>
+					<input type="text" name="monthly" placeholder="no limit" class="border-2 p-1 w-24 rounded-lg"/>
+					<input type="text" name="yearly" placeholder="no limit" class="border-2 p-1 w-24 rounded-lg"/>
+					<select name="slot" class="border-2 p-1 rounded-lg">
+						{{- range .Slots }}
+						<option value="{{.Name}}"> {{.Label}} </option>
+						{{- end }}
+					</select>
+					<button class="p-1 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> Add </button>
+				</form>
+			</td>
+		</tr>
+	</table>
+</div>`
+	t := template.Must(template.New("categories").Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, struct {
+		Categories []persist.Category
+		Slots      []struct{ Name, Label string }
+	}{categories, slots})
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listSuggestions(s suggestions) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	{{- if .Weather }}
+	<p class="p-2 mb-2 rounded-lg bg-sky-50 text-sky-800"> Today: {{.Weather.Summary}}. Suggesting what suits the weather. </p>
+	{{- else if .WeatherErr }}
+	<p class="p-2 mb-2 rounded-lg bg-amber-50 text-amber-800"> Couldn't get the weather, so it's ignored: {{.WeatherErr}} </p>
+	{{- end }}
+	<h2 class="text-lg font-bold"> Outfits </h2>
+	{{- if not .Outfits }}
+	<p class="text-slate-500 p-2"> Pick which categories make up outfits under Categories, and add items to them, to get outfit suggestions. </p>
+	{{- end }}
+	<div class="flex flex-wrap">
+		{{- range .Outfits }}
+		<div class="p-3 m-3 max-w-xs rounded-lg bg-indigo-50">
+			{{- range .Items }}
+			{{template "pick" .}}
+			{{- end }}
+			{{- if .Together }}
+			<div class="text-sm text-slate-500 p-1"> 🤝 Worn together before </div>
+			{{- end }}
+			<button hx-post="api/suggest/wear?{{range $i, $item := .Items}}{{if $i}}&{{end}}item={{$item.ID}}{{end}}" hx-target="#viewport" class="p-2 mt-2 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> Wear </button>
+		</div>
+		{{- end }}
+	</div>
+	<h2 class="text-lg font-bold mt-4"> Not worn lately </h2>
+	<div class="flex flex-wrap">
+		{{- range .Items }}
+		<div class="p-3 m-3 max-w-xs"> {{template "pick" .}} </div>
+		{{- end }}
+	</div>
+	{{- if .Skipped }}
+	<h2 class="text-lg font-bold mt-4"> Never suggested </h2>
+	<div class="p-2">
+		{{- range .Skipped }}
+		<span class="mr-3"> {{.Icon}} {{.Title.String}} <button hx-post="api/suggest/skip?id={{.ID}}&skip=false" hx-target="#viewport" class="p-1 rounded-lg text-sm text-slate-600 bg-slate-50 hover:bg-slate-100"> Allow </button> </span>
+		{{- end }}
+	</div>
+	{{- end }}
+</div>`
+	const pickTmpl = `
+<div class="flex items-center p-1">
+	{{- if .Photo }}
+	<img src="data/photo?id={{.Photo}}&thumb=true" alt="{{.Title.String}}" class="w-12 h-12 object-cover rounded-lg mr-2"/>
+	{{- else }}
+	<span class="w-12 text-2xl text-center mr-2"> {{.Icon}} </span>
+	{{- end }}
+	<span class="grow cursor-pointer" hx-get="component/useHistory?id={{.ID}}" hx-target="#viewport">
+		{{.Title.String}}
+		<span class="block text-sm italic text-slate-400" {{if .LastActivity.Valid}}timestamp="{{.LastActivity.Time.UnixMilli}}"{{end}}> {{if not .LastActivity.Valid}} never worn {{end}} </span>
+	</span>
+	<button hx-post="api/suggest/skip?id={{.ID}}&skip=true" hx-target="#viewport" title="Never suggest this" class="p-1 ml-2 rounded-lg text-slate-400 hover:bg-red-200"> ✕ </button>
+</div>`
+	t := template.Must(template.New("suggestions").Parse(tmpl))
+	template.Must(t.New("pick").Parse(pickTmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, s)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func renderPlans(c calendar) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<div class="flex flex-wrap items-center mb-2">
+		<button hx-get="component/calendar?view={{.View}}&date={{day .Prev}}" hx-target="#viewport" class="p-2 rounded-lg bg-slate-50 hover:bg-slate-100"> ◀ </button>
+		<h2 class="text-lg font-bold mx-3"> {{if eq .View "month"}}{{.Start.Format "January 2006"}}{{else}}Week of {{.Start.Format "2 Jan 2006"}}{{end}} </h2>
+		<button hx-get="component/calendar?view={{.View}}&date={{day .Next}}" hx-target="#viewport" class="p-2 rounded-lg bg-slate-50 hover:bg-slate-100"> ▶ </button>
+		<button hx-get="component/calendar?view={{.View}}" hx-target="#viewport" class="p-2 ml-2 rounded-lg bg-slate-50 hover:bg-slate-100"> Today </button>
+		{{- if eq .View "month" }}
+		<button hx-get="component/calendar?view=week&date={{day .Start}}" hx-target="#viewport" class="p-2 ml-2 rounded-lg bg-slate-50 hover:bg-slate-100"> Week </button>
+		{{- else }}
+		<button hx-get="component/calendar?view=month&date={{day .Start}}" hx-target="#viewport" class="p-2 ml-2 rounded-lg bg-slate-50 hover:bg-slate-100"> Month </button>
+		{{- end }}
+		<a href="{{.Feed}}" class="p-2 ml-2 rounded-lg text-blue-600 hover:bg-blue-50"> 📅 calendar.ics </a>
+	</div>
+	<div class="flex flex-wrap items-center mb-2 text-sm text-slate-600">
+		<label for="feed"> Subscribe in a calendar app: </label>
+		<input type="text" id="feed" readonly value="{{.Feed}}" onclick="this.select()" class="border-2 p-1 mx-2 rounded-lg w-96"/>
+		<button hx-post="api/calendar/reset?view={{.View}}&date={{day .Start}}" hx-target="#viewport" hx-confirm="Make a new link? Calendars subscribed with this one stop updating." class="p-1 rounded-lg bg-slate-50 hover:bg-slate-100"> New link </button>
+	</div>
+	<form hx-post="api/plan/put" hx-target="#viewport" class="flex flex-wrap items-center">
+		<input type="hidden" name="view" value="{{.View}}"/>
+		<input type="date" name="day" required class="border-2 p-2 m-1 rounded-lg"/>
+		<select name="plan" required class="border-2 p-2 m-1 rounded-lg">
+			<option value=""> What to wear </option>
+			{{- if .Outfits }}
+			<optgroup label="Outfits">
+				{{- range .Outfits }}
+				<option value="outfit:{{.ID}}"> {{.Name}} </option>
+				{{- end }}
+			</optgroup>
+			{{- end }}
+			<optgroup label="Items">
+				{{- range .Items }}
+				<option value="item:{{.ID}}"> {{.Icon}} {{.Title.String}} </option>
+				{{- end }}
+			</optgroup>
+		</select>
+		<input type="text" name="note" placeholder="e.g Lisbon trip" class="border-2 p-2 m-1 rounded-lg"/>
+		<button class="p-2 m-1 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> Plan </button>
+	</form>
+	<div class="grid grid-cols-7 gap-1 mt-2 text-sm">
+		{{- range (index .Weeks 0) }}
+		<div class="p-1 text-center text-slate-500"> {{.Day.Format "Mon"}} </div>
+		{{- end }}
+		{{- $view := .View }}
+		{{- $start := day .Start }}
+		{{- range .Weeks }}
+		{{- range . }}
+		<div class="p-1 min-h-[5rem] rounded-lg {{if .Today}}bg-blue-50 border-2 border-blue-300{{else if .Outside}}bg-slate-50 text-slate-400{{else}}border-2{{end}}">
+			<div class="font-bold {{if .Past}}text-slate-400{{end}}"> {{.Day.Day}} </div>
+			{{- range .Plans }}
+			<div class="my-1 p-1 rounded bg-indigo-50">
+				{{if .Item}}{{.Item.Icon}}{{else if .Outfit}}👗{{end}} {{.Title}}
+				{{- if .Note.Valid }} <span class="block italic text-slate-500"> {{.Note.String}} </span> {{- end }}
+				<span class="block">
+					{{- if and (or .Item .Outfit) (not (after .Day)) }}
+					<button hx-post="api/plan/wear?id={{.ID}}&view={{$view}}&date={{$start}}" hx-target="#viewport" title="Wore it" class="px-1 rounded text-green-600 bg-green-100 hover:bg-green-200"> ✓ Worn </button>
+					{{- end }}
+					<button hx-post="api/plan/delete?id={{.ID}}&view={{$view}}&date={{$start}}" hx-target="#viewport" title="Drop this plan" class="px-1 rounded text-slate-400 hover:bg-red-200"> ✕ </button>
+				</span>
+			</div>
+			{{- end }}
+		</div>
+		{{- end }}
+		{{- end }}
+	</div>
+</div>`
+	today := dayOf(time.Now())
+	t := template.Must(template.New("plans").Funcs(template.FuncMap{
+		"day": func(t time.Time) string { return t.Format(dateFormat) },
+		// Whether the day is still to come, so what's planned can't have been worn yet.
+		"after": func(t time.Time) bool { return dayOf(t).After(today) },
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, c)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listLaundry(b laundryBasket) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	{{- if not (or .Worn .Laundry .Cleaner .Repair .Lent) }}
+	<p class="text-slate-500 p-2"> Everything is clean and put away. Set how many wears each category takes before washing in Categories. </p>
+	{{- end }}
+	{{- if .Worn }}
+	<h2 class="text-lg font-bold"> Needs washing
+		<button hx-post="api/laundry/move?to=laundry{{range .Worn}}&id={{.ID}}{{end}}" hx-target="#viewport" class="p-1 ml-2 text-sm rounded-lg text-sky-700 bg-sky-100 hover:bg-sky-200"> 🧺 Wash all </button>
+	</h2>
+	{{- range .Worn }} {{template "entry" .}} {{- end }}
+	{{- end }}
+	{{- if .Laundry }}
+	<h2 class="text-lg font-bold mt-4"> In the laundry
+		<button hx-post="api/laundry/move?to=clean{{range .Laundry}}&id={{.ID}}{{end}}" hx-target="#viewport" class="p-1 ml-2 text-sm rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> ✓ All clean </button>
+	</h2>
+	{{- range .Laundry }} {{template "entry" .}} {{- end }}
+	{{- end }}
+	{{- if .Cleaner }}
+	<h2 class="text-lg font-bold mt-4"> At the dry cleaner </h2>
+	{{- range .Cleaner }} {{template "entry" .}} {{- end }}
+	{{- end }}
+	{{- if .Repair }}
+	<h2 class="text-lg font-bold mt-4"> In repair </h2>
+	{{- range .Repair }} {{template "entry" .}} {{- end }}
+	{{- end }}
+	{{- if .Lent }}
+	<h2 class="text-lg font-bold mt-4"> Lent out </h2>
+	{{- range .Lent }} {{template "entry" .}} {{- end }}
+	{{- end }}
+</div>`
+	const entryTmpl = `
+	<div class="flex flex-wrap items-center p-1 gap-1">
+		<span class="w-8 text-center"> {{.Icon}} </span>
+		<span class="grow cursor-pointer" hx-get="component/useHistory?id={{.ID}}" hx-target="#viewport"> {{.Title.String}} </span>
+		{{- if eq .State "worn" }}
+		<button hx-post="api/laundry/move?to=laundry&id={{.ID}}" hx-target="#viewport" class="p-1 rounded-lg text-sky-700 bg-sky-100 hover:bg-sky-200"> 🧺 Wash </button>
+		<button hx-post="api/laundry/move?to=cleaner&id={{.ID}}" hx-target="#viewport" class="p-1 rounded-lg text-slate-600 bg-slate-100 hover:bg-slate-200"> Dry clean </button>
+		<button hx-post="api/laundry/move?to=clean&id={{.ID}}" hx-target="#viewport" class="p-1 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> Still clean </button>
+		{{- else }}
+		<button hx-post="api/laundry/move?to=clean&id={{.ID}}" hx-target="#viewport" class="p-1 rounded-lg text-green-600 bg-green-100 hover:bg-green-200">
+			{{- if eq .State "laundry" }} ✓ Clean {{ else if eq .State "cleaner" }} ✓ Picked up {{ else if eq .State "repair" }} ✓ Mended {{ else }} ✓ Returned {{ end -}}
+		</button>
+		{{- end }}
+	</div>`
+	t := template.Must(template.New("laundry").Parse(tmpl))
+	template.Must(t.New("entry").Parse(entryTmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, b)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listLoans(l loanList) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<h2 class="text-lg font-bold"> Lent out </h2>
+	{{- if not .Open }}
+	<p class="text-slate-500 p-2"> Nothing is lent out. Lend an item from its card. </p>
+	{{- end }}
+	<table>
+		{{- range .Open }}
+		<tr class="{{if overdue .Loan}}text-red-700 bg-red-50{{end}}">
+			<td class="p-2"> {{with .Item}}{{.Icon}}{{end}} </td>
+			<td class="p-2 cursor-pointer" hx-get="component/useHistory?id={{.CID}}" hx-target="#viewport"> {{with .Item}}{{.Title.String}}{{end}} </td>
+			<td class="p-2"> {{.Borrower}} </td>
+			<td class="p-2"> since {{.Lent.Format "2006-01-02"}} </td>
+			<td class="p-2"> {{- if .Due.Valid }} due {{date .Due}} {{- if overdue .Loan }} ⚠ overdue {{- end }} {{- end }} </td>
+			<td class="p-2"> <button hx-post="api/loan/return?id={{.ID}}" hx-target="#viewport" class="p-1 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> ✓ Returned </button> </td>
+		</tr>
+		{{- end }}
+	</table>
+	{{- if .Returned }}
+	<h2 class="text-lg font-bold mt-4"> Returned </h2>
+	<table class="text-sm text-slate-500">
+		{{- range .Returned }}
+		<tr>
+			<td class="p-2"> {{with .Item}}{{.Title.String}}{{end}} </td>
+			<td class="p-2"> {{.Borrower}} </td>
+			<td class="p-2"> {{.Lent.Format "2006-01-02"}} – {{date .Returned}} </td>
+		</tr>
+		{{- end }}
+	</table>
+	{{- end }}
+</div>`
+	t := template.Must(template.New("loans").Funcs(template.FuncMap{
+		"date":    formatDate,
+		"overdue": overdue,
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, l)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listDeclutter(v declutterView) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<form>
+		<h2 class="text-lg font-bold">
+			Not worn in
+			<input type="number" name="months" min="0" value="{{.Months}}" hx-get="component/declutter" hx-target="#viewport" hx-trigger="change" class="w-16 p-1 border-2 rounded-lg"/>
+			months
+		</h2>
+		{{- if not .Unworn }}
+		<p class="text-slate-500 p-2"> Everything has been worn lately. </p>
+		{{- else }}
+		<table>
+			<tr> <th></th> <th></th> <th class="p-2 text-left"> Item </th> <th class="p-2"> Price </th> <th class="p-2"> Last worn </th> </tr>
+			{{- range .Unworn }}
+			<tr>
+				<td class="p-2"> <input type="checkbox" name="id" value="{{.ID}}"/> </td>
+				<td class="p-2"> {{.Icon}} </td>
+				<td class="p-2 cursor-pointer" hx-get="component/useHistory?id={{.ID}}" hx-target="#viewport"> {{.Title.String}} </td>
+				<td class="p-2 text-right text-green-800"> {{if .Price.Valid}}{{money .Currency .Price.Float64}}{{end}} </td>
+				<td class="p-2 text-slate-500"> {{if .LastActivity.Valid}}<span timestamp="{{.LastActivity.Time.UnixMilli}}"></span>{{else}}never{{end}} </td>
+			</tr>
+			{{- end }}
+		</table>
+		<div class="p-2">
+			<input type="date" name="date" value="{{.Today}}" title="When they went" class="p-1 border-2 rounded-lg"/>
+			<button hx-post="api/declutter?lifecycle=archived" hx-target="#viewport" class="p-2 rounded-lg text-slate-600 bg-slate-100 hover:bg-slate-200"> Hide </button>
+			<button hx-post="api/declutter?lifecycle=donated" hx-target="#viewport" class="p-2 rounded-lg text-rose-700 bg-rose-100 hover:bg-rose-200"> Mark donated </button>
+			<button hx-post="api/declutter?lifecycle=sold" hx-target="#viewport" class="p-2 rounded-lg text-green-700 bg-green-100 hover:bg-green-200"> Mark sold </button>
+		</div>
+		{{- end }}
+	</form>
+	{{- if .Gone }}
+	<h2 class="text-lg font-bold mt-4"> Let go </h2>
+	<table class="text-sm text-slate-500">
+		{{- range .Gone }}
+		<tr>
+			<td class="p-2"> {{.Title.String}} </td>
+			<td class="p-2"> {{.Lifecycle}} {{date .Disposed}} </td>
+			<td class="p-2"> <button hx-post="api/declutter/keep?id={{.ID}}&months={{$.Months}}" hx-target="#viewport" class="p-1 rounded-lg bg-slate-50 hover:bg-slate-100"> Keep after all </button> </td>
+		</tr>
+		{{- end }}
+	</table>
+	{{- end }}
+</div>`
+	t := template.Must(template.New("declutter").Funcs(template.FuncMap{
+		"date":  formatDate,
+		"money": formatMoney,
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, v)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listWishlist(l wishlist) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<form hx-post="api/wish/put" hx-target="#viewport" class="flex flex-wrap items-end gap-2 p-2 rounded-lg bg-slate-50">
+		<input type="hidden" name="id" value="{{.Editing.ID}}"/>
+		<label class="grid"> Title <input type="text" name="title" value="{{.Editing.Title}}" required class="border-2 p-2"/> </label>
+		<label class="grid"> Category <select name="category" class="border-2 p-2">
+			<option value=""></option>
+			{{- $current := trim .Editing.Category.String }}
+			{{- range .Categories }}
+			<option value="{{.Name}}" {{if eq .Name $current}}selected{{end}}>{{.Icon}} {{.Name}}</option>
+			{{- end }}
+		</select> </label>
+		<label class="grid"> Brand <input type="text" name="brand" value="{{.Editing.Brand.String}}" class="border-2 p-2 w-32"/> </label>
+		<label class="grid"> Target price ({{.Currency}}) <input type="text" name="target_price" value="{{if .Editing.TargetPrice.Valid}}{{printf "%.2f" .Editing.TargetPrice.Float64}}{{end}}" placeholder="30.99" class="border-2 p-2 w-24"/> </label>
+		<label class="grid"> Link <input type="text" name="link" value="{{.Editing.Link.String}}" placeholder="https://… or a shop" class="border-2 p-2"/> </label>
+		<label class="grid"> Priority <select name="priority" class="border-2 p-2">
+			{{- $priority := .Editing.Priority }}
+			{{- range priorities }}
+			<option value="{{.Value}}" {{if eq .Value $priority}}selected{{end}}>{{.Label}}</option>
+			{{- end }}
+		</select> </label>
+		<label class="grid"> Notes <input type="text" name="notes" value="{{.Editing.Notes.String}}" class="border-2 p-2"/> </label>
+		<button class="p-2 rounded-lg text-green-100 bg-green-600 hover:bg-green-500 font-bold"> {{if .Editing.ID}}Save{{else}}Add to wishlist{{end}} </button>
+		{{- if .Editing.ID }}
+		<button type="button" hx-get="component/wishlist" hx-target="#viewport" class="p-2 rounded-lg bg-slate-100 hover:bg-slate-200"> Cancel </button>
+		{{- end }}
+	</form>
+
+	{{- if not .Wanted }}
+	<p class="text-slate-500 p-2"> Nothing on the wishlist. </p>
+	{{- end }}
+	{{- range .Wanted }}
+	<div class="flex flex-wrap items-center gap-2 p-2 border-b">
+		<span class="px-2 rounded-full text-sm {{if eq .Priority 1}}bg-rose-100 text-rose-700{{else if eq .Priority 2}}bg-amber-100 text-amber-700{{else}}bg-slate-100 text-slate-600{{end}}"> {{priority .Priority}} </span>
+		<b> {{.Title}} </b>
+		<span class="text-slate-500"> {{trim .Category.String}} {{- if .Brand.Valid }} ⸱ {{.Brand.String}} {{- end }} </span>
+		{{- if .TargetPrice.Valid }} <span class="text-green-800"> {{money $.Currency .TargetPrice.Float64}} </span> {{- end }}
+		{{- if .URL }} <a href="{{.URL}}" target="_blank" rel="noopener" class="text-sky-700 underline"> link </a>
+		{{- else if .Link.Valid }} <span class="text-slate-500"> at {{.Link.String}} </span> {{- end }}
+		{{- if .Notes.Valid }} <span class="italic text-slate-500"> {{.Notes.String}} </span> {{- end }}
+		<span class="grow"></span>
+		<form hx-post="api/wish/buy?id={{.ID}}" hx-target="#viewport" class="inline">
+			<input type="text" name="price" placeholder="Paid" value="{{if .TargetPrice.Valid}}{{printf "%.2f" .TargetPrice.Float64}}{{end}}" class="border-2 p-1 w-20 rounded-lg"/>
+			<button class="p-1 rounded-lg text-green-600 bg-green-100 hover:bg-green-200"> 🛍 Bought it </button>
+		</form>
+		<button hx-get="component/wishlist?edit={{.ID}}" hx-target="#viewport" class="p-1 rounded-lg text-slate-500 bg-slate-50 hover:bg-slate-100"> Edit </button>
+		<button hx-post="api/wish/delete?id={{.ID}}" hx-target="#viewport" hx-confirm="Remove this from the wishlist?" class="p-1 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> 🗑 </button>
+	</div>
+	{{- end }}
+
+	{{- if .Bought }}
+	<h2 class="text-lg font-bold mt-4"> Bought </h2>
+	<table class="text-sm">
+		<tr> <th class="p-2 text-left"> Item </th> <th class="p-2"> Bought </th> <th class="p-2"> Planned </th> <th class="p-2"> Paid </th> <th class="p-2"> Difference </th> </tr>
+		{{- range .Bought }}
+		<tr>
+			<td class="p-2"> {{- if .Item }} <span class="cursor-pointer hover:underline" hx-get="component/putCatalog?id={{.Item.ID}}" hx-target="#viewport"> {{.Title}} </span> {{- else }} {{.Title}} {{- end }} </td>
+			<td class="p-2"> {{date .Bought}} </td>
+			<td class="p-2 text-right"> {{if .TargetPrice.Valid}}{{money $.Currency .TargetPrice.Float64}}{{end}} </td>
+			<td class="p-2 text-right"> {{if and .Item .Item.Price.Valid}}{{money .Item.Currency .Item.Price.Float64}}{{end}} </td>
+			<td class="p-2 text-right"> {{- with .Overspend }} {{- if .Valid }} <span class="{{if gt .Float64 0.0}}text-red-700{{else}}text-green-800{{end}}"> {{printf "%+.2f" .Float64}} </span> {{- end }} {{- end }} </td>
+		</tr>
+		{{- end }}
+	</table>
+	{{- end }}
+</div>`
+	t := template.Must(template.New("wishlist").Funcs(template.FuncMap{
+		"trim":       strings.TrimSpace,
+		"date":       formatDate,
+		"priority":   priorityLabel,
+		"priorities": func() interface{} { return priorities },
+		"money":      formatMoney,
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, l)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listBudget(v budgetView) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<div class="flex items-center gap-2 mb-2">
+		<button hx-get="component/budget?month={{.Prev.Format "2006-01"}}" hx-target="#viewport" class="p-1 rounded-lg bg-slate-100 hover:bg-slate-200"> ← </button>
+		<h2 class="text-lg font-bold"> {{.Month.Format "January 2006"}} </h2>
+		<button hx-get="component/budget?month={{.Next.Format "2006-01"}}" hx-target="#viewport" class="p-1 rounded-lg bg-slate-100 hover:bg-slate-200"> → </button>
+		<a hx-get="component/categories" hx-target="#viewport" class="ml-4 text-sm text-slate-400 cursor-pointer hover:underline"> set limits </a>
+	</div>
+	<table>
+		<tr class="text-slate-500"> <th class="p-2 text-left"> Category </th> <th class="p-2" colspan="2"> This month </th> <th class="p-2" colspan="2"> {{.Month.Year}} </th> </tr>
+		{{- range .Categories }}
+		<tr>
+			<td class="p-2"> {{.Icon}} {{or .Name "—"}} </td>
+			<td class="p-2 text-right {{if .OverMonth}}text-red-700 font-bold{{end}}"> {{money $.Currency .Month}} {{- if .MonthlyLimit.Valid }} / {{money $.Currency .MonthlyLimit.Float64}} {{- end }} </td>
+			<td class="p-2 w-40"> {{- if .MonthlyLimit.Valid }} <div class="bg-slate-100 rounded h-3"> <div class="{{if .OverMonth}}bg-red-500{{else}}bg-green-500{{end}} rounded h-3" style="width: {{percent .Month .MonthlyLimit}}%"></div> </div> {{- end }} </td>
+			<td class="p-2 text-right {{if .OverYear}}text-red-700 font-bold{{end}}"> {{money $.Currency .Year}} {{- if .YearlyLimit.Valid }} / {{money $.Currency .YearlyLimit.Float64}} {{- end }} </td>
+			<td class="p-2 w-40"> {{- if .YearlyLimit.Valid }} <div class="bg-slate-100 rounded h-3"> <div class="{{if .OverYear}}bg-red-500{{else}}bg-green-500{{end}} rounded h-3" style="width: {{percent .Year .YearlyLimit}}%"></div> </div> {{- end }} </td>
+		</tr>
+		{{- end }}
+		<tr class="font-bold">
+			<td class="p-2"> Total </td>
+			<td class="p-2 text-right"> {{money .Currency .MonthTotal}} </td> <td></td>
+			<td class="p-2 text-right"> {{money .Currency .YearTotal}} </td> <td></td>
+		</tr>
+	</table>
+	<p class="text-sm text-slate-500 p-2"> Items count towards the month they were purchased in. Those without a purchase date aren't counted. </p>
+	{{- if .Unrated }}
+	<p class="p-2 rounded-lg bg-amber-50 text-amber-800"> Prices in {{join .Unrated ", "}} aren't counted until they have an <a hx-get="component/currencies" hx-target="#viewport" class="underline cursor-pointer">exchange rate</a>. </p>
+	{{- end }}
+</div>`
+	t := template.Must(template.New("budget").Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"money": formatMoney,
+		// How much of the limit is spent, up to all of it.
+		"percent": func(spent float64, limit sql.NullFloat64) int {
+			if limit.Float64 <= 0 || spent >= limit.Float64 {
+				return 100
+			}
+			return int(spent * 100 / limit.Float64)
+		},
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, v)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func renderWarnings(warnings []string) (string, error) {
+	const tmpl = `
+<div class="m-4 p-2 rounded-lg bg-red-50 text-red-800">
+	{{- range . }}
+	<p> ⚠ {{.}} </p>
+	{{- end }}
+</div>`
+	t := template.Must(template.New("warnings").Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, warnings)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
+
+func listCurrencies(v currenciesView) (string, error) {
+	const tmpl = `
+<div class="p-4">
+	<form hx-post="api/currency/home" hx-target="#viewport" class="mb-4">
+		<label> Prices are entered and reported in
+			<input type="text" name="currency" value="{{.Home}}" required maxlength="3" class="border-2 p-1 w-16 rounded-lg uppercase"/>
+		</label>
+		<button class="p-1 rounded-lg bg-slate-100 hover:bg-slate-200"> Change </button>
+	</form>
+	{{- if .Missing }}
+	<p class="p-2 mb-4 rounded-lg bg-amber-50 text-amber-800"> Some items are priced in {{join .Missing ", "}}, which can't be converted to {{.Home}} until it has a rate. </p>
+	{{- end }}
+	<table>
+		<tr class="text-left text-slate-500"> <th class="p-1"> Currency </th> <th class="p-1"> Worth in {{.Home}} </th> <th class="p-1"> Updated </th> </tr>
+		{{- range .Rates }}
+		<tr>
+			<td colspan="3">
+				<form hx-post="api/rate/put" hx-target="#viewport" hx-trigger="change" class="m-0">
+					<input type="hidden" name="currency" value="{{.Currency}}"/>
+					<span class="inline-block w-20 p-1 font-bold"> 1 {{.Currency}} </span>
+					<input type="text" name="rate" value="{{rate .Rate}}" required class="border-2 p-1 w-28 rounded-lg"/>
+					<span class="inline-block w-28 p-1 text-sm text-slate-400"> {{.Updated.Format "2006-01-02"}} </span>
+					<button type="button" hx-post="api/rate/delete?currency={{.Currency}}" hx-target="#viewport" hx-confirm="Forget the rate for {{.Currency}}?" class="p-1 rounded-lg text-slate-600 bg-slate-50 hover:bg-red-200"> Delete </button>
+				</form>
+			</td>
+		</tr>
+		{{- end }}
+		<tr>
+			<td colspan="3">
+				<form hx-post="api/rate/put" hx-target="#viewport" class="m-0 mt-4">
+					<input type="text" name="currency" placeholder="EUR" required maxlength="3" class="border-2 p-1 w-20 rounded-lg uppercase"/>
+					<input type="text" name="rate" placeholder="1.08" required class="border-2 p-1 w-28 rounded-lg"/>
+					<button class="p-1 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> Add </button>
+				</form>
+			</td>
+		</tr>
+	</table>
+	<h2 class="text-lg font-bold mt-6 mb-2"> Import rates </h2>
+	<p class="text-sm text-slate-500 mb-2"> A csv with a header of currency,rate, where each rate is what one unit is worth in {{.Home}}. Rates already known are replaced. </p>
+	<form hx-post="api/rate/import" hx-encoding="multipart/form-data" hx-target="#viewport">
+		<input type="file" name="rates" accept=".csv,text/csv" required/>
+		<button class="p-2 bg-green-600 hover:bg-green-500 rounded-lg text-green-100 font-bold"> Import </button>
+	</form>
+	{{- if .Imported }}
+	<p class="p-2 font-bold text-green-700"> {{.Imported}}. </p>
+	{{- end }}
+</div>`
+	t := template.Must(template.New("currencies").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"rate": func(f float64) string { return strconv.FormatFloat(f, 'g', 6, 64) },
+	}).Parse(tmpl))
+	var bs bytes.Buffer
+	err := t.Execute(&bs, v)
+	if err != nil {
+		return "", fmt.Errorf("execute tmpl: %w", err)
+	}
+	return bs.String(), nil
+}
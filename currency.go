@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Columns of an exchange rate csv. Each rate is what one unit of the currency is worth in the
+// wardrobe's currency, e.g EUR,1.08 when it's USD.
+var rateColumns = []string{"currency", "rate"}
+
+// Symbols shown in place of the codes of common currencies.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+	"KRW": "₩",
+}
+
+// Formats an amount of money in its currency, e.g $30.99 or CHF 30.99.
+func formatMoney(currency string, amount float64) string {
+	if s, ok := currencySymbols[currency]; ok {
+		return fmt.Sprintf("%v%.2f", s, amount)
+	}
+	return fmt.Sprintf("%v %.2f", currency, amount)
+}
+
+// Parses a currency code, e.g eur. Empty is def.
+func parseCurrency(s string, def string) (string, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return def, nil
+	}
+	if len(s) != 3 || strings.Trim(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("parse currency %q: expected a code, e.g EUR", s)
+	}
+	return s, nil
+}
+
+// Parses what one unit of a currency is worth in another, e.g 1.08.
+func parseRate(s string) (float64, error) {
+	r, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || r <= 0 || math.IsInf(r, 0) {
+		return 0, fmt.Errorf("parse rate %q: expected a positive number, e.g 1.08", s)
+	}
+	return r, nil
+}
+
+// The currency of the current wardrobe, which prices are entered and reported in by default.
+func homeCurrency(ctx context.Context) (string, error) {
+	w, err := store.GetWardrobe(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return "", fmt.Errorf("get wardrobe: %w", err)
+	}
+	return w.Currency, nil
+}
+
+// Converts prices into the wardrobe's currency, remembering those it had no rate for.
+type exchange struct {
+	Home  string
+	Rates map[string]float64
+	// Currencies which couldn't be converted, and so were left out.
+	Unrated []string
+}
+
+func loadExchange(ctx context.Context) (*exchange, error) {
+	home, err := homeCurrency(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := store.ListRates(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list rates: %w", err)
+	}
+	x := &exchange{Home: home, Rates: make(map[string]float64)}
+	for _, r := range rs {
+		x.Rates[r.Currency] = r.Rate
+	}
+	return x, nil
+}
+
+// The amount in the home currency, if it can be converted.
+func (x *exchange) convert(amount float64, currency string) (float64, bool) {
+	if currency == x.Home {
+		return amount, true
+	}
+	r, ok := x.Rates[currency]
+	return amount * r, ok
+}
+
+// The amount in the home currency, or nothing when there's no rate for its currency.
+func (x *exchange) toHome(amount float64, currency string) float64 {
+	v, ok := x.convert(amount, currency)
+	if !ok && amount != 0 && !containsString(x.Unrated, currency) {
+		x.Unrated = append(x.Unrated, currency)
+		sort.Strings(x.Unrated)
+	}
+	return v
+}
+
+// Currencies prices can be entered in, the home currency first.
+func (x *exchange) Currencies() []string {
+	cs := []string{x.Home}
+	for c := range x.Rates {
+		cs = append(cs, c)
+	}
+	sort.Strings(cs[1:])
+	return cs
+}
+
+// Makes another currency the wardrobe's. Rates, category budgets and wishlist target prices are
+// converted into the new currency, so a rate must be known for it unless there's nothing to
+// convert. Item prices keep the currency they were entered in.
+func setHomeCurrency(ctx context.Context, currency string) error {
+	wid := currentWardrobe(ctx)
+	return store.InTx(ctx, func(store Store) error {
+		w, err := store.GetWardrobe(ctx, wid)
+		if err != nil {
+			return fmt.Errorf("get wardrobe: %w", err)
+		}
+		if w.Currency == currency {
+			return nil
+		}
+		rs, err := store.ListRates(ctx, wid)
+		if err != nil {
+			return fmt.Errorf("list rates: %w", err)
+		}
+		var base float64
+		for _, r := range rs {
+			if r.Currency == currency {
+				base = r.Rate
+			}
+		}
+		if base == 0 {
+			priced, err := hasHomePrices(ctx, store, wid)
+			if err != nil {
+				return err
+			}
+			if len(rs) > 0 || priced {
+				return fmt.Errorf("add a rate for %v first, so rates, budgets and wishlist prices can be converted", currency)
+			}
+		}
+		for _, r := range rs {
+			if r.Currency == currency {
+				// The old currency takes the new one's place.
+				err = store.DeleteRate(ctx, persist.DeleteRateParams{WID: wid, Currency: r.Currency})
+				if err != nil {
+					return fmt.Errorf("delete rate %v: %w", r.Currency, err)
+				}
+				r = persist.ExchangeRate{Currency: w.Currency, Rate: 1, Updated: time.Now().UTC()}
+			}
+			err = store.PutRate(ctx, persist.PutRateParams{WID: wid, Currency: r.Currency, Rate: r.Rate / base, Updated: r.Updated})
+			if err != nil {
+				return fmt.Errorf("put rate %v: %w", r.Currency, err)
+			}
+		}
+		if base != 0 {
+			err = store.ConvertBudgets(ctx, persist.ConvertBudgetsParams{Rate: 1 / base, WID: wid})
+			if err != nil {
+				return fmt.Errorf("convert budgets: %w", err)
+			}
+			err = store.ConvertWishPrices(ctx, persist.ConvertWishPricesParams{Rate: 1 / base, WID: wid})
+			if err != nil {
+				return fmt.Errorf("convert wishlist prices: %w", err)
+			}
+		}
+		err = store.SetWardrobeCurrency(ctx, persist.SetWardrobeCurrencyParams{Currency: currency, ID: wid})
+		if err != nil {
+			return fmt.Errorf("set currency: %w", err)
+		}
+		return nil
+	})
+}
+
+// Whether any category budget or wishlist target price, which are in the wardrobe's currency, is set.
+func hasHomePrices(ctx context.Context, store Store, wid string) (bool, error) {
+	cs, err := store.ListCategories(ctx, wid)
+	if err != nil {
+		return false, fmt.Errorf("list categories: %w", err)
+	}
+	for _, c := range cs {
+		if c.MonthlyBudget.Valid || c.YearlyBudget.Valid {
+			return true, nil
+		}
+	}
+	ws, err := store.ListWishlist(ctx, wid)
+	if err != nil {
+		return false, fmt.Errorf("list wishlist: %w", err)
+	}
+	for _, w := range ws {
+		if w.TargetPrice.Valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Saves the rates of a csv with a header of currency,rate, replacing those already known for the
+// same currencies. Nothing is saved unless every line is valid. Returns how many were saved.
+func importRates(ctx context.Context, r io.Reader) (int, error) {
+	wid := currentWardrobe(ctx)
+	home, err := homeCurrency(ctx)
+	if err != nil {
+		return 0, err
+	}
+	rs, err := readCSV(r, rateColumns)
+	if err != nil {
+		return 0, err
+	}
+	var ps []persist.PutRateParams
+	now := time.Now().UTC()
+	for _, r := range rs {
+		c, err := parseCurrency(r.Fields["currency"], "")
+		if err != nil {
+			return 0, fmt.Errorf("line %v: %w", r.Line, err)
+		}
+		if c == "" || c == home {
+			return 0, fmt.Errorf("line %v: expected a currency other than %v", r.Line, home)
+		}
+		rate, err := parseRate(r.Fields["rate"])
+		if err != nil {
+			return 0, fmt.Errorf("line %v: %w", r.Line, err)
+		}
+		ps = append(ps, persist.PutRateParams{WID: wid, Currency: c, Rate: rate, Updated: now})
+	}
+	err = store.InTx(ctx, func(store Store) error {
+		for _, p := range ps {
+			err := store.PutRate(ctx, p)
+			if err != nil {
+				return fmt.Errorf("put rate %v: %w", p.Currency, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ps), nil
+}
+
+// The wardrobe's currency and its exchange rates, along with any currencies items are priced in
+// which can't be converted yet.
+type currenciesView struct {
+	Home    string
+	Rates   []persist.ExchangeRate
+	Missing []string
+	// What the last import did, if there was one.
+	Imported string
+}
+
+func renderCurrencies(response http.ResponseWriter, req *http.Request, imported string) error {
+	ctx := req.Context()
+	wid := currentWardrobe(ctx)
+	x, err := loadExchange(ctx)
+	if err != nil {
+		return err
+	}
+	v := currenciesView{Home: x.Home, Imported: imported}
+	v.Rates, err = store.ListRates(ctx, wid)
+	if err != nil {
+		return fmt.Errorf("list rates: %w", err)
+	}
+	cs, err := store.ListCatalog(ctx, wid)
+	if err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	for _, c := range cs {
+		x.toHome(c.Price.Float64, c.Currency)
+		x.toHome(c.ResalePrice.Float64, c.Currency)
+	}
+	v.Missing = x.Unrated
+	r, err := listCurrencies(v)
+	if err != nil {
+		return fmt.Errorf("render currencies: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleCurrencies(response http.ResponseWriter, req *http.Request) error {
+	return renderCurrencies(response, req, "")
+}
+
+// They changed the wardrobe's currency. e.g api/currency/home?currency=EUR
+func handleSetHomeCurrency(response http.ResponseWriter, req *http.Request) error {
+	c, err := parseCurrency(req.FormValue("currency"), "")
+	if err != nil {
+		return err
+	}
+	if c == "" {
+		return fmt.Errorf("pick a currency")
+	}
+	err = setHomeCurrency(req.Context(), c)
+	if err != nil {
+		return fmt.Errorf("set currency to %v: %w", c, err)
+	}
+	return renderCurrencies(response, req, "")
+}
+
+// They added or changed an exchange rate. e.g api/rate/put?currency=EUR&rate=1.08
+func handlePutRate(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	c, err := parseCurrency(req.FormValue("currency"), "")
+	if err != nil {
+		return err
+	}
+	home, err := homeCurrency(ctx)
+	if err != nil {
+		return err
+	}
+	if c == "" || c == home {
+		return fmt.Errorf("pick a currency other than %v", home)
+	}
+	rate, err := parseRate(req.FormValue("rate"))
+	if err != nil {
+		return err
+	}
+	err = store.PutRate(ctx, persist.PutRateParams{WID: currentWardrobe(ctx), Currency: c, Rate: rate, Updated: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("put rate %v: %w", c, err)
+	}
+	return renderCurrencies(response, req, "")
+}
+
+// e.g api/rate/delete?currency=EUR
+func handleDeleteRate(response http.ResponseWriter, req *http.Request) error {
+	c := req.FormValue("currency")
+	err := store.DeleteRate(req.Context(), persist.DeleteRateParams{WID: currentWardrobe(req.Context()), Currency: c})
+	if err != nil {
+		return fmt.Errorf("delete rate %v: %w", c, err)
+	}
+	return renderCurrencies(response, req, "")
+}
+
+// Imports the rates of an uploaded csv, e.g one exported from a bank or a spreadsheet.
+func handleImportRates(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseMultipartForm(maxUploadBytes)
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	f, err := formFile(req, "rates")
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return fmt.Errorf("pick a csv of rates to import")
+	}
+	defer f.Close()
+	n, err := importRates(req.Context(), f)
+	if err != nil {
+		return fmt.Errorf("import rates: %w", err)
+	}
+	return renderCurrencies(response, req, fmt.Sprintf("Imported %v rates", n))
+}
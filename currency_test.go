@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// The wardrobe's rates, by currency.
+func testRates(t *testing.T, ctx context.Context) map[string]float64 {
+	t.Helper()
+	rs, err := store.ListRates(ctx, currentWardrobe(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]float64)
+	for _, r := range rs {
+		m[r.Currency] = r.Rate
+	}
+	return m
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestImportRates(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		csv       string
+		wantN     int
+		wantRates map[string]float64
+		wantErr   string
+	}{
+		{name: "rates", csv: "currency,rate\neur,1.08\nGBP,1.25\n", wantN: 2, wantRates: map[string]float64{"EUR": 1.08, "GBP": 1.25, "JPY": 0.007}},
+		{name: "columns in any order", csv: "rate,currency\n1.1,EUR\n", wantN: 1, wantRates: map[string]float64{"EUR": 1.1, "JPY": 0.007}},
+		{name: "home currency", csv: "currency,rate\nEUR,1.08\nUSD,1\n", wantErr: "line 3: expected a currency other than USD"},
+		{name: "bad rate", csv: "currency,rate\nEUR,1.08\nGBP,-1\n", wantErr: "line 3: parse rate"},
+		{name: "bad currency", csv: "currency,rate\neuro,1.08\n", wantErr: "line 2: parse currency"},
+		{name: "unknown column", csv: "currency,rate,date\nEUR,1.08,2022-01-01\n", wantErr: `unknown column "date"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			err := store.PutRate(ctx, persist.PutRateParams{WID: currentWardrobe(ctx), Currency: "JPY", Rate: 0.007, Updated: time.Now().UTC()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := importRates(ctx, strings.NewReader(tc.csv))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("importRates: got %v, want an error containing %q", err, tc.wantErr)
+				}
+				// Nothing is saved unless every line is valid.
+				tc.wantRates = map[string]float64{"JPY": 0.007}
+			} else if err != nil {
+				t.Fatalf("importRates: %v", err)
+			} else if n != tc.wantN {
+				t.Fatalf("imported %v rates, want %v", n, tc.wantN)
+			}
+			got := testRates(t, ctx)
+			if len(got) != len(tc.wantRates) {
+				t.Fatalf("got rates %v, want %v", got, tc.wantRates)
+			}
+			for c, r := range tc.wantRates {
+				if !near(got[c], r) {
+					t.Fatalf("got rates %v, want %v", got, tc.wantRates)
+				}
+			}
+		})
+	}
+}
+
+func TestSetHomeCurrency(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// What one unit of each currency is worth in dollars, before the switch.
+		rates     map[string]float64
+		budget    sql.NullFloat64
+		target    sql.NullFloat64
+		to        string
+		wantRates map[string]float64
+		// The budget and wishlist target price after the switch.
+		wantBudget float64
+		wantTarget float64
+		wantErr    string
+	}{
+		{
+			name:      "nothing to convert",
+			to:        "EUR",
+			wantRates: map[string]float64{},
+		},
+		{
+			name:       "converts rates, budgets and target prices",
+			rates:      map[string]float64{"EUR": 1.25, "GBP": 1.5},
+			budget:     sql.NullFloat64{Valid: true, Float64: 100},
+			target:     sql.NullFloat64{Valid: true, Float64: 50},
+			to:         "EUR",
+			wantRates:  map[string]float64{"USD": 0.8, "GBP": 1.2},
+			wantBudget: 80,
+			wantTarget: 40,
+		},
+		{
+			name:      "unset prices stay unset",
+			rates:     map[string]float64{"EUR": 1.25},
+			to:        "EUR",
+			wantRates: map[string]float64{"USD": 0.8},
+		},
+		{
+			name:    "no rate for the currency",
+			rates:   map[string]float64{"GBP": 1.5},
+			to:      "EUR",
+			wantErr: "add a rate for EUR first",
+		},
+		{
+			name:    "a budget without a rate",
+			budget:  sql.NullFloat64{Valid: true, Float64: 100},
+			to:      "EUR",
+			wantErr: "add a rate for EUR first",
+		},
+		{
+			name:    "a target price without a rate",
+			target:  sql.NullFloat64{Valid: true, Float64: 50},
+			to:      "EUR",
+			wantErr: "add a rate for EUR first",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			wid := currentWardrobe(ctx)
+			for c, r := range tc.rates {
+				err := store.PutRate(ctx, persist.PutRateParams{WID: wid, Currency: c, Rate: r, Updated: time.Now().UTC()})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := store.CreateCategory(ctx, persist.CreateCategoryParams{WID: wid, Name: "Coats", Icon: "🧥", MonthlyBudget: tc.budget, YearlyBudget: tc.budget})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.PutWish(ctx, persist.PutWishParams{ID: "wish", WID: wid, Title: "Boots", TargetPrice: tc.target, Added: time.Now().UTC()})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = setHomeCurrency(ctx, tc.to)
+			home, herr := homeCurrency(ctx)
+			if herr != nil {
+				t.Fatal(herr)
+			}
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("setHomeCurrency: got %v, want an error containing %q", err, tc.wantErr)
+				}
+				if home != "USD" {
+					t.Fatalf("home currency is %v after a failed switch", home)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setHomeCurrency: %v", err)
+			}
+			if home != tc.to {
+				t.Fatalf("home currency is %v, want %v", home, tc.to)
+			}
+			got := testRates(t, ctx)
+			if len(got) != len(tc.wantRates) {
+				t.Fatalf("got rates %v, want %v", got, tc.wantRates)
+			}
+			for c, r := range tc.wantRates {
+				if !near(got[c], r) {
+					t.Fatalf("got rates %v, want %v", got, tc.wantRates)
+				}
+			}
+			c, err := store.GetCategory(ctx, persist.GetCategoryParams{WID: wid, Name: "Coats"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.MonthlyBudget.Valid != tc.budget.Valid || !near(c.MonthlyBudget.Float64, tc.wantBudget) || !near(c.YearlyBudget.Float64, tc.wantBudget) {
+				t.Fatalf("budgets are %v and %v, want %v", c.MonthlyBudget, c.YearlyBudget, tc.wantBudget)
+			}
+			w, err := store.GetWish(ctx, persist.GetWishParams{ID: "wish", WID: wid})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.TargetPrice.Valid != tc.target.Valid || !near(w.TargetPrice.Float64, tc.wantTarget) {
+				t.Fatalf("target price is %v, want %v", w.TargetPrice, tc.wantTarget)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+DROP TABLE ACTIVITY;
+DROP TABLE CATALOG;
@@ -0,0 +1,7 @@
+CREATE TABLE item_photos
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	c_id NCHAR(36) references catalog(id) NOT NULL,
+	content_type text NOT NULL,
+	ts timestamp NOT NULL
+);
@@ -0,0 +1,24 @@
+CREATE TABLE outfits
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	name text NOT NULL,
+	last_activity timestamp
+);
+
+CREATE TABLE outfit_items
+(
+	o_id NCHAR(36) references outfits(id) NOT NULL,
+	c_id NCHAR(36) references catalog(id) NOT NULL,
+	PRIMARY KEY (o_id, c_id)
+);
+
+-- Each time an outfit is worn. The usage of each of its items is logged in activity, pointing back here.
+CREATE TABLE outfit_activity
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	o_id NCHAR(36) references outfits(id) NOT NULL,
+	ts timestamp NOT NULL,
+	note text
+);
+
+ALTER TABLE activity ADD COLUMN oa_id NCHAR(36) references outfit_activity(id);
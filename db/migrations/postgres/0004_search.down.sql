@@ -0,0 +1 @@
+DROP INDEX catalog_search;
@@ -0,0 +1,6 @@
+-- Full text search over the catalog. SearchCatalog must use this exact expression to hit the index.
+CREATE INDEX catalog_search ON catalog USING GIN ((
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(category, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(color, '') || ' ' || coalesce(pattern, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'C')
+));
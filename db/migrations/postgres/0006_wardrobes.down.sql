@@ -0,0 +1,10 @@
+DELETE FROM sessions;
+ALTER TABLE sessions DROP COLUMN w_id;
+DROP INDEX outfits_w_id;
+ALTER TABLE outfits DROP COLUMN w_id;
+DROP INDEX activity_w_id;
+ALTER TABLE activity DROP COLUMN w_id;
+DROP INDEX catalog_w_id;
+ALTER TABLE catalog DROP COLUMN w_id;
+DROP TABLE wardrobe_members;
+DROP TABLE wardrobes;
@@ -0,0 +1,37 @@
+CREATE TABLE wardrobes
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	name text NOT NULL,
+	created timestamp NOT NULL
+);
+
+CREATE TABLE wardrobe_members
+(
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	u_id NCHAR(36) references users(id) NOT NULL,
+	PRIMARY KEY (w_id, u_id)
+);
+
+-- Everything from before wardrobes existed goes into one shared by all the existing accounts.
+-- When there are no accounts yet, the first one to sign up claims it. New accounts get a private wardrobe.
+INSERT INTO wardrobes (id, name, created)
+SELECT '00000000-0000-0000-0000-000000000000', 'Home', CURRENT_TIMESTAMP AT TIME ZONE 'UTC'
+WHERE EXISTS (SELECT 1 FROM catalog) OR EXISTS (SELECT 1 FROM outfits) OR EXISTS (SELECT 1 FROM users);
+INSERT INTO wardrobe_members (w_id, u_id) SELECT '00000000-0000-0000-0000-000000000000', id FROM users;
+
+-- Sessions now remember which wardrobe they're in, so everyone signs in again.
+DELETE FROM sessions;
+
+ALTER TABLE catalog ADD COLUMN w_id NCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' references wardrobes(id);
+ALTER TABLE catalog ALTER COLUMN w_id DROP DEFAULT;
+CREATE INDEX catalog_w_id ON catalog (w_id);
+
+ALTER TABLE activity ADD COLUMN w_id NCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' references wardrobes(id);
+ALTER TABLE activity ALTER COLUMN w_id DROP DEFAULT;
+CREATE INDEX activity_w_id ON activity (w_id);
+
+ALTER TABLE outfits ADD COLUMN w_id NCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' references wardrobes(id);
+ALTER TABLE outfits ALTER COLUMN w_id DROP DEFAULT;
+CREATE INDEX outfits_w_id ON outfits (w_id);
+
+ALTER TABLE sessions ADD COLUMN w_id NCHAR(36) NOT NULL references wardrobes(id);
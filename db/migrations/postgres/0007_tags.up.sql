@@ -0,0 +1,16 @@
+-- Free form labels, e.g "work" or "summer". Names are lower case and unique within a wardrobe.
+CREATE TABLE tags
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	name text NOT NULL,
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	UNIQUE (w_id, name)
+);
+
+CREATE TABLE catalog_tags
+(
+	c_id NCHAR(36) references catalog(id) NOT NULL,
+	t_id NCHAR(36) references tags(id) NOT NULL,
+	PRIMARY KEY (c_id, t_id)
+);
+CREATE INDEX catalog_tags_t_id ON catalog_tags (t_id);
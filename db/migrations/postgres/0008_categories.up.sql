@@ -0,0 +1,19 @@
+-- The categories offered for items in each wardrobe. Items refer to them by name, in catalog.category.
+CREATE TABLE categories
+(
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	name text NOT NULL,
+	icon text NOT NULL,
+	sort_order integer NOT NULL,
+	PRIMARY KEY (w_id, name)
+);
+
+-- The five which used to be built in, followed by any others already in use.
+INSERT INTO categories (w_id, name, icon, sort_order) SELECT id, 'tops', '👚', 1 FROM wardrobes;
+INSERT INTO categories (w_id, name, icon, sort_order) SELECT id, 'bottoms', '👖', 2 FROM wardrobes;
+INSERT INTO categories (w_id, name, icon, sort_order) SELECT id, 'dresses', '👗', 3 FROM wardrobes;
+INSERT INTO categories (w_id, name, icon, sort_order) SELECT id, 'accessories', '💍', 4 FROM wardrobes;
+INSERT INTO categories (w_id, name, icon, sort_order) SELECT id, 'shoes', '👠', 5 FROM wardrobes;
+INSERT INTO categories (w_id, name, icon, sort_order)
+SELECT DISTINCT w_id, TRIM(category), '', 6 FROM catalog
+WHERE TRIM(category) <> '' AND TRIM(category) NOT IN ('tops', 'bottoms', 'dresses', 'accessories', 'shoes');
@@ -0,0 +1,7 @@
+-- Seasons are a comma separated list, e.g "spring,summer". Condition is one of new, like new, good, fair or worn.
+ALTER TABLE catalog ADD COLUMN size text;
+ALTER TABLE catalog ADD COLUMN material text;
+ALTER TABLE catalog ADD COLUMN seasons text;
+ALTER TABLE catalog ADD COLUMN purchased timestamp;
+ALTER TABLE catalog ADD COLUMN retailer text;
+ALTER TABLE catalog ADD COLUMN condition text;
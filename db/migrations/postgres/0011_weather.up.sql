@@ -0,0 +1,5 @@
+-- The temperatures, in °C, an item is comfortable between. Either end may be open.
+ALTER TABLE catalog ADD COLUMN min_temp real;
+ALTER TABLE catalog ADD COLUMN max_temp real;
+-- True for items made for rain, false for those to keep out of it, and null when it doesn't matter.
+ALTER TABLE catalog ADD COLUMN rain_ok boolean;
@@ -0,0 +1,12 @@
+-- What's planned to be worn on a future day: either a single item or a whole outfit.
+CREATE TABLE planned_usage
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	day timestamp NOT NULL,
+	c_id NCHAR(36) references catalog(id),
+	o_id NCHAR(36) references outfits(id),
+	note text,
+	CHECK ((c_id IS NULL) <> (o_id IS NULL))
+);
+CREATE INDEX planned_usage_w_id_day ON planned_usage (w_id, day);
@@ -0,0 +1,2 @@
+ALTER TABLE catalog DROP COLUMN disposed;
+ALTER TABLE catalog DROP COLUMN disposition;
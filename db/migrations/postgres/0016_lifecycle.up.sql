@@ -0,0 +1,8 @@
+-- Where an item is in its life: active, archived, sold, donated, discarded or lost. Hidden items
+-- are those which aren't active, and those hidden before without a disposition were archived.
+ALTER TABLE catalog ADD COLUMN lifecycle text NOT NULL DEFAULT 'active';
+UPDATE catalog SET lifecycle = COALESCE(disposition, CASE WHEN hidden THEN 'archived' ELSE 'active' END);
+ALTER TABLE catalog DROP COLUMN disposition;
+-- What the item was sold for, and anything worth remembering about where it went.
+ALTER TABLE catalog ADD COLUMN resale_price real;
+ALTER TABLE catalog ADD COLUMN lifecycle_note text;
@@ -0,0 +1,2 @@
+DROP INDEX wishlist_w_id;
+DROP TABLE wishlist;
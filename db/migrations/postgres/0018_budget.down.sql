@@ -0,0 +1,2 @@
+ALTER TABLE categories DROP COLUMN yearly_budget;
+ALTER TABLE categories DROP COLUMN monthly_budget;
@@ -0,0 +1,3 @@
+-- How much may be spent on items of the category each month and each year. Null is no limit.
+ALTER TABLE categories ADD COLUMN monthly_budget real;
+ALTER TABLE categories ADD COLUMN yearly_budget real;
@@ -0,0 +1,3 @@
+DROP TABLE exchange_rates;
+ALTER TABLE catalog DROP COLUMN currency;
+ALTER TABLE wardrobes DROP COLUMN currency;
@@ -0,0 +1,17 @@
+-- The currency prices are entered and reported in, unless an item says otherwise. Prices were
+-- always shown in dollars before.
+ALTER TABLE wardrobes ADD COLUMN currency text NOT NULL DEFAULT 'USD';
+
+-- The currency the item's price and resale price are in, e.g EUR.
+ALTER TABLE catalog ADD COLUMN currency text NOT NULL DEFAULT 'USD';
+
+-- What one unit of each foreign currency is worth in the wardrobe's currency, kept locally so
+-- reports work offline.
+CREATE TABLE exchange_rates
+(
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	currency text NOT NULL,
+	rate real NOT NULL,
+	updated timestamp NOT NULL,
+	PRIMARY KEY (w_id, currency)
+);
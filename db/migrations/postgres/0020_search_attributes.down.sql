@@ -0,0 +1,6 @@
+DROP INDEX catalog_search;
+CREATE INDEX catalog_search ON catalog USING GIN ((
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(category, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(color, '') || ' ' || coalesce(pattern, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'C')
+));
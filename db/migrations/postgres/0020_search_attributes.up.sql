@@ -0,0 +1,8 @@
+-- Free text search also matches size, material, seasons and retailer. SearchCatalog must use this
+-- exact expression to hit the index.
+DROP INDEX catalog_search;
+CREATE INDEX catalog_search ON catalog USING GIN ((
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(category, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(color, '') || ' ' || coalesce(pattern, '') || ' ' || coalesce(size, '') || ' ' || coalesce(material, '') || ' ' || coalesce(seasons, '') || ' ' || coalesce(retailer, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'C')
+));
@@ -0,0 +1,10 @@
+-- Secret links to a user's plans in a wardrobe, which calendar apps subscribe to without signing in.
+-- Unlike session ids the token is kept as is, so the link can be shown again. It only reads plans.
+CREATE TABLE calendar_feeds
+(
+	token text NOT NULL PRIMARY KEY,
+	u_id NCHAR(36) references users(id) NOT NULL,
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	created timestamp NOT NULL,
+	UNIQUE (u_id, w_id)
+);
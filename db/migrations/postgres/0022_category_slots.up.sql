@@ -0,0 +1,9 @@
+-- The part of an outfit items in the category fill, e.g top, which suggestions are built from.
+-- Empty for categories which aren't part of suggested outfits, e.g accessories.
+ALTER TABLE categories ADD COLUMN slot text NOT NULL DEFAULT '';
+
+-- Suggestions used to go by the default names.
+UPDATE categories SET slot='top' WHERE name='tops';
+UPDATE categories SET slot='bottom' WHERE name='bottoms';
+UPDATE categories SET slot='dress' WHERE name='dresses';
+UPDATE categories SET slot='shoes' WHERE name='shoes';
@@ -19,4 +19,4 @@ CREATE TABLE IF NOT EXISTS ACTIVITY
 	c_id NCHAR(36) references catalog(id) NOT NULL,
 	ts timestamp NOT NULL,
 	note text
-);
\ No newline at end of file
+);
@@ -0,0 +1 @@
+DROP TABLE item_photos;
@@ -0,0 +1,4 @@
+ALTER TABLE activity DROP COLUMN oa_id;
+DROP TABLE outfit_activity;
+DROP TABLE outfit_items;
+DROP TABLE outfits;
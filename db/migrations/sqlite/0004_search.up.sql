@@ -0,0 +1,2 @@
+-- SQLite has no equivalent of the Postgres full text index; its search scans the catalog instead.
+SELECT 1;
@@ -0,0 +1,17 @@
+CREATE TABLE users
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	username text NOT NULL UNIQUE,
+	-- bcrypt hash of the password.
+	password_hash text NOT NULL,
+	created timestamp NOT NULL
+);
+
+-- Signed in browsers. The id is the sha256 of the session cookie, so a leaked table can't be used to sign in.
+CREATE TABLE sessions
+(
+	id text NOT NULL PRIMARY KEY,
+	u_id NCHAR(36) references users(id) NOT NULL,
+	created timestamp NOT NULL,
+	expires timestamp NOT NULL
+);
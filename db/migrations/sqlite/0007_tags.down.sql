@@ -0,0 +1,3 @@
+DROP INDEX catalog_tags_t_id;
+DROP TABLE catalog_tags;
+DROP TABLE tags;
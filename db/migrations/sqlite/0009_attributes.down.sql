@@ -0,0 +1,6 @@
+ALTER TABLE catalog DROP COLUMN condition;
+ALTER TABLE catalog DROP COLUMN retailer;
+ALTER TABLE catalog DROP COLUMN purchased;
+ALTER TABLE catalog DROP COLUMN seasons;
+ALTER TABLE catalog DROP COLUMN material;
+ALTER TABLE catalog DROP COLUMN size;
@@ -0,0 +1,2 @@
+-- Items the user never wants suggested, e.g formal wear.
+ALTER TABLE catalog ADD COLUMN skip_suggest boolean NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+ALTER TABLE catalog DROP COLUMN rain_ok;
+ALTER TABLE catalog DROP COLUMN max_temp;
+ALTER TABLE catalog DROP COLUMN min_temp;
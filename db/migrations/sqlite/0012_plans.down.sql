@@ -0,0 +1,2 @@
+DROP INDEX planned_usage_w_id_day;
+DROP TABLE planned_usage;
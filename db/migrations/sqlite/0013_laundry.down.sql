@@ -0,0 +1,5 @@
+DROP INDEX state_changes_c_id;
+DROP TABLE state_changes;
+ALTER TABLE categories DROP COLUMN wears_before_wash;
+ALTER TABLE catalog DROP COLUMN wears_since_clean;
+ALTER TABLE catalog DROP COLUMN state;
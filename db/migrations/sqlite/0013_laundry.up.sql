@@ -0,0 +1,18 @@
+-- Where an item is: clean, worn, laundry, cleaner (at the dry cleaner), lent or repair.
+ALTER TABLE catalog ADD COLUMN state text NOT NULL DEFAULT 'clean';
+-- Uses since the item was last clean, for moving it to worn once its category's limit is reached.
+ALTER TABLE catalog ADD COLUMN wears_since_clean integer NOT NULL DEFAULT 0;
+-- How many wears an item of the category takes before it needs washing. Null never marks them worn.
+ALTER TABLE categories ADD COLUMN wears_before_wash integer;
+
+-- Each time an item moves from one state to another.
+CREATE TABLE state_changes
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	c_id NCHAR(36) references catalog(id) NOT NULL,
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	ts timestamp NOT NULL,
+	from_state text NOT NULL,
+	to_state text NOT NULL
+);
+CREATE INDEX state_changes_c_id ON state_changes (c_id);
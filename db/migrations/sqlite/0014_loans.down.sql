@@ -0,0 +1,2 @@
+DROP INDEX loans_c_id;
+DROP TABLE loans;
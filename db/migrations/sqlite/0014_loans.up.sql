@@ -0,0 +1,12 @@
+-- Items lent to someone. A loan is open until it's returned, and the item is lent out meanwhile.
+CREATE TABLE loans
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	c_id NCHAR(36) references catalog(id) NOT NULL,
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	borrower text NOT NULL,
+	lent timestamp NOT NULL,
+	due timestamp,
+	returned timestamp
+);
+CREATE INDEX loans_c_id ON loans (c_id);
@@ -0,0 +1,3 @@
+-- What became of an item which was let go, e.g donated or sold, and when. Null while it's kept.
+ALTER TABLE catalog ADD COLUMN disposition text;
+ALTER TABLE catalog ADD COLUMN disposed timestamp;
@@ -0,0 +1,9 @@
+-- Put disposition back before disposed, where it was.
+ALTER TABLE catalog RENAME COLUMN disposed TO disposed_old;
+ALTER TABLE catalog ADD COLUMN disposition text;
+ALTER TABLE catalog ADD COLUMN disposed timestamp;
+UPDATE catalog SET disposition = NULLIF(lifecycle, 'active'), disposed = disposed_old;
+ALTER TABLE catalog DROP COLUMN disposed_old;
+ALTER TABLE catalog DROP COLUMN lifecycle_note;
+ALTER TABLE catalog DROP COLUMN resale_price;
+ALTER TABLE catalog DROP COLUMN lifecycle;
@@ -0,0 +1,20 @@
+-- Items being considered for buying. Once bought, an entry points at the catalog item it became,
+-- and keeps the price that was planned.
+CREATE TABLE wishlist
+(
+	id NCHAR(36) NOT NULL PRIMARY KEY,
+	w_id NCHAR(36) references wardrobes(id) NOT NULL,
+	title text NOT NULL,
+	category text,
+	brand text,
+	target_price real,
+	-- Where to find it, e.g a URL or a shop.
+	link text,
+	-- 1 is the highest.
+	priority integer NOT NULL DEFAULT 2,
+	notes text,
+	added timestamp NOT NULL,
+	bought timestamp,
+	c_id NCHAR(36) references catalog(id)
+);
+CREATE INDEX wishlist_w_id ON wishlist (w_id);
@@ -12,18 +12,170 @@ type Activity struct {
 	CID  string
 	Ts   time.Time
 	Note sql.NullString
+	OaID sql.NullString
+	WID  string
+}
+
+type CalendarFeed struct {
+	Token   string
+	UID     string
+	WID     string
+	Created time.Time
 }
 
 type Catalog struct {
+	ID              string
+	Category        sql.NullString
+	Brand           sql.NullString
+	Color           sql.NullString
+	Pattern         sql.NullString
+	Title           sql.NullString
+	Description     sql.NullString
+	Price           sql.NullFloat64
+	LastActivity    sql.NullTime
+	LastNote        sql.NullString
+	Hidden          bool
+	WID             string
+	Size            sql.NullString
+	Material        sql.NullString
+	Seasons         sql.NullString
+	Purchased       sql.NullTime
+	Retailer        sql.NullString
+	Condition       sql.NullString
+	SkipSuggest     bool
+	MinTemp         sql.NullFloat64
+	MaxTemp         sql.NullFloat64
+	RainOk          sql.NullBool
+	State           string
+	WearsSinceClean int32
+	Disposed        sql.NullTime
+	Lifecycle       string
+	ResalePrice     sql.NullFloat64
+	LifecycleNote   sql.NullString
+	Currency        string
+}
+
+type CatalogTag struct {
+	CID string
+	TID string
+}
+
+type Category struct {
+	WID             string
+	Name            string
+	Icon            string
+	SortOrder       int32
+	WearsBeforeWash sql.NullInt32
+	MonthlyBudget   sql.NullFloat64
+	YearlyBudget    sql.NullFloat64
+	Slot            string
+}
+
+type ExchangeRate struct {
+	WID      string
+	Currency string
+	Rate     float64
+	Updated  time.Time
+}
+
+type ItemPhoto struct {
+	ID          string
+	CID         string
+	ContentType string
+	Ts          time.Time
+}
+
+type Loan struct {
+	ID       string
+	CID      string
+	WID      string
+	Borrower string
+	Lent     time.Time
+	Due      sql.NullTime
+	Returned sql.NullTime
+}
+
+type Outfit struct {
 	ID           string
-	Category     sql.NullString
-	Brand        sql.NullString
-	Color        sql.NullString
-	Pattern      sql.NullString
-	Title        sql.NullString
-	Description  sql.NullString
-	Price        sql.NullFloat64
+	Name         string
 	LastActivity sql.NullTime
-	LastNote     sql.NullString
-	Hidden       bool
+	WID          string
+}
+
+type OutfitActivity struct {
+	ID   string
+	OID  string
+	Ts   time.Time
+	Note sql.NullString
+}
+
+type OutfitItem struct {
+	OID string
+	CID string
+}
+
+type PlannedUsage struct {
+	ID   string
+	WID  string
+	Day  time.Time
+	CID  sql.NullString
+	OID  sql.NullString
+	Note sql.NullString
+}
+
+type Session struct {
+	ID      string
+	UID     string
+	Created time.Time
+	Expires time.Time
+	WID     string
+}
+
+type StateChange struct {
+	ID        string
+	CID       string
+	WID       string
+	Ts        time.Time
+	FromState string
+	ToState   string
+}
+
+type Tag struct {
+	ID   string
+	Name string
+	WID  string
+}
+
+type User struct {
+	ID           string
+	Username     string
+	PasswordHash string
+	Created      time.Time
+}
+
+type Wardrobe struct {
+	ID       string
+	Name     string
+	Created  time.Time
+	Currency string
+}
+
+type WardrobeMember struct {
+	WID string
+	UID string
+}
+
+type Wish struct {
+	ID          string
+	WID         string
+	Title       string
+	Category    sql.NullString
+	Brand       sql.NullString
+	TargetPrice sql.NullFloat64
+	Link        sql.NullString
+	Priority    int32
+	Notes       sql.NullString
+	Added       time.Time
+	Bought      sql.NullTime
+	CID         sql.NullString
 }
@@ -9,24 +9,2124 @@ import (
 	"time"
 )
 
+const addItemTag = `-- name: AddItemTag :exec
+INSERT INTO catalog_tags (c_id, t_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type AddItemTagParams struct {
+	CID string
+	TID string
+}
+
+func (q *Queries) AddItemTag(ctx context.Context, arg AddItemTagParams) error {
+	_, err := q.db.ExecContext(ctx, addItemTag, arg.CID, arg.TID)
+	return err
+}
+
+const addOutfitItem = `-- name: AddOutfitItem :exec
+INSERT INTO outfit_items (o_id, c_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type AddOutfitItemParams struct {
+	OID string
+	CID string
+}
+
+func (q *Queries) AddOutfitItem(ctx context.Context, arg AddOutfitItemParams) error {
+	_, err := q.db.ExecContext(ctx, addOutfitItem, arg.OID, arg.CID)
+	return err
+}
+
+const addPhoto = `-- name: AddPhoto :exec
+INSERT INTO item_photos(id, c_id, content_type, ts) VALUES ($1, $2, $3, $4)
+`
+
+type AddPhotoParams struct {
+	ID          string
+	CID         string
+	ContentType string
+	Ts          time.Time
+}
+
+func (q *Queries) AddPhoto(ctx context.Context, arg AddPhotoParams) error {
+	_, err := q.db.ExecContext(ctx, addPhoto,
+		arg.ID,
+		arg.CID,
+		arg.ContentType,
+		arg.Ts,
+	)
+	return err
+}
+
+const addWardrobeMember = `-- name: AddWardrobeMember :exec
+INSERT INTO wardrobe_members (w_id, u_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type AddWardrobeMemberParams struct {
+	WID string
+	UID string
+}
+
+func (q *Queries) AddWardrobeMember(ctx context.Context, arg AddWardrobeMemberParams) error {
+	_, err := q.db.ExecContext(ctx, addWardrobeMember, arg.WID, arg.UID)
+	return err
+}
+
+const catalogIDTaken = `-- name: CatalogIDTaken :one
+SELECT COUNT(*) > 0 FROM catalog WHERE id=$1
+`
+
+func (q *Queries) CatalogIDTaken(ctx context.Context, id string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, catalogIDTaken, id)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const claimOrphanWardrobes = `-- name: ClaimOrphanWardrobes :exec
+INSERT INTO wardrobe_members (w_id, u_id)
+SELECT id, $1 FROM wardrobes WHERE id NOT IN (SELECT w_id FROM wardrobe_members)
+`
+
+func (q *Queries) ClaimOrphanWardrobes(ctx context.Context, uID string) error {
+	_, err := q.db.ExecContext(ctx, claimOrphanWardrobes, uID)
+	return err
+}
+
+const clearItemTags = `-- name: ClearItemTags :exec
+DELETE FROM catalog_tags WHERE c_id IN (SELECT id FROM catalog WHERE id=$1 AND w_id=$2)
+`
+
+type ClearItemTagsParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) ClearItemTags(ctx context.Context, arg ClearItemTagsParams) error {
+	_, err := q.db.ExecContext(ctx, clearItemTags, arg.ID, arg.WID)
+	return err
+}
+
+const clearOutfitItems = `-- name: ClearOutfitItems :exec
+DELETE FROM outfit_items WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2)
+`
+
+type ClearOutfitItemsParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) ClearOutfitItems(ctx context.Context, arg ClearOutfitItemsParams) error {
+	_, err := q.db.ExecContext(ctx, clearOutfitItems, arg.ID, arg.WID)
+	return err
+}
+
+const convertBudgets = `-- name: ConvertBudgets :exec
+UPDATE categories SET monthly_budget=monthly_budget*$1, yearly_budget=yearly_budget*$1 WHERE w_id=$2
+`
+
+type ConvertBudgetsParams struct {
+	Rate float64
+	WID  string
+}
+
+func (q *Queries) ConvertBudgets(ctx context.Context, arg ConvertBudgetsParams) error {
+	_, err := q.db.ExecContext(ctx, convertBudgets, arg.Rate, arg.WID)
+	return err
+}
+
+const convertWishPrices = `-- name: ConvertWishPrices :exec
+UPDATE wishlist SET target_price=target_price*$1 WHERE w_id=$2
+`
+
+type ConvertWishPricesParams struct {
+	Rate float64
+	WID  string
+}
+
+func (q *Queries) ConvertWishPrices(ctx context.Context, arg ConvertWishPricesParams) error {
+	_, err := q.db.ExecContext(ctx, convertWishPrices, arg.Rate, arg.WID)
+	return err
+}
+
+const countCategoryItems = `-- name: CountCategoryItems :one
+SELECT COUNT(*) FROM catalog WHERE w_id=$1 AND TRIM(category)=$2
+`
+
+type CountCategoryItemsParams struct {
+	WID  string
+	Name string
+}
+
+func (q *Queries) CountCategoryItems(ctx context.Context, arg CountCategoryItemsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCategoryItems, arg.WID, arg.Name)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countWear = `-- name: CountWear :exec
+UPDATE catalog SET wears_since_clean = wears_since_clean + 1 WHERE id=$1 AND w_id=$2
+`
+
+type CountWearParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) CountWear(ctx context.Context, arg CountWearParams) error {
+	_, err := q.db.ExecContext(ctx, countWear, arg.ID, arg.WID)
+	return err
+}
+
+const createCategory = `-- name: CreateCategory :exec
+INSERT INTO categories (w_id, name, icon, sort_order, wears_before_wash, monthly_budget, yearly_budget, slot) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateCategoryParams struct {
+	WID             string
+	Name            string
+	Icon            string
+	SortOrder       int32
+	WearsBeforeWash sql.NullInt32
+	MonthlyBudget   sql.NullFloat64
+	YearlyBudget    sql.NullFloat64
+	Slot            string
+}
+
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, createCategory,
+		arg.WID,
+		arg.Name,
+		arg.Icon,
+		arg.SortOrder,
+		arg.WearsBeforeWash,
+		arg.MonthlyBudget,
+		arg.YearlyBudget,
+		arg.Slot,
+	)
+	return err
+}
+
+const createSession = `-- name: CreateSession :exec
+INSERT INTO sessions (id, u_id, created, expires, w_id) VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateSessionParams struct {
+	ID      string
+	UID     string
+	Created time.Time
+	Expires time.Time
+	WID     string
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createSession,
+		arg.ID,
+		arg.UID,
+		arg.Created,
+		arg.Expires,
+		arg.WID,
+	)
+	return err
+}
+
+const createTag = `-- name: CreateTag :exec
+INSERT INTO tags (id, name, w_id) VALUES ($1, $2, $3) ON CONFLICT (w_id, name) DO NOTHING
+`
+
+type CreateTagParams struct {
+	ID   string
+	Name string
+	WID  string
+}
+
+func (q *Queries) CreateTag(ctx context.Context, arg CreateTagParams) error {
+	_, err := q.db.ExecContext(ctx, createTag, arg.ID, arg.Name, arg.WID)
+	return err
+}
+
+const createUser = `-- name: CreateUser :exec
+INSERT INTO users (id, username, password_hash, created) VALUES ($1, $2, $3, $4)
+`
+
+type CreateUserParams struct {
+	ID           string
+	Username     string
+	PasswordHash string
+	Created      time.Time
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
+	_, err := q.db.ExecContext(ctx, createUser,
+		arg.ID,
+		arg.Username,
+		arg.PasswordHash,
+		arg.Created,
+	)
+	return err
+}
+
+const createWardrobe = `-- name: CreateWardrobe :exec
+INSERT INTO wardrobes (id, name, created) VALUES ($1, $2, $3)
+`
+
+type CreateWardrobeParams struct {
+	ID      string
+	Name    string
+	Created time.Time
+}
+
+func (q *Queries) CreateWardrobe(ctx context.Context, arg CreateWardrobeParams) error {
+	_, err := q.db.ExecContext(ctx, createWardrobe, arg.ID, arg.Name, arg.Created)
+	return err
+}
+
+const deleteAllCategories = `-- name: DeleteAllCategories :exec
+DELETE FROM categories WHERE w_id=$1
+`
+
+func (q *Queries) DeleteAllCategories(ctx context.Context, wID string) error {
+	_, err := q.db.ExecContext(ctx, deleteAllCategories, wID)
+	return err
+}
+
+const deleteAllOutfitUsage = `-- name: DeleteAllOutfitUsage :exec
+DELETE FROM outfit_activity WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2)
+`
+
+type DeleteAllOutfitUsageParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeleteAllOutfitUsage(ctx context.Context, arg DeleteAllOutfitUsageParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAllOutfitUsage, arg.ID, arg.WID)
+	return err
+}
+
+const deleteAllRates = `-- name: DeleteAllRates :exec
+DELETE FROM exchange_rates WHERE w_id=$1
+`
+
+func (q *Queries) DeleteAllRates(ctx context.Context, wID string) error {
+	_, err := q.db.ExecContext(ctx, deleteAllRates, wID)
+	return err
+}
+
+const deleteCategory = `-- name: DeleteCategory :exec
+DELETE FROM categories WHERE w_id=$1 AND name=$2
+`
+
+type DeleteCategoryParams struct {
+	WID  string
+	Name string
+}
+
+func (q *Queries) DeleteCategory(ctx context.Context, arg DeleteCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, deleteCategory, arg.WID, arg.Name)
+	return err
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires < $1
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expires time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredSessions, expires)
+	return err
+}
+
+const deleteItem = `-- name: DeleteItem :exec
+DELETE FROM catalog WHERE id=$1 AND w_id=$2
+`
+
+type DeleteItemParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeleteItem(ctx context.Context, arg DeleteItemParams) error {
+	_, err := q.db.ExecContext(ctx, deleteItem, arg.ID, arg.WID)
+	return err
+}
+
+const deleteItemLoans = `-- name: DeleteItemLoans :exec
+DELETE FROM loans WHERE c_id=$1 AND w_id=$2
+`
+
+type DeleteItemLoansParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) DeleteItemLoans(ctx context.Context, arg DeleteItemLoansParams) error {
+	_, err := q.db.ExecContext(ctx, deleteItemLoans, arg.CID, arg.WID)
+	return err
+}
+
+const deleteItemOutfits = `-- name: DeleteItemOutfits :exec
+DELETE FROM outfit_items WHERE c_id IN (SELECT id FROM catalog WHERE id=$1 AND w_id=$2)
+`
+
+type DeleteItemOutfitsParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeleteItemOutfits(ctx context.Context, arg DeleteItemOutfitsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteItemOutfits, arg.ID, arg.WID)
+	return err
+}
+
+const deleteItemPhotos = `-- name: DeleteItemPhotos :exec
+DELETE FROM item_photos WHERE c_id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2)
+`
+
+type DeleteItemPhotosParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) DeleteItemPhotos(ctx context.Context, arg DeleteItemPhotosParams) error {
+	_, err := q.db.ExecContext(ctx, deleteItemPhotos, arg.CID, arg.WID)
+	return err
+}
+
+const deleteItemPlans = `-- name: DeleteItemPlans :exec
+DELETE FROM planned_usage WHERE c_id=$1 AND w_id=$2
+`
+
+type DeleteItemPlansParams struct {
+	CID sql.NullString
+	WID string
+}
+
+func (q *Queries) DeleteItemPlans(ctx context.Context, arg DeleteItemPlansParams) error {
+	_, err := q.db.ExecContext(ctx, deleteItemPlans, arg.CID, arg.WID)
+	return err
+}
+
+const deleteItemStateChanges = `-- name: DeleteItemStateChanges :exec
+DELETE FROM state_changes WHERE c_id=$1 AND w_id=$2
+`
+
+type DeleteItemStateChangesParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) DeleteItemStateChanges(ctx context.Context, arg DeleteItemStateChangesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteItemStateChanges, arg.CID, arg.WID)
+	return err
+}
+
+const deleteItemUsage = `-- name: DeleteItemUsage :exec
+DELETE FROM activity WHERE c_id=$1 AND w_id=$2
+`
+
+type DeleteItemUsageParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) DeleteItemUsage(ctx context.Context, arg DeleteItemUsageParams) error {
+	_, err := q.db.ExecContext(ctx, deleteItemUsage, arg.CID, arg.WID)
+	return err
+}
+
+const deleteOutfit = `-- name: DeleteOutfit :exec
+DELETE FROM outfits WHERE id=$1 AND w_id=$2
+`
+
+type DeleteOutfitParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeleteOutfit(ctx context.Context, arg DeleteOutfitParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOutfit, arg.ID, arg.WID)
+	return err
+}
+
+const deleteOutfitItemUsage = `-- name: DeleteOutfitItemUsage :exec
+DELETE FROM activity WHERE oa_id=$1 AND w_id=$2
+`
+
+type DeleteOutfitItemUsageParams struct {
+	OaID sql.NullString
+	WID  string
+}
+
+func (q *Queries) DeleteOutfitItemUsage(ctx context.Context, arg DeleteOutfitItemUsageParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOutfitItemUsage, arg.OaID, arg.WID)
+	return err
+}
+
+const deleteOutfitPlans = `-- name: DeleteOutfitPlans :exec
+DELETE FROM planned_usage WHERE o_id=$1 AND w_id=$2
+`
+
+type DeleteOutfitPlansParams struct {
+	OID sql.NullString
+	WID string
+}
+
+func (q *Queries) DeleteOutfitPlans(ctx context.Context, arg DeleteOutfitPlansParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOutfitPlans, arg.OID, arg.WID)
+	return err
+}
+
+const deleteOutfitUsage = `-- name: DeleteOutfitUsage :exec
+DELETE FROM outfit_activity WHERE id=$1 AND o_id IN (SELECT id FROM outfits WHERE w_id=$2)
+`
+
+type DeleteOutfitUsageParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeleteOutfitUsage(ctx context.Context, arg DeleteOutfitUsageParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOutfitUsage, arg.ID, arg.WID)
+	return err
+}
+
+const deletePhoto = `-- name: DeletePhoto :exec
+DELETE FROM item_photos WHERE id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2)
+`
+
+type DeletePhotoParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeletePhoto(ctx context.Context, arg DeletePhotoParams) error {
+	_, err := q.db.ExecContext(ctx, deletePhoto, arg.ID, arg.WID)
+	return err
+}
+
+const deletePlan = `-- name: DeletePlan :exec
+DELETE FROM planned_usage WHERE id=$1 AND w_id=$2
+`
+
+type DeletePlanParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeletePlan(ctx context.Context, arg DeletePlanParams) error {
+	_, err := q.db.ExecContext(ctx, deletePlan, arg.ID, arg.WID)
+	return err
+}
+
+const deleteRate = `-- name: DeleteRate :exec
+DELETE FROM exchange_rates WHERE w_id=$1 AND currency=$2
+`
+
+type DeleteRateParams struct {
+	WID      string
+	Currency string
+}
+
+func (q *Queries) DeleteRate(ctx context.Context, arg DeleteRateParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRate, arg.WID, arg.Currency)
+	return err
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id=$1
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	return err
+}
+
+const deleteUnusedTags = `-- name: DeleteUnusedTags :exec
+DELETE FROM tags WHERE w_id=$1 AND id NOT IN (SELECT t_id FROM catalog_tags)
+`
+
+func (q *Queries) DeleteUnusedTags(ctx context.Context, wID string) error {
+	_, err := q.db.ExecContext(ctx, deleteUnusedTags, wID)
+	return err
+}
+
+const deleteUsage = `-- name: DeleteUsage :exec
+DELETE FROM activity WHERE id=$1 AND w_id=$2
+`
+
+type DeleteUsageParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeleteUsage(ctx context.Context, arg DeleteUsageParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUsage, arg.ID, arg.WID)
+	return err
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :exec
+DELETE FROM sessions WHERE u_id=$1
+`
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, uID string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSessions, uID)
+	return err
+}
+
+const deleteWish = `-- name: DeleteWish :exec
+DELETE FROM wishlist WHERE id=$1 AND w_id=$2
+`
+
+type DeleteWishParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) DeleteWish(ctx context.Context, arg DeleteWishParams) error {
+	_, err := q.db.ExecContext(ctx, deleteWish, arg.ID, arg.WID)
+	return err
+}
+
+const detachOutfitUsage = `-- name: DetachOutfitUsage :exec
+UPDATE activity SET oa_id=NULL WHERE w_id=$2 AND oa_id IN (SELECT id FROM outfit_activity WHERE o_id=$1)
+`
+
+type DetachOutfitUsageParams struct {
+	OID string
+	WID string
+}
+
+func (q *Queries) DetachOutfitUsage(ctx context.Context, arg DetachOutfitUsageParams) error {
+	_, err := q.db.ExecContext(ctx, detachOutfitUsage, arg.OID, arg.WID)
+	return err
+}
+
+const forgetItemWishes = `-- name: ForgetItemWishes :exec
+UPDATE wishlist SET c_id=NULL WHERE c_id=$1 AND w_id=$2
+`
+
+type ForgetItemWishesParams struct {
+	CID sql.NullString
+	WID string
+}
+
+func (q *Queries) ForgetItemWishes(ctx context.Context, arg ForgetItemWishesParams) error {
+	_, err := q.db.ExecContext(ctx, forgetItemWishes, arg.CID, arg.WID)
+	return err
+}
+
 const getAllUsage = `-- name: GetAllUsage :many
-SELECT id, c_id, ts, note FROM ACTIVITY WHERE c_id=$1 ORDER BY ts DESC
+SELECT id, c_id, ts, note, oa_id, w_id FROM ACTIVITY WHERE c_id=$1 AND w_id=$2 ORDER BY ts DESC
+`
+
+type GetAllUsageParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) GetAllUsage(ctx context.Context, arg GetAllUsageParams) ([]Activity, error) {
+	rows, err := q.db.QueryContext(ctx, getAllUsage, arg.CID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Activity
+	for rows.Next() {
+		var i Activity
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.Ts,
+			&i.Note,
+			&i.OaID,
+			&i.WID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCalendarFeed = `-- name: GetCalendarFeed :one
+SELECT token, u_id, w_id, created FROM calendar_feeds WHERE token=$1
+`
+
+func (q *Queries) GetCalendarFeed(ctx context.Context, token string) (CalendarFeed, error) {
+	row := q.db.QueryRowContext(ctx, getCalendarFeed, token)
+	var i CalendarFeed
+	err := row.Scan(
+		&i.Token,
+		&i.UID,
+		&i.WID,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getCatalog = `-- name: GetCatalog :one
+SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden, w_id, size, material, seasons, purchased, retailer, condition, skip_suggest, min_temp, max_temp, rain_ok, state, wears_since_clean, disposed, lifecycle, resale_price, lifecycle_note, currency FROM CATALOG WHERE id=$1 AND w_id=$2
+`
+
+type GetCatalogParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetCatalog(ctx context.Context, arg GetCatalogParams) (Catalog, error) {
+	row := q.db.QueryRowContext(ctx, getCatalog, arg.ID, arg.WID)
+	var i Catalog
+	err := row.Scan(
+		&i.ID,
+		&i.Category,
+		&i.Brand,
+		&i.Color,
+		&i.Pattern,
+		&i.Title,
+		&i.Description,
+		&i.Price,
+		&i.LastActivity,
+		&i.LastNote,
+		&i.Hidden,
+		&i.WID,
+		&i.Size,
+		&i.Material,
+		&i.Seasons,
+		&i.Purchased,
+		&i.Retailer,
+		&i.Condition,
+		&i.SkipSuggest,
+		&i.MinTemp,
+		&i.MaxTemp,
+		&i.RainOk,
+		&i.State,
+		&i.WearsSinceClean,
+		&i.Disposed,
+		&i.Lifecycle,
+		&i.ResalePrice,
+		&i.LifecycleNote,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getCategory = `-- name: GetCategory :one
+SELECT w_id, name, icon, sort_order, wears_before_wash, monthly_budget, yearly_budget, slot FROM categories WHERE w_id=$1 AND name=$2
+`
+
+type GetCategoryParams struct {
+	WID  string
+	Name string
+}
+
+func (q *Queries) GetCategory(ctx context.Context, arg GetCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategory, arg.WID, arg.Name)
+	var i Category
+	err := row.Scan(
+		&i.WID,
+		&i.Name,
+		&i.Icon,
+		&i.SortOrder,
+		&i.WearsBeforeWash,
+		&i.MonthlyBudget,
+		&i.YearlyBudget,
+		&i.Slot,
+	)
+	return i, err
+}
+
+const getLastUsage = `-- name: GetLastUsage :one
+SELECT id, c_id, ts, note, oa_id, w_id FROM ACTIVITY WHERE c_id=$1 AND w_id=$2 ORDER BY ts DESC LIMIT 1
+`
+
+type GetLastUsageParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) GetLastUsage(ctx context.Context, arg GetLastUsageParams) (Activity, error) {
+	row := q.db.QueryRowContext(ctx, getLastUsage, arg.CID, arg.WID)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.CID,
+		&i.Ts,
+		&i.Note,
+		&i.OaID,
+		&i.WID,
+	)
+	return i, err
+}
+
+const getLoan = `-- name: GetLoan :one
+SELECT id, c_id, w_id, borrower, lent, due, returned FROM loans WHERE id=$1 AND w_id=$2
+`
+
+type GetLoanParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetLoan(ctx context.Context, arg GetLoanParams) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, getLoan, arg.ID, arg.WID)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.CID,
+		&i.WID,
+		&i.Borrower,
+		&i.Lent,
+		&i.Due,
+		&i.Returned,
+	)
+	return i, err
+}
+
+const getOutfit = `-- name: GetOutfit :one
+SELECT id, name, last_activity, w_id FROM outfits WHERE id=$1 AND w_id=$2
+`
+
+type GetOutfitParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetOutfit(ctx context.Context, arg GetOutfitParams) (Outfit, error) {
+	row := q.db.QueryRowContext(ctx, getOutfit, arg.ID, arg.WID)
+	var i Outfit
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.LastActivity,
+		&i.WID,
+	)
+	return i, err
+}
+
+const getOutfitUsage = `-- name: GetOutfitUsage :one
+SELECT id, o_id, ts, note FROM outfit_activity WHERE id=$1 AND o_id IN (SELECT id FROM outfits WHERE w_id=$2)
+`
+
+type GetOutfitUsageParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetOutfitUsage(ctx context.Context, arg GetOutfitUsageParams) (OutfitActivity, error) {
+	row := q.db.QueryRowContext(ctx, getOutfitUsage, arg.ID, arg.WID)
+	var i OutfitActivity
+	err := row.Scan(
+		&i.ID,
+		&i.OID,
+		&i.Ts,
+		&i.Note,
+	)
+	return i, err
+}
+
+const getPhoto = `-- name: GetPhoto :one
+SELECT id, c_id, content_type, ts FROM item_photos WHERE id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2)
+`
+
+type GetPhotoParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetPhoto(ctx context.Context, arg GetPhotoParams) (ItemPhoto, error) {
+	row := q.db.QueryRowContext(ctx, getPhoto, arg.ID, arg.WID)
+	var i ItemPhoto
+	err := row.Scan(
+		&i.ID,
+		&i.CID,
+		&i.ContentType,
+		&i.Ts,
+	)
+	return i, err
+}
+
+const getPlan = `-- name: GetPlan :one
+SELECT id, w_id, day, c_id, o_id, note FROM planned_usage WHERE id=$1 AND w_id=$2
+`
+
+type GetPlanParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetPlan(ctx context.Context, arg GetPlanParams) (PlannedUsage, error) {
+	row := q.db.QueryRowContext(ctx, getPlan, arg.ID, arg.WID)
+	var i PlannedUsage
+	err := row.Scan(
+		&i.ID,
+		&i.WID,
+		&i.Day,
+		&i.CID,
+		&i.OID,
+		&i.Note,
+	)
+	return i, err
+}
+
+const getSession = `-- name: GetSession :one
+SELECT id, u_id, created, expires, w_id FROM sessions WHERE id=$1
+`
+
+func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UID,
+		&i.Created,
+		&i.Expires,
+		&i.WID,
+	)
+	return i, err
+}
+
+const getTagByName = `-- name: GetTagByName :one
+SELECT id, name, w_id FROM tags WHERE name=$1 AND w_id=$2
+`
+
+type GetTagByNameParams struct {
+	Name string
+	WID  string
+}
+
+func (q *Queries) GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTagByName, arg.Name, arg.WID)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.WID,
+	)
+	return i, err
+}
+
+const getUsage = `-- name: GetUsage :one
+SELECT id, c_id, ts, note, oa_id, w_id FROM ACTIVITY WHERE id=$1 AND w_id=$2
+`
+
+type GetUsageParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetUsage(ctx context.Context, arg GetUsageParams) (Activity, error) {
+	row := q.db.QueryRowContext(ctx, getUsage, arg.ID, arg.WID)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.CID,
+		&i.Ts,
+		&i.Note,
+		&i.OaID,
+		&i.WID,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, username, password_hash, created FROM users WHERE id=$1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getUserByName = `-- name: GetUserByName :one
+SELECT id, username, password_hash, created FROM users WHERE username=$1
+`
+
+func (q *Queries) GetUserByName(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByName, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getUserCalendarFeed = `-- name: GetUserCalendarFeed :one
+SELECT token, u_id, w_id, created FROM calendar_feeds WHERE u_id=$1 AND w_id=$2
+`
+
+type GetUserCalendarFeedParams struct {
+	UID string
+	WID string
+}
+
+func (q *Queries) GetUserCalendarFeed(ctx context.Context, arg GetUserCalendarFeedParams) (CalendarFeed, error) {
+	row := q.db.QueryRowContext(ctx, getUserCalendarFeed, arg.UID, arg.WID)
+	var i CalendarFeed
+	err := row.Scan(
+		&i.Token,
+		&i.UID,
+		&i.WID,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getWardrobe = `-- name: GetWardrobe :one
+SELECT id, name, created, currency FROM wardrobes WHERE id=$1
+`
+
+func (q *Queries) GetWardrobe(ctx context.Context, id string) (Wardrobe, error) {
+	row := q.db.QueryRowContext(ctx, getWardrobe, id)
+	var i Wardrobe
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Created,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getWish = `-- name: GetWish :one
+SELECT id, w_id, title, category, brand, target_price, link, priority, notes, added, bought, c_id FROM wishlist WHERE id=$1 AND w_id=$2
+`
+
+type GetWishParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) GetWish(ctx context.Context, arg GetWishParams) (Wish, error) {
+	row := q.db.QueryRowContext(ctx, getWish, arg.ID, arg.WID)
+	var i Wish
+	err := row.Scan(
+		&i.ID,
+		&i.WID,
+		&i.Title,
+		&i.Category,
+		&i.Brand,
+		&i.TargetPrice,
+		&i.Link,
+		&i.Priority,
+		&i.Notes,
+		&i.Added,
+		&i.Bought,
+		&i.CID,
+	)
+	return i, err
+}
+
+const isWardrobeMember = `-- name: IsWardrobeMember :one
+SELECT COUNT(*) > 0 FROM wardrobe_members WHERE w_id=$1 AND u_id=$2
+`
+
+type IsWardrobeMemberParams struct {
+	WID string
+	UID string
+}
+
+func (q *Queries) IsWardrobeMember(ctx context.Context, arg IsWardrobeMemberParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isWardrobeMember, arg.WID, arg.UID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const itemStats = `-- name: ItemStats :many
+SELECT catalog.id, catalog.title, catalog.category, catalog.brand, catalog.price, catalog.last_activity, catalog.hidden,
+	catalog.lifecycle, catalog.resale_price, catalog.currency, COUNT(activity.id) AS wears
+FROM catalog LEFT JOIN activity ON activity.c_id = catalog.id
+WHERE catalog.w_id=$1
+GROUP BY catalog.id
+ORDER BY wears DESC, catalog.last_activity DESC NULLS LAST
+`
+
+type ItemStatsRow struct {
+	ID           string
+	Title        sql.NullString
+	Category     sql.NullString
+	Brand        sql.NullString
+	Price        sql.NullFloat64
+	LastActivity sql.NullTime
+	Hidden       bool
+	Lifecycle    string
+	ResalePrice  sql.NullFloat64
+	Currency     string
+	Wears        int64
+}
+
+func (q *Queries) ItemStats(ctx context.Context, wID string) ([]ItemStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, itemStats, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemStatsRow
+	for rows.Next() {
+		var i ItemStatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Category,
+			&i.Brand,
+			&i.Price,
+			&i.LastActivity,
+			&i.Hidden,
+			&i.Lifecycle,
+			&i.ResalePrice,
+			&i.Currency,
+			&i.Wears,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lendItem = `-- name: LendItem :exec
+INSERT INTO loans (id, c_id, w_id, borrower, lent, due) VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type LendItemParams struct {
+	ID       string
+	CID      string
+	WID      string
+	Borrower string
+	Lent     time.Time
+	Due      sql.NullTime
+}
+
+func (q *Queries) LendItem(ctx context.Context, arg LendItemParams) error {
+	_, err := q.db.ExecContext(ctx, lendItem,
+		arg.ID,
+		arg.CID,
+		arg.WID,
+		arg.Borrower,
+		arg.Lent,
+		arg.Due,
+	)
+	return err
+}
+
+const listAllCatalogTags = `-- name: ListAllCatalogTags :many
+SELECT catalog_tags.c_id, catalog_tags.t_id FROM catalog_tags JOIN tags ON tags.id = catalog_tags.t_id WHERE tags.w_id=$1
+`
+
+func (q *Queries) ListAllCatalogTags(ctx context.Context, wID string) ([]CatalogTag, error) {
+	rows, err := q.db.QueryContext(ctx, listAllCatalogTags, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CatalogTag
+	for rows.Next() {
+		var i CatalogTag
+		if err := rows.Scan(
+			&i.CID,
+			&i.TID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllItemTags = `-- name: ListAllItemTags :many
+SELECT catalog_tags.c_id, tags.name FROM tags JOIN catalog_tags ON catalog_tags.t_id = tags.id
+WHERE tags.w_id=$1
+ORDER BY tags.name ASC
+`
+
+type ListAllItemTagsRow struct {
+	CID  string
+	Name string
+}
+
+func (q *Queries) ListAllItemTags(ctx context.Context, wID string) ([]ListAllItemTagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAllItemTags, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAllItemTagsRow
+	for rows.Next() {
+		var i ListAllItemTagsRow
+		if err := rows.Scan(
+			&i.CID,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllOutfitItems = `-- name: ListAllOutfitItems :many
+SELECT o_id, c_id FROM outfit_items WHERE o_id IN (SELECT id FROM outfits WHERE w_id=$1)
+`
+
+func (q *Queries) ListAllOutfitItems(ctx context.Context, wID string) ([]OutfitItem, error) {
+	rows, err := q.db.QueryContext(ctx, listAllOutfitItems, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutfitItem
+	for rows.Next() {
+		var i OutfitItem
+		if err := rows.Scan(
+			&i.OID,
+			&i.CID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllOutfitUsage = `-- name: ListAllOutfitUsage :many
+SELECT id, o_id, ts, note FROM outfit_activity WHERE o_id IN (SELECT id FROM outfits WHERE w_id=$1) ORDER BY ts ASC
+`
+
+func (q *Queries) ListAllOutfitUsage(ctx context.Context, wID string) ([]OutfitActivity, error) {
+	rows, err := q.db.QueryContext(ctx, listAllOutfitUsage, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutfitActivity
+	for rows.Next() {
+		var i OutfitActivity
+		if err := rows.Scan(
+			&i.ID,
+			&i.OID,
+			&i.Ts,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllPhotos = `-- name: ListAllPhotos :many
+SELECT id, c_id, content_type, ts FROM item_photos WHERE c_id IN (SELECT id FROM catalog WHERE w_id=$1) ORDER BY ts ASC
+`
+
+func (q *Queries) ListAllPhotos(ctx context.Context, wID string) ([]ItemPhoto, error) {
+	rows, err := q.db.QueryContext(ctx, listAllPhotos, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemPhoto
+	for rows.Next() {
+		var i ItemPhoto
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.ContentType,
+			&i.Ts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllPlans = `-- name: ListAllPlans :many
+SELECT id, w_id, day, c_id, o_id, note FROM planned_usage WHERE w_id=$1 ORDER BY day ASC
+`
+
+func (q *Queries) ListAllPlans(ctx context.Context, wID string) ([]PlannedUsage, error) {
+	rows, err := q.db.QueryContext(ctx, listAllPlans, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlannedUsage
+	for rows.Next() {
+		var i PlannedUsage
+		if err := rows.Scan(
+			&i.ID,
+			&i.WID,
+			&i.Day,
+			&i.CID,
+			&i.OID,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllStateChanges = `-- name: ListAllStateChanges :many
+SELECT id, c_id, w_id, ts, from_state, to_state FROM state_changes WHERE w_id=$1 ORDER BY ts ASC
+`
+
+func (q *Queries) ListAllStateChanges(ctx context.Context, wID string) ([]StateChange, error) {
+	rows, err := q.db.QueryContext(ctx, listAllStateChanges, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StateChange
+	for rows.Next() {
+		var i StateChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.WID,
+			&i.Ts,
+			&i.FromState,
+			&i.ToState,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCatalog = `-- name: ListCatalog :many
+SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden, w_id, size, material, seasons, purchased, retailer, condition, skip_suggest, min_temp, max_temp, rain_ok, state, wears_since_clean, disposed, lifecycle, resale_price, lifecycle_note, currency FROM CATALOG WHERE w_id=$1 ORDER BY hidden ASC, last_activity DESC NULLS LAST
+`
+
+func (q *Queries) ListCatalog(ctx context.Context, wID string) ([]Catalog, error) {
+	rows, err := q.db.QueryContext(ctx, listCatalog, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Catalog
+	for rows.Next() {
+		var i Catalog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Category,
+			&i.Brand,
+			&i.Color,
+			&i.Pattern,
+			&i.Title,
+			&i.Description,
+			&i.Price,
+			&i.LastActivity,
+			&i.LastNote,
+			&i.Hidden,
+			&i.WID,
+			&i.Size,
+			&i.Material,
+			&i.Seasons,
+			&i.Purchased,
+			&i.Retailer,
+			&i.Condition,
+			&i.SkipSuggest,
+			&i.MinTemp,
+			&i.MaxTemp,
+			&i.RainOk,
+			&i.State,
+			&i.WearsSinceClean,
+			&i.Disposed,
+			&i.Lifecycle,
+			&i.ResalePrice,
+			&i.LifecycleNote,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT w_id, name, icon, sort_order, wears_before_wash, monthly_budget, yearly_budget, slot FROM categories WHERE w_id=$1 ORDER BY sort_order ASC, name ASC
+`
+
+func (q *Queries) ListCategories(ctx context.Context, wID string) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategories, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.WID,
+			&i.Name,
+			&i.Icon,
+			&i.SortOrder,
+			&i.WearsBeforeWash,
+			&i.MonthlyBudget,
+			&i.YearlyBudget,
+			&i.Slot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemLoans = `-- name: ListItemLoans :many
+SELECT id, c_id, w_id, borrower, lent, due, returned FROM loans WHERE c_id=$1 AND w_id=$2 ORDER BY lent DESC
+`
+
+type ListItemLoansParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) ListItemLoans(ctx context.Context, arg ListItemLoansParams) ([]Loan, error) {
+	rows, err := q.db.QueryContext(ctx, listItemLoans, arg.CID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Loan
+	for rows.Next() {
+		var i Loan
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.WID,
+			&i.Borrower,
+			&i.Lent,
+			&i.Due,
+			&i.Returned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemTags = `-- name: ListItemTags :many
+SELECT tags.name FROM tags JOIN catalog_tags ON catalog_tags.t_id = tags.id
+WHERE catalog_tags.c_id=$1 AND tags.w_id=$2
+ORDER BY tags.name ASC
+`
+
+type ListItemTagsParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) ListItemTags(ctx context.Context, arg ListItemTagsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listItemTags, arg.CID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLoans = `-- name: ListLoans :many
+SELECT id, c_id, w_id, borrower, lent, due, returned FROM loans WHERE w_id=$1 ORDER BY lent DESC
+`
+
+func (q *Queries) ListLoans(ctx context.Context, wID string) ([]Loan, error) {
+	rows, err := q.db.QueryContext(ctx, listLoans, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Loan
+	for rows.Next() {
+		var i Loan
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.WID,
+			&i.Borrower,
+			&i.Lent,
+			&i.Due,
+			&i.Returned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenLoans = `-- name: ListOpenLoans :many
+SELECT id, c_id, w_id, borrower, lent, due, returned FROM loans WHERE w_id=$1 AND returned IS NULL ORDER BY lent ASC
+`
+
+func (q *Queries) ListOpenLoans(ctx context.Context, wID string) ([]Loan, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenLoans, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Loan
+	for rows.Next() {
+		var i Loan
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.WID,
+			&i.Borrower,
+			&i.Lent,
+			&i.Due,
+			&i.Returned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutfitItemUsage = `-- name: ListOutfitItemUsage :many
+SELECT id, c_id, ts, note, oa_id, w_id FROM activity WHERE oa_id=$1 AND w_id=$2
+`
+
+type ListOutfitItemUsageParams struct {
+	OaID sql.NullString
+	WID  string
+}
+
+func (q *Queries) ListOutfitItemUsage(ctx context.Context, arg ListOutfitItemUsageParams) ([]Activity, error) {
+	rows, err := q.db.QueryContext(ctx, listOutfitItemUsage, arg.OaID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Activity
+	for rows.Next() {
+		var i Activity
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.Ts,
+			&i.Note,
+			&i.OaID,
+			&i.WID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutfitItems = `-- name: ListOutfitItems :many
+SELECT o_id, c_id FROM outfit_items WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2)
+`
+
+type ListOutfitItemsParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) ListOutfitItems(ctx context.Context, arg ListOutfitItemsParams) ([]OutfitItem, error) {
+	rows, err := q.db.QueryContext(ctx, listOutfitItems, arg.ID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutfitItem
+	for rows.Next() {
+		var i OutfitItem
+		if err := rows.Scan(
+			&i.OID,
+			&i.CID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutfitUsage = `-- name: ListOutfitUsage :many
+SELECT id, o_id, ts, note FROM outfit_activity WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2) ORDER BY ts DESC
+`
+
+type ListOutfitUsageParams struct {
+	ID  string
+	WID string
+}
+
+func (q *Queries) ListOutfitUsage(ctx context.Context, arg ListOutfitUsageParams) ([]OutfitActivity, error) {
+	rows, err := q.db.QueryContext(ctx, listOutfitUsage, arg.ID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutfitActivity
+	for rows.Next() {
+		var i OutfitActivity
+		if err := rows.Scan(
+			&i.ID,
+			&i.OID,
+			&i.Ts,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutfits = `-- name: ListOutfits :many
+SELECT id, name, last_activity, w_id FROM outfits WHERE w_id=$1 ORDER BY last_activity DESC NULLS LAST, name ASC
+`
+
+func (q *Queries) ListOutfits(ctx context.Context, wID string) ([]Outfit, error) {
+	rows, err := q.db.QueryContext(ctx, listOutfits, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outfit
+	for rows.Next() {
+		var i Outfit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.LastActivity,
+			&i.WID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPhotos = `-- name: ListPhotos :many
+SELECT id, c_id, content_type, ts FROM item_photos WHERE c_id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2) ORDER BY ts ASC
+`
+
+type ListPhotosParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) ListPhotos(ctx context.Context, arg ListPhotosParams) ([]ItemPhoto, error) {
+	rows, err := q.db.QueryContext(ctx, listPhotos, arg.CID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemPhoto
+	for rows.Next() {
+		var i ItemPhoto
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.ContentType,
+			&i.Ts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlans = `-- name: ListPlans :many
+SELECT id, w_id, day, c_id, o_id, note FROM planned_usage WHERE w_id=$1 AND day >= $2 AND day < $3
+ORDER BY day ASC
+`
+
+type ListPlansParams struct {
+	WID     string
+	FromDay time.Time
+	ToDay   time.Time
+}
+
+func (q *Queries) ListPlans(ctx context.Context, arg ListPlansParams) ([]PlannedUsage, error) {
+	rows, err := q.db.QueryContext(ctx, listPlans, arg.WID, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlannedUsage
+	for rows.Next() {
+		var i PlannedUsage
+		if err := rows.Scan(
+			&i.ID,
+			&i.WID,
+			&i.Day,
+			&i.CID,
+			&i.OID,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurchases = `-- name: ListPurchases :many
+SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden, w_id, size, material, seasons, purchased, retailer, condition, skip_suggest, min_temp, max_temp, rain_ok, state, wears_since_clean, disposed, lifecycle, resale_price, lifecycle_note, currency FROM catalog WHERE w_id=$1 AND purchased >= $2 AND purchased < $3
+ORDER BY purchased ASC
+`
+
+type ListPurchasesParams struct {
+	WID     string
+	FromDay time.Time
+	ToDay   time.Time
+}
+
+func (q *Queries) ListPurchases(ctx context.Context, arg ListPurchasesParams) ([]Catalog, error) {
+	rows, err := q.db.QueryContext(ctx, listPurchases, arg.WID, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Catalog
+	for rows.Next() {
+		var i Catalog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Category,
+			&i.Brand,
+			&i.Color,
+			&i.Pattern,
+			&i.Title,
+			&i.Description,
+			&i.Price,
+			&i.LastActivity,
+			&i.LastNote,
+			&i.Hidden,
+			&i.WID,
+			&i.Size,
+			&i.Material,
+			&i.Seasons,
+			&i.Purchased,
+			&i.Retailer,
+			&i.Condition,
+			&i.SkipSuggest,
+			&i.MinTemp,
+			&i.MaxTemp,
+			&i.RainOk,
+			&i.State,
+			&i.WearsSinceClean,
+			&i.Disposed,
+			&i.Lifecycle,
+			&i.ResalePrice,
+			&i.LifecycleNote,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRates = `-- name: ListRates :many
+SELECT w_id, currency, rate, updated FROM exchange_rates WHERE w_id=$1 ORDER BY currency ASC
+`
+
+func (q *Queries) ListRates(ctx context.Context, wID string) ([]ExchangeRate, error) {
+	rows, err := q.db.QueryContext(ctx, listRates, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExchangeRate
+	for rows.Next() {
+		var i ExchangeRate
+		if err := rows.Scan(
+			&i.WID,
+			&i.Currency,
+			&i.Rate,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStateChanges = `-- name: ListStateChanges :many
+SELECT id, c_id, w_id, ts, from_state, to_state FROM state_changes WHERE c_id=$1 AND w_id=$2 ORDER BY ts DESC
+`
+
+type ListStateChangesParams struct {
+	CID string
+	WID string
+}
+
+func (q *Queries) ListStateChanges(ctx context.Context, arg ListStateChangesParams) ([]StateChange, error) {
+	rows, err := q.db.QueryContext(ctx, listStateChanges, arg.CID, arg.WID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StateChange
+	for rows.Next() {
+		var i StateChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.WID,
+			&i.Ts,
+			&i.FromState,
+			&i.ToState,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTags = `-- name: ListTags :many
+SELECT id, name, w_id FROM tags WHERE w_id=$1 ORDER BY name ASC
+`
+
+func (q *Queries) ListTags(ctx context.Context, wID string) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTags, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.WID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsage = `-- name: ListUsage :many
+SELECT id, c_id, ts, note, oa_id, w_id FROM ACTIVITY WHERE w_id=$1 ORDER BY ts DESC
+`
+
+func (q *Queries) ListUsage(ctx context.Context, wID string) ([]Activity, error) {
+	rows, err := q.db.QueryContext(ctx, listUsage, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Activity
+	for rows.Next() {
+		var i Activity
+		if err := rows.Scan(
+			&i.ID,
+			&i.CID,
+			&i.Ts,
+			&i.Note,
+			&i.OaID,
+			&i.WID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWardrobes = `-- name: ListUserWardrobes :many
+SELECT wardrobes.id, wardrobes.name, wardrobes.created, wardrobes.currency FROM wardrobes JOIN wardrobe_members ON wardrobe_members.w_id = wardrobes.id
+WHERE wardrobe_members.u_id=$1
+ORDER BY wardrobes.created ASC
 `
 
-func (q *Queries) GetAllUsage(ctx context.Context, cID string) ([]Activity, error) {
-	rows, err := q.db.QueryContext(ctx, getAllUsage, cID)
+func (q *Queries) ListUserWardrobes(ctx context.Context, uID string) ([]Wardrobe, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWardrobes, uID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Activity
+	var items []Wardrobe
 	for rows.Next() {
-		var i Activity
+		var i Wardrobe
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Created,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWardrobeMembers = `-- name: ListWardrobeMembers :many
+SELECT users.id, users.username FROM users JOIN wardrobe_members ON wardrobe_members.u_id = users.id
+WHERE wardrobe_members.w_id=$1
+ORDER BY users.username ASC
+`
+
+type ListWardrobeMembersRow struct {
+	ID       string
+	Username string
+}
+
+func (q *Queries) ListWardrobeMembers(ctx context.Context, wID string) ([]ListWardrobeMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWardrobeMembers, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWardrobeMembersRow
+	for rows.Next() {
+		var i ListWardrobeMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWishlist = `-- name: ListWishlist :many
+SELECT id, w_id, title, category, brand, target_price, link, priority, notes, added, bought, c_id FROM wishlist WHERE w_id=$1 ORDER BY priority ASC, added ASC
+`
+
+func (q *Queries) ListWishlist(ctx context.Context, wID string) ([]Wish, error) {
+	rows, err := q.db.QueryContext(ctx, listWishlist, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wish
+	for rows.Next() {
+		var i Wish
 		if err := rows.Scan(
 			&i.ID,
+			&i.WID,
+			&i.Title,
+			&i.Category,
+			&i.Brand,
+			&i.TargetPrice,
+			&i.Link,
+			&i.Priority,
+			&i.Notes,
+			&i.Added,
+			&i.Bought,
 			&i.CID,
-			&i.Ts,
-			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -41,204 +2141,700 @@ func (q *Queries) GetAllUsage(ctx context.Context, cID string) ([]Activity, erro
 	return items, nil
 }
 
-const getCatalog = `-- name: GetCatalog :one
-SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden FROM CATALOG WHERE id=$1
+const logOutfitItemUsage = `-- name: LogOutfitItemUsage :exec
+INSERT INTO activity (id, c_id, ts, oa_id, w_id) VALUES ($1, $2, $3, $4, $5)
+`
+
+type LogOutfitItemUsageParams struct {
+	ID   string
+	CID  string
+	Ts   time.Time
+	OaID sql.NullString
+	WID  string
+}
+
+func (q *Queries) LogOutfitItemUsage(ctx context.Context, arg LogOutfitItemUsageParams) error {
+	_, err := q.db.ExecContext(ctx, logOutfitItemUsage,
+		arg.ID,
+		arg.CID,
+		arg.Ts,
+		arg.OaID,
+		arg.WID,
+	)
+	return err
+}
+
+const logOutfitUsage = `-- name: LogOutfitUsage :exec
+INSERT INTO outfit_activity (id, o_id, ts) VALUES ($1, $2, $3)
+`
+
+type LogOutfitUsageParams struct {
+	ID  string
+	OID string
+	Ts  time.Time
+}
+
+func (q *Queries) LogOutfitUsage(ctx context.Context, arg LogOutfitUsageParams) error {
+	_, err := q.db.ExecContext(ctx, logOutfitUsage, arg.ID, arg.OID, arg.Ts)
+	return err
+}
+
+const logStateChange = `-- name: LogStateChange :exec
+INSERT INTO state_changes (id, c_id, w_id, ts, from_state, to_state) VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type LogStateChangeParams struct {
+	ID        string
+	CID       string
+	WID       string
+	Ts        time.Time
+	FromState string
+	ToState   string
+}
+
+func (q *Queries) LogStateChange(ctx context.Context, arg LogStateChangeParams) error {
+	_, err := q.db.ExecContext(ctx, logStateChange,
+		arg.ID,
+		arg.CID,
+		arg.WID,
+		arg.Ts,
+		arg.FromState,
+		arg.ToState,
+	)
+	return err
+}
+
+const logUsage = `-- name: LogUsage :execresult
+INSERT INTO ACTIVITY(id, c_id, ts, w_id) values ($1, $2, $3, $4)
+`
+
+type LogUsageParams struct {
+	ID  string
+	CID string
+	Ts  time.Time
+	WID string
+}
+
+func (q *Queries) LogUsage(ctx context.Context, arg LogUsageParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, logUsage,
+		arg.ID,
+		arg.CID,
+		arg.Ts,
+		arg.WID,
+	)
+}
+
+const markWishBought = `-- name: MarkWishBought :exec
+UPDATE wishlist SET bought=$1, c_id=$2 WHERE id=$3 AND w_id=$4
+`
+
+type MarkWishBoughtParams struct {
+	Bought sql.NullTime
+	CID    sql.NullString
+	ID     string
+	WID    string
+}
+
+func (q *Queries) MarkWishBought(ctx context.Context, arg MarkWishBoughtParams) error {
+	_, err := q.db.ExecContext(ctx, markWishBought,
+		arg.Bought,
+		arg.CID,
+		arg.ID,
+		arg.WID,
+	)
+	return err
+}
+
+const putCalendarFeed = `-- name: PutCalendarFeed :exec
+INSERT INTO calendar_feeds (token, u_id, w_id, created) VALUES ($1, $2, $3, $4)
+ON CONFLICT (u_id, w_id) DO UPDATE SET token=$1, created=$4
+`
+
+type PutCalendarFeedParams struct {
+	Token   string
+	UID     string
+	WID     string
+	Created time.Time
+}
+
+func (q *Queries) PutCalendarFeed(ctx context.Context, arg PutCalendarFeedParams) error {
+	_, err := q.db.ExecContext(ctx, putCalendarFeed,
+		arg.Token,
+		arg.UID,
+		arg.WID,
+		arg.Created,
+	)
+	return err
+}
+
+const putItem = `-- name: PutItem :execresult
+INSERT INTO catalog
+(id, category, brand, color, pattern, title, description, price, w_id, size, material, seasons, purchased, retailer, condition,
+	min_temp, max_temp, rain_ok, currency)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+ON CONFLICT (id) DO UPDATE
+SET category=$2, brand=$3, color=$4, pattern=$5, title=$6, description=$7, price=$8,
+	size=$10, material=$11, seasons=$12, purchased=$13, retailer=$14, condition=$15,
+	min_temp=$16, max_temp=$17, rain_ok=$18, currency=$19
+WHERE catalog.w_id=$9
+`
+
+type PutItemParams struct {
+	ID          string
+	Category    sql.NullString
+	Brand       sql.NullString
+	Color       sql.NullString
+	Pattern     sql.NullString
+	Title       sql.NullString
+	Description sql.NullString
+	Price       sql.NullFloat64
+	WID         string
+	Size        sql.NullString
+	Material    sql.NullString
+	Seasons     sql.NullString
+	Purchased   sql.NullTime
+	Retailer    sql.NullString
+	Condition   sql.NullString
+	MinTemp     sql.NullFloat64
+	MaxTemp     sql.NullFloat64
+	RainOk      sql.NullBool
+	Currency    string
+}
+
+func (q *Queries) PutItem(ctx context.Context, arg PutItemParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, putItem,
+		arg.ID,
+		arg.Category,
+		arg.Brand,
+		arg.Color,
+		arg.Pattern,
+		arg.Title,
+		arg.Description,
+		arg.Price,
+		arg.WID,
+		arg.Size,
+		arg.Material,
+		arg.Seasons,
+		arg.Purchased,
+		arg.Retailer,
+		arg.Condition,
+		arg.MinTemp,
+		arg.MaxTemp,
+		arg.RainOk,
+		arg.Currency,
+	)
+}
+
+const putOutfit = `-- name: PutOutfit :exec
+INSERT INTO outfits (id, name, w_id) VALUES ($1, $2, $3)
+ON CONFLICT (id) DO UPDATE SET name=$2 WHERE outfits.w_id=$3
+`
+
+type PutOutfitParams struct {
+	ID   string
+	Name string
+	WID  string
+}
+
+func (q *Queries) PutOutfit(ctx context.Context, arg PutOutfitParams) error {
+	_, err := q.db.ExecContext(ctx, putOutfit, arg.ID, arg.Name, arg.WID)
+	return err
+}
+
+const putOutfitItemUsage = `-- name: PutOutfitItemUsage :exec
+UPDATE activity SET note=$1, ts=$2 WHERE oa_id=$3 AND w_id=$4
+`
+
+type PutOutfitItemUsageParams struct {
+	Note sql.NullString
+	Ts   time.Time
+	OaID sql.NullString
+	WID  string
+}
+
+func (q *Queries) PutOutfitItemUsage(ctx context.Context, arg PutOutfitItemUsageParams) error {
+	_, err := q.db.ExecContext(ctx, putOutfitItemUsage,
+		arg.Note,
+		arg.Ts,
+		arg.OaID,
+		arg.WID,
+	)
+	return err
+}
+
+const putOutfitUsage = `-- name: PutOutfitUsage :exec
+UPDATE outfit_activity SET note=$1, ts=$2 WHERE id=$3 AND o_id IN (SELECT id FROM outfits WHERE w_id=$4)
+`
+
+type PutOutfitUsageParams struct {
+	Note sql.NullString
+	Ts   time.Time
+	ID   string
+	WID  string
+}
+
+func (q *Queries) PutOutfitUsage(ctx context.Context, arg PutOutfitUsageParams) error {
+	_, err := q.db.ExecContext(ctx, putOutfitUsage,
+		arg.Note,
+		arg.Ts,
+		arg.ID,
+		arg.WID,
+	)
+	return err
+}
+
+const putPlan = `-- name: PutPlan :exec
+INSERT INTO planned_usage (id, w_id, day, c_id, o_id, note) VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type PutPlanParams struct {
+	ID   string
+	WID  string
+	Day  time.Time
+	CID  sql.NullString
+	OID  sql.NullString
+	Note sql.NullString
+}
+
+func (q *Queries) PutPlan(ctx context.Context, arg PutPlanParams) error {
+	_, err := q.db.ExecContext(ctx, putPlan,
+		arg.ID,
+		arg.WID,
+		arg.Day,
+		arg.CID,
+		arg.OID,
+		arg.Note,
+	)
+	return err
+}
+
+const putRate = `-- name: PutRate :exec
+INSERT INTO exchange_rates (w_id, currency, rate, updated) VALUES ($1, $2, $3, $4)
+ON CONFLICT (w_id, currency) DO UPDATE SET rate=$3, updated=$4
+`
+
+type PutRateParams struct {
+	WID      string
+	Currency string
+	Rate     float64
+	Updated  time.Time
+}
+
+func (q *Queries) PutRate(ctx context.Context, arg PutRateParams) error {
+	_, err := q.db.ExecContext(ctx, putRate,
+		arg.WID,
+		arg.Currency,
+		arg.Rate,
+		arg.Updated,
+	)
+	return err
+}
+
+const putUsage = `-- name: PutUsage :execresult
+UPDATE activity SET note=$1, ts=$2 WHERE id=$3 AND w_id=$4
+`
+
+type PutUsageParams struct {
+	Note sql.NullString
+	Ts   time.Time
+	ID   string
+	WID  string
+}
+
+func (q *Queries) PutUsage(ctx context.Context, arg PutUsageParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, putUsage,
+		arg.Note,
+		arg.Ts,
+		arg.ID,
+		arg.WID,
+	)
+}
+
+const putWish = `-- name: PutWish :exec
+INSERT INTO wishlist (id, w_id, title, category, brand, target_price, link, priority, notes, added)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (id) DO UPDATE
+SET title=$3, category=$4, brand=$5, target_price=$6, link=$7, priority=$8, notes=$9
+WHERE wishlist.w_id=$2
+`
+
+type PutWishParams struct {
+	ID          string
+	WID         string
+	Title       string
+	Category    sql.NullString
+	Brand       sql.NullString
+	TargetPrice sql.NullFloat64
+	Link        sql.NullString
+	Priority    int32
+	Notes       sql.NullString
+	Added       time.Time
+}
+
+func (q *Queries) PutWish(ctx context.Context, arg PutWishParams) error {
+	_, err := q.db.ExecContext(ctx, putWish,
+		arg.ID,
+		arg.WID,
+		arg.Title,
+		arg.Category,
+		arg.Brand,
+		arg.TargetPrice,
+		arg.Link,
+		arg.Priority,
+		arg.Notes,
+		arg.Added,
+	)
+	return err
+}
+
+const refreshOutfitLastUsed = `-- name: RefreshOutfitLastUsed :exec
+UPDATE outfits SET last_activity=(SELECT MAX(ts) FROM outfit_activity WHERE o_id=$1) WHERE id=$1 AND w_id=$2
+`
+
+type RefreshOutfitLastUsedParams struct {
+	OID string
+	WID string
+}
+
+func (q *Queries) RefreshOutfitLastUsed(ctx context.Context, arg RefreshOutfitLastUsedParams) error {
+	_, err := q.db.ExecContext(ctx, refreshOutfitLastUsed, arg.OID, arg.WID)
+	return err
+}
+
+const removeWardrobeMember = `-- name: RemoveWardrobeMember :exec
+DELETE FROM wardrobe_members WHERE w_id=$1 AND u_id=$2
+`
+
+type RemoveWardrobeMemberParams struct {
+	WID string
+	UID string
+}
+
+func (q *Queries) RemoveWardrobeMember(ctx context.Context, arg RemoveWardrobeMemberParams) error {
+	_, err := q.db.ExecContext(ctx, removeWardrobeMember, arg.WID, arg.UID)
+	return err
+}
+
+const renameItemCategory = `-- name: RenameItemCategory :exec
+UPDATE catalog SET category=$1 WHERE w_id=$2 AND TRIM(category)=$3
 `
 
-func (q *Queries) GetCatalog(ctx context.Context, id string) (Catalog, error) {
-	row := q.db.QueryRowContext(ctx, getCatalog, id)
-	var i Catalog
-	err := row.Scan(
-		&i.ID,
-		&i.Category,
-		&i.Brand,
-		&i.Color,
-		&i.Pattern,
-		&i.Title,
-		&i.Description,
-		&i.Price,
-		&i.LastActivity,
-		&i.LastNote,
-		&i.Hidden,
-	)
-	return i, err
+type RenameItemCategoryParams struct {
+	NewName sql.NullString
+	WID     string
+	OldName string
 }
 
-const getLastUsage = `-- name: GetLastUsage :one
-SELECT id, c_id, ts, note FROM ACTIVITY WHERE c_id=$1 ORDER BY ts DESC LIMIT 1
+func (q *Queries) RenameItemCategory(ctx context.Context, arg RenameItemCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, renameItemCategory, arg.NewName, arg.WID, arg.OldName)
+	return err
+}
+
+const renameWardrobe = `-- name: RenameWardrobe :exec
+UPDATE wardrobes SET name=$1 WHERE id=$2
 `
 
-func (q *Queries) GetLastUsage(ctx context.Context, cID string) (Activity, error) {
-	row := q.db.QueryRowContext(ctx, getLastUsage, cID)
-	var i Activity
-	err := row.Scan(
-		&i.ID,
-		&i.CID,
-		&i.Ts,
-		&i.Note,
+type RenameWardrobeParams struct {
+	Name string
+	ID   string
+}
+
+func (q *Queries) RenameWardrobe(ctx context.Context, arg RenameWardrobeParams) error {
+	_, err := q.db.ExecContext(ctx, renameWardrobe, arg.Name, arg.ID)
+	return err
+}
+
+const restoreItem = `-- name: RestoreItem :exec
+INSERT INTO catalog (id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden, w_id,
+	size, material, seasons, purchased, retailer, condition, skip_suggest, min_temp, max_temp, rain_ok, state, wears_since_clean,
+	disposed, lifecycle, resale_price, lifecycle_note, currency)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29)
+`
+
+type RestoreItemParams struct {
+	ID              string
+	Category        sql.NullString
+	Brand           sql.NullString
+	Color           sql.NullString
+	Pattern         sql.NullString
+	Title           sql.NullString
+	Description     sql.NullString
+	Price           sql.NullFloat64
+	LastActivity    sql.NullTime
+	LastNote        sql.NullString
+	Hidden          bool
+	WID             string
+	Size            sql.NullString
+	Material        sql.NullString
+	Seasons         sql.NullString
+	Purchased       sql.NullTime
+	Retailer        sql.NullString
+	Condition       sql.NullString
+	SkipSuggest     bool
+	MinTemp         sql.NullFloat64
+	MaxTemp         sql.NullFloat64
+	RainOk          sql.NullBool
+	State           string
+	WearsSinceClean int32
+	Disposed        sql.NullTime
+	Lifecycle       string
+	ResalePrice     sql.NullFloat64
+	LifecycleNote   sql.NullString
+	Currency        string
+}
+
+func (q *Queries) RestoreItem(ctx context.Context, arg RestoreItemParams) error {
+	_, err := q.db.ExecContext(ctx, restoreItem,
+		arg.ID,
+		arg.Category,
+		arg.Brand,
+		arg.Color,
+		arg.Pattern,
+		arg.Title,
+		arg.Description,
+		arg.Price,
+		arg.LastActivity,
+		arg.LastNote,
+		arg.Hidden,
+		arg.WID,
+		arg.Size,
+		arg.Material,
+		arg.Seasons,
+		arg.Purchased,
+		arg.Retailer,
+		arg.Condition,
+		arg.SkipSuggest,
+		arg.MinTemp,
+		arg.MaxTemp,
+		arg.RainOk,
+		arg.State,
+		arg.WearsSinceClean,
+		arg.Disposed,
+		arg.Lifecycle,
+		arg.ResalePrice,
+		arg.LifecycleNote,
+		arg.Currency,
 	)
-	return i, err
+	return err
 }
 
-const getUsage = `-- name: GetUsage :one
-SELECT id, c_id, ts, note FROM ACTIVITY WHERE id=$1
+const restoreLoan = `-- name: RestoreLoan :exec
+INSERT INTO loans (id, c_id, w_id, borrower, lent, due, returned) VALUES ($1, $2, $3, $4, $5, $6, $7)
 `
 
-func (q *Queries) GetUsage(ctx context.Context, id string) (Activity, error) {
-	row := q.db.QueryRowContext(ctx, getUsage, id)
-	var i Activity
-	err := row.Scan(
-		&i.ID,
-		&i.CID,
-		&i.Ts,
-		&i.Note,
+type RestoreLoanParams struct {
+	ID       string
+	CID      string
+	WID      string
+	Borrower string
+	Lent     time.Time
+	Due      sql.NullTime
+	Returned sql.NullTime
+}
+
+func (q *Queries) RestoreLoan(ctx context.Context, arg RestoreLoanParams) error {
+	_, err := q.db.ExecContext(ctx, restoreLoan,
+		arg.ID,
+		arg.CID,
+		arg.WID,
+		arg.Borrower,
+		arg.Lent,
+		arg.Due,
+		arg.Returned,
 	)
-	return i, err
+	return err
 }
 
-const listCatalog = `-- name: ListCatalog :many
-SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden FROM CATALOG ORDER BY hidden ASC, last_activity DESC NULLS LAST
+const restoreOutfit = `-- name: RestoreOutfit :exec
+INSERT INTO outfits (id, name, last_activity, w_id) VALUES ($1, $2, $3, $4)
 `
 
-func (q *Queries) ListCatalog(ctx context.Context) ([]Catalog, error) {
-	rows, err := q.db.QueryContext(ctx, listCatalog)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Catalog
-	for rows.Next() {
-		var i Catalog
-		if err := rows.Scan(
-			&i.ID,
-			&i.Category,
-			&i.Brand,
-			&i.Color,
-			&i.Pattern,
-			&i.Title,
-			&i.Description,
-			&i.Price,
-			&i.LastActivity,
-			&i.LastNote,
-			&i.Hidden,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type RestoreOutfitParams struct {
+	ID           string
+	Name         string
+	LastActivity sql.NullTime
+	WID          string
 }
 
-const listUsage = `-- name: ListUsage :many
-SELECT id, c_id, ts, note FROM ACTIVITY ORDER BY ts DESC
+func (q *Queries) RestoreOutfit(ctx context.Context, arg RestoreOutfitParams) error {
+	_, err := q.db.ExecContext(ctx, restoreOutfit,
+		arg.ID,
+		arg.Name,
+		arg.LastActivity,
+		arg.WID,
+	)
+	return err
+}
+
+const restoreOutfitUsage = `-- name: RestoreOutfitUsage :exec
+INSERT INTO outfit_activity (id, o_id, ts, note) VALUES ($1, $2, $3, $4)
 `
 
-func (q *Queries) ListUsage(ctx context.Context) ([]Activity, error) {
-	rows, err := q.db.QueryContext(ctx, listUsage)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Activity
-	for rows.Next() {
-		var i Activity
-		if err := rows.Scan(
-			&i.ID,
-			&i.CID,
-			&i.Ts,
-			&i.Note,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type RestoreOutfitUsageParams struct {
+	ID   string
+	OID  string
+	Ts   time.Time
+	Note sql.NullString
 }
 
-const logUsage = `-- name: LogUsage :execresult
-INSERT INTO ACTIVITY(id, c_id, ts) values ($1, $2, $3)
+func (q *Queries) RestoreOutfitUsage(ctx context.Context, arg RestoreOutfitUsageParams) error {
+	_, err := q.db.ExecContext(ctx, restoreOutfitUsage,
+		arg.ID,
+		arg.OID,
+		arg.Ts,
+		arg.Note,
+	)
+	return err
+}
+
+const restoreUsage = `-- name: RestoreUsage :exec
+INSERT INTO activity (id, c_id, ts, note, oa_id, w_id) VALUES ($1, $2, $3, $4, $5, $6)
 `
 
-type LogUsageParams struct {
-	ID  string
-	CID string
-	Ts  time.Time
+type RestoreUsageParams struct {
+	ID   string
+	CID  string
+	Ts   time.Time
+	Note sql.NullString
+	OaID sql.NullString
+	WID  string
 }
 
-func (q *Queries) LogUsage(ctx context.Context, arg LogUsageParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, logUsage, arg.ID, arg.CID, arg.Ts)
+func (q *Queries) RestoreUsage(ctx context.Context, arg RestoreUsageParams) error {
+	_, err := q.db.ExecContext(ctx, restoreUsage,
+		arg.ID,
+		arg.CID,
+		arg.Ts,
+		arg.Note,
+		arg.OaID,
+		arg.WID,
+	)
+	return err
 }
 
-const putItem = `-- name: PutItem :execresult
-INSERT INTO catalog
-(id, category, brand, color, pattern, title, description, price)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (id) DO UPDATE
-SET category=$2, brand=$3, color=$4, pattern=$5, title=$6, description=$7, price=$8
+const restoreWish = `-- name: RestoreWish :exec
+INSERT INTO wishlist (id, w_id, title, category, brand, target_price, link, priority, notes, added, bought, c_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 `
 
-type PutItemParams struct {
+type RestoreWishParams struct {
 	ID          string
+	WID         string
+	Title       string
 	Category    sql.NullString
 	Brand       sql.NullString
-	Color       sql.NullString
-	Pattern     sql.NullString
-	Title       sql.NullString
-	Description sql.NullString
-	Price       sql.NullFloat64
+	TargetPrice sql.NullFloat64
+	Link        sql.NullString
+	Priority    int32
+	Notes       sql.NullString
+	Added       time.Time
+	Bought      sql.NullTime
+	CID         sql.NullString
 }
 
-func (q *Queries) PutItem(ctx context.Context, arg PutItemParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, putItem,
+func (q *Queries) RestoreWish(ctx context.Context, arg RestoreWishParams) error {
+	_, err := q.db.ExecContext(ctx, restoreWish,
 		arg.ID,
+		arg.WID,
+		arg.Title,
 		arg.Category,
 		arg.Brand,
-		arg.Color,
-		arg.Pattern,
-		arg.Title,
-		arg.Description,
-		arg.Price,
+		arg.TargetPrice,
+		arg.Link,
+		arg.Priority,
+		arg.Notes,
+		arg.Added,
+		arg.Bought,
+		arg.CID,
 	)
+	return err
 }
 
-const putUsage = `-- name: PutUsage :execresult
-UPDATE activity SET note=$1, ts=$2 WHERE id=$3
+const returnItemLoans = `-- name: ReturnItemLoans :exec
+UPDATE loans SET returned=$1 WHERE c_id=$2 AND w_id=$3 AND returned IS NULL
 `
 
-type PutUsageParams struct {
-	Note sql.NullString
-	Ts   time.Time
-	ID   string
+type ReturnItemLoansParams struct {
+	Returned sql.NullTime
+	CID      string
+	WID      string
 }
 
-func (q *Queries) PutUsage(ctx context.Context, arg PutUsageParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, putUsage, arg.Note, arg.Ts, arg.ID)
+func (q *Queries) ReturnItemLoans(ctx context.Context, arg ReturnItemLoansParams) error {
+	_, err := q.db.ExecContext(ctx, returnItemLoans, arg.Returned, arg.CID, arg.WID)
+	return err
 }
 
 const searchCatalog = `-- name: SearchCatalog :many
-SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden FROM CATALOG WHERE LOWER(title) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(description) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(color) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(category) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(brand) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(pattern) LIKE '%' || LOWER($1) || '%'
-	ORDER BY hidden ASC, last_activity DESC NULLS LAST
+SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden, w_id, size, material, seasons, purchased, retailer, condition, skip_suggest, min_temp, max_temp, rain_ok, state, wears_since_clean, disposed, lifecycle, resale_price, lifecycle_note, currency FROM CATALOG
+WHERE w_id=$1 AND ($2::text = '' OR (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(category, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(color, '') || ' ' || coalesce(pattern, '') || ' ' || coalesce(size, '') || ' ' || coalesce(material, '') || ' ' || coalesce(seasons, '') || ' ' || coalesce(retailer, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C')
+	) @@ to_tsquery('english', $2))
+	AND ($3::text IS NULL OR LOWER(brand) LIKE '%' || LOWER($3) || '%')
+	AND ($4::text IS NULL OR LOWER(color) LIKE '%' || LOWER($4) || '%')
+	AND ($5::text IS NULL OR LOWER(category) LIKE '%' || LOWER($5) || '%')
+	AND ($6::text IS NULL OR LOWER(pattern) LIKE '%' || LOWER($6) || '%')
+	AND ($7::text IS NULL OR LOWER(size) = LOWER($7))
+	AND ($8::text IS NULL OR LOWER(material) LIKE '%' || LOWER($8) || '%')
+	AND ($9::text IS NULL OR LOWER(seasons) LIKE '%' || LOWER($9) || '%')
+	AND ($10::text IS NULL OR LOWER(retailer) LIKE '%' || LOWER($10) || '%')
+	AND ($11::text IS NULL OR LOWER(condition) = LOWER($11))
+	AND ($12::boolean IS NULL OR hidden = $12)
+	AND ($13::text IS NULL OR state = LOWER($13))
+	AND ($14::boolean IS NULL OR (state = 'clean') = $14)
+	AND ($15::text IS NULL OR lifecycle = LOWER($15))
+	AND ($16::text IS NULL OR NOT EXISTS (
+		SELECT 1 FROM unnest(string_to_array($16, ',')) AS wanted(name)
+		WHERE wanted.name NOT IN (SELECT tags.name FROM catalog_tags JOIN tags ON tags.id = catalog_tags.t_id WHERE catalog_tags.c_id = catalog.id)))
+	ORDER BY hidden ASC,
+	ts_rank(
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(category, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(color, '') || ' ' || coalesce(pattern, '') || ' ' || coalesce(size, '') || ' ' || coalesce(material, '') || ' ' || coalesce(seasons, '') || ' ' || coalesce(retailer, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C'),
+		to_tsquery('english', $2)) DESC,
+	last_activity DESC NULLS LAST
 `
 
-func (q *Queries) SearchCatalog(ctx context.Context, lower string) ([]Catalog, error) {
-	rows, err := q.db.QueryContext(ctx, searchCatalog, lower)
+type SearchCatalogParams struct {
+	WID       string
+	Query     string
+	Brand     sql.NullString
+	Color     sql.NullString
+	Category  sql.NullString
+	Pattern   sql.NullString
+	Size      sql.NullString
+	Material  sql.NullString
+	Season    sql.NullString
+	Retailer  sql.NullString
+	Condition sql.NullString
+	Hidden    sql.NullBool
+	State     sql.NullString
+	Available sql.NullBool
+	Lifecycle sql.NullString
+	Tags      sql.NullString
+}
+
+func (q *Queries) SearchCatalog(ctx context.Context, arg SearchCatalogParams) ([]Catalog, error) {
+	rows, err := q.db.QueryContext(ctx, searchCatalog,
+		arg.WID,
+		arg.Query,
+		arg.Brand,
+		arg.Color,
+		arg.Category,
+		arg.Pattern,
+		arg.Size,
+		arg.Material,
+		arg.Season,
+		arg.Retailer,
+		arg.Condition,
+		arg.Hidden,
+		arg.State,
+		arg.Available,
+		arg.Lifecycle,
+		arg.Tags,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -258,6 +2854,24 @@ func (q *Queries) SearchCatalog(ctx context.Context, lower string) ([]Catalog, e
 			&i.LastActivity,
 			&i.LastNote,
 			&i.Hidden,
+			&i.WID,
+			&i.Size,
+			&i.Material,
+			&i.Seasons,
+			&i.Purchased,
+			&i.Retailer,
+			&i.Condition,
+			&i.SkipSuggest,
+			&i.MinTemp,
+			&i.MaxTemp,
+			&i.RainOk,
+			&i.State,
+			&i.WearsSinceClean,
+			&i.Disposed,
+			&i.Lifecycle,
+			&i.ResalePrice,
+			&i.LifecycleNote,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -272,55 +2886,229 @@ func (q *Queries) SearchCatalog(ctx context.Context, lower string) ([]Catalog, e
 	return items, nil
 }
 
-const setHidden = `-- name: SetHidden :exec
-UPDATE catalog SET hidden=$1 WHERE id=$2
+const setItemState = `-- name: SetItemState :exec
+UPDATE catalog SET state=$1, wears_since_clean=$2 WHERE id=$3 AND w_id=$4
 `
 
-type SetHiddenParams struct {
-	Hidden bool
-	ID     string
+type SetItemStateParams struct {
+	State           string
+	WearsSinceClean int32
+	ID              string
+	WID             string
+}
+
+func (q *Queries) SetItemState(ctx context.Context, arg SetItemStateParams) error {
+	_, err := q.db.ExecContext(ctx, setItemState,
+		arg.State,
+		arg.WearsSinceClean,
+		arg.ID,
+		arg.WID,
+	)
+	return err
+}
+
+const setLifecycle = `-- name: SetLifecycle :exec
+UPDATE catalog SET hidden=$1, lifecycle=$2, disposed=$3, resale_price=$4, lifecycle_note=$5 WHERE id=$6 AND w_id=$7
+`
+
+type SetLifecycleParams struct {
+	Hidden        bool
+	Lifecycle     string
+	Disposed      sql.NullTime
+	ResalePrice   sql.NullFloat64
+	LifecycleNote sql.NullString
+	ID            string
+	WID           string
+}
+
+func (q *Queries) SetLifecycle(ctx context.Context, arg SetLifecycleParams) error {
+	_, err := q.db.ExecContext(ctx, setLifecycle,
+		arg.Hidden,
+		arg.Lifecycle,
+		arg.Disposed,
+		arg.ResalePrice,
+		arg.LifecycleNote,
+		arg.ID,
+		arg.WID,
+	)
+	return err
 }
 
-func (q *Queries) SetHidden(ctx context.Context, arg SetHiddenParams) error {
-	_, err := q.db.ExecContext(ctx, setHidden, arg.Hidden, arg.ID)
+const setPassword = `-- name: SetPassword :exec
+UPDATE users SET password_hash=$1 WHERE id=$2
+`
+
+type SetPasswordParams struct {
+	PasswordHash string
+	ID           string
+}
+
+func (q *Queries) SetPassword(ctx context.Context, arg SetPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setPassword, arg.PasswordHash, arg.ID)
+	return err
+}
+
+const setSessionWardrobe = `-- name: SetSessionWardrobe :exec
+UPDATE sessions SET w_id=$1 WHERE id=$2
+`
+
+type SetSessionWardrobeParams struct {
+	WID string
+	ID  string
+}
+
+func (q *Queries) SetSessionWardrobe(ctx context.Context, arg SetSessionWardrobeParams) error {
+	_, err := q.db.ExecContext(ctx, setSessionWardrobe, arg.WID, arg.ID)
+	return err
+}
+
+const setSkipSuggest = `-- name: SetSkipSuggest :exec
+UPDATE catalog SET skip_suggest=$1 WHERE id=$2 AND w_id=$3
+`
+
+type SetSkipSuggestParams struct {
+	SkipSuggest bool
+	ID          string
+	WID         string
+}
+
+func (q *Queries) SetSkipSuggest(ctx context.Context, arg SetSkipSuggestParams) error {
+	_, err := q.db.ExecContext(ctx, setSkipSuggest, arg.SkipSuggest, arg.ID, arg.WID)
 	return err
 }
 
 const setUsageNote = `-- name: SetUsageNote :execresult
-UPDATE activity SET note=$1 WHERE id=$2
+UPDATE activity SET note=$1 WHERE id=$2 AND w_id=$3
 `
 
 type SetUsageNoteParams struct {
 	Note sql.NullString
 	ID   string
+	WID  string
 }
 
 func (q *Queries) SetUsageNote(ctx context.Context, arg SetUsageNoteParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, setUsageNote, arg.Note, arg.ID)
+	return q.db.ExecContext(ctx, setUsageNote, arg.Note, arg.ID, arg.WID)
+}
+
+const setWardrobeCurrency = `-- name: SetWardrobeCurrency :exec
+UPDATE wardrobes SET currency=$1 WHERE id=$2
+`
+
+type SetWardrobeCurrencyParams struct {
+	Currency string
+	ID       string
+}
+
+func (q *Queries) SetWardrobeCurrency(ctx context.Context, arg SetWardrobeCurrencyParams) error {
+	_, err := q.db.ExecContext(ctx, setWardrobeCurrency, arg.Currency, arg.ID)
+	return err
+}
+
+const updateCategory = `-- name: UpdateCategory :execresult
+UPDATE categories SET name=$1, icon=$2, sort_order=$3,
+	wears_before_wash=$4, monthly_budget=$5, yearly_budget=$6,
+	slot=$7
+WHERE w_id=$8 AND name=$9
+`
+
+type UpdateCategoryParams struct {
+	Name            string
+	Icon            string
+	SortOrder       int32
+	WearsBeforeWash sql.NullInt32
+	MonthlyBudget   sql.NullFloat64
+	YearlyBudget    sql.NullFloat64
+	Slot            string
+	WID             string
+	OldName         string
+}
+
+func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateCategory,
+		arg.Name,
+		arg.Icon,
+		arg.SortOrder,
+		arg.WearsBeforeWash,
+		arg.MonthlyBudget,
+		arg.YearlyBudget,
+		arg.Slot,
+		arg.WID,
+		arg.OldName,
+	)
 }
 
 const updateLastNote = `-- name: UpdateLastNote :execresult
-UPDATE catalog SET last_note=$1 WHERE id=$2
+UPDATE catalog SET last_note=$1 WHERE id=$2 AND w_id=$3
 `
 
 type UpdateLastNoteParams struct {
 	LastNote sql.NullString
 	ID       string
+	WID      string
 }
 
 func (q *Queries) UpdateLastNote(ctx context.Context, arg UpdateLastNoteParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, updateLastNote, arg.LastNote, arg.ID)
+	return q.db.ExecContext(ctx, updateLastNote, arg.LastNote, arg.ID, arg.WID)
 }
 
 const updateLastUsed = `-- name: UpdateLastUsed :execresult
-UPDATE catalog SET last_activity=$1, last_note=NULL WHERE id=$2
+UPDATE catalog SET last_activity=$1, last_note=NULL WHERE id=$2 AND w_id=$3
 `
 
 type UpdateLastUsedParams struct {
 	LastActivity sql.NullTime
 	ID           string
+	WID          string
 }
 
 func (q *Queries) UpdateLastUsed(ctx context.Context, arg UpdateLastUsedParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, updateLastUsed, arg.LastActivity, arg.ID)
+	return q.db.ExecContext(ctx, updateLastUsed, arg.LastActivity, arg.ID, arg.WID)
+}
+
+const usageIDTaken = `-- name: UsageIDTaken :one
+SELECT COUNT(*) > 0 FROM activity WHERE id=$1
+`
+
+func (q *Queries) UsageIDTaken(ctx context.Context, id string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, usageIDTaken, id)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const wearsByMonth = `-- name: WearsByMonth :many
+SELECT SUBSTR(CAST(ts AS TEXT), 1, 7) AS month, COUNT(*) AS wears
+FROM activity WHERE w_id=$1 GROUP BY month ORDER BY month ASC
+`
+
+type WearsByMonthRow struct {
+	Month string
+	Wears int64
+}
+
+func (q *Queries) WearsByMonth(ctx context.Context, wID string) ([]WearsByMonthRow, error) {
+	rows, err := q.db.QueryContext(ctx, wearsByMonth, wID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WearsByMonthRow
+	for rows.Next() {
+		var i WearsByMonthRow
+		if err := rows.Scan(
+			&i.Month,
+			&i.Wears,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
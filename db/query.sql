@@ -1,52 +1,457 @@
 -- name: PutItem :execresult
 INSERT INTO catalog
-(id, category, brand, color, pattern, title, description, price)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+(id, category, brand, color, pattern, title, description, price, w_id, size, material, seasons, purchased, retailer, condition,
+	min_temp, max_temp, rain_ok, currency)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 ON CONFLICT (id) DO UPDATE
-SET category=$2, brand=$3, color=$4, pattern=$5, title=$6, description=$7, price=$8;
+SET category=$2, brand=$3, color=$4, pattern=$5, title=$6, description=$7, price=$8,
+	size=$10, material=$11, seasons=$12, purchased=$13, retailer=$14, condition=$15,
+	min_temp=$16, max_temp=$17, rain_ok=$18, currency=$19
+WHERE catalog.w_id=$9;
 
 -- name: ListCatalog :many
-SELECT * FROM CATALOG ORDER BY hidden ASC, last_activity DESC NULLS LAST;
+SELECT * FROM CATALOG WHERE w_id=$1 ORDER BY hidden ASC, last_activity DESC NULLS LAST;
 
 -- name: GetCatalog :one
-SELECT * FROM CATALOG WHERE id=$1;
+SELECT * FROM CATALOG WHERE id=$1 AND w_id=$2;
+
+-- name: CatalogIDTaken :one
+SELECT COUNT(*) > 0 FROM catalog WHERE id=$1;
 
 -- name: SearchCatalog :many
-SELECT * FROM CATALOG WHERE LOWER(title) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(description) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(color) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(category) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(brand) LIKE '%' || LOWER($1) || '%'
-	OR LOWER(pattern) LIKE '%' || LOWER($1) || '%'
-	ORDER BY hidden ASC, last_activity DESC NULLS LAST
+SELECT * FROM CATALOG
+WHERE w_id=sqlc.arg(w_id) AND (sqlc.arg(query)::text = '' OR (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(category, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(color, '') || ' ' || coalesce(pattern, '') || ' ' || coalesce(size, '') || ' ' || coalesce(material, '') || ' ' || coalesce(seasons, '') || ' ' || coalesce(retailer, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C')
+	) @@ to_tsquery('english', sqlc.arg(query)))
+	AND (sqlc.narg(brand)::text IS NULL OR LOWER(brand) LIKE '%' || LOWER(sqlc.narg(brand)) || '%')
+	AND (sqlc.narg(color)::text IS NULL OR LOWER(color) LIKE '%' || LOWER(sqlc.narg(color)) || '%')
+	AND (sqlc.narg(category)::text IS NULL OR LOWER(category) LIKE '%' || LOWER(sqlc.narg(category)) || '%')
+	AND (sqlc.narg(pattern)::text IS NULL OR LOWER(pattern) LIKE '%' || LOWER(sqlc.narg(pattern)) || '%')
+	AND (sqlc.narg(size)::text IS NULL OR LOWER(size) = LOWER(sqlc.narg(size)))
+	AND (sqlc.narg(material)::text IS NULL OR LOWER(material) LIKE '%' || LOWER(sqlc.narg(material)) || '%')
+	AND (sqlc.narg(season)::text IS NULL OR LOWER(seasons) LIKE '%' || LOWER(sqlc.narg(season)) || '%')
+	AND (sqlc.narg(retailer)::text IS NULL OR LOWER(retailer) LIKE '%' || LOWER(sqlc.narg(retailer)) || '%')
+	AND (sqlc.narg(condition)::text IS NULL OR LOWER(condition) = LOWER(sqlc.narg(condition)))
+	AND (sqlc.narg(hidden)::boolean IS NULL OR hidden = sqlc.narg(hidden))
+	AND (sqlc.narg(state)::text IS NULL OR state = LOWER(sqlc.narg(state)))
+	AND (sqlc.narg(available)::boolean IS NULL OR (state = 'clean') = sqlc.narg(available))
+	AND (sqlc.narg(lifecycle)::text IS NULL OR lifecycle = LOWER(sqlc.narg(lifecycle)))
+	AND (sqlc.narg(tags)::text IS NULL OR NOT EXISTS (
+		SELECT 1 FROM unnest(string_to_array(sqlc.narg(tags), ',')) AS wanted(name)
+		WHERE wanted.name NOT IN (SELECT tags.name FROM catalog_tags JOIN tags ON tags.id = catalog_tags.t_id WHERE catalog_tags.c_id = catalog.id)))
+	ORDER BY hidden ASC,
+	ts_rank(
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(category, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(color, '') || ' ' || coalesce(pattern, '') || ' ' || coalesce(size, '') || ' ' || coalesce(material, '') || ' ' || coalesce(seasons, '') || ' ' || coalesce(retailer, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C'),
+		to_tsquery('english', sqlc.arg(query))) DESC,
+	last_activity DESC NULLS LAST
 	;
 
--- name: SetHidden :exec
-UPDATE catalog SET hidden=$1 WHERE id=$2;
+-- name: SetLifecycle :exec
+UPDATE catalog SET hidden=$1, lifecycle=$2, disposed=$3, resale_price=$4, lifecycle_note=$5 WHERE id=$6 AND w_id=$7;
+
+-- name: SetSkipSuggest :exec
+UPDATE catalog SET skip_suggest=$1 WHERE id=$2 AND w_id=$3;
 
 -- name: GetLastUsage :one
-SELECT * FROM ACTIVITY WHERE c_id=$1 ORDER BY ts DESC LIMIT 1;
+SELECT * FROM ACTIVITY WHERE c_id=$1 AND w_id=$2 ORDER BY ts DESC LIMIT 1;
 
 -- name: GetAllUsage :many
-SELECT * FROM ACTIVITY WHERE c_id=$1 ORDER BY ts DESC;
+SELECT * FROM ACTIVITY WHERE c_id=$1 AND w_id=$2 ORDER BY ts DESC;
 
 -- name: GetUsage :one
-SELECT * FROM ACTIVITY WHERE id=$1;
+SELECT * FROM ACTIVITY WHERE id=$1 AND w_id=$2;
+
+-- name: UsageIDTaken :one
+SELECT COUNT(*) > 0 FROM activity WHERE id=$1;
 
 -- name: LogUsage :execresult
-INSERT INTO ACTIVITY(id, c_id, ts) values ($1, $2, $3);
+INSERT INTO ACTIVITY(id, c_id, ts, w_id) values ($1, $2, $3, $4);
 
 -- name: SetUsageNote :execresult
-UPDATE activity SET note=$1 WHERE id=$2;
+UPDATE activity SET note=$1 WHERE id=$2 AND w_id=$3;
 
 -- name: PutUsage :execresult
-UPDATE activity SET note=$1, ts=$2 WHERE id=$3;
+UPDATE activity SET note=$1, ts=$2 WHERE id=$3 AND w_id=$4;
 
 -- name: UpdateLastUsed :execresult
-UPDATE catalog SET last_activity=$1, last_note=NULL WHERE id=$2;
+UPDATE catalog SET last_activity=$1, last_note=NULL WHERE id=$2 AND w_id=$3;
 
 -- name: UpdateLastNote :execresult
-UPDATE catalog SET last_note=$1 WHERE id=$2;
+UPDATE catalog SET last_note=$1 WHERE id=$2 AND w_id=$3;
 
 -- name: ListUsage :many
-SELECT * FROM ACTIVITY ORDER BY ts DESC;
+SELECT * FROM ACTIVITY WHERE w_id=$1 ORDER BY ts DESC;
+
+-- name: DeleteItem :exec
+DELETE FROM catalog WHERE id=$1 AND w_id=$2;
+
+-- name: DeleteItemUsage :exec
+DELETE FROM activity WHERE c_id=$1 AND w_id=$2;
+
+-- name: DeleteUsage :exec
+DELETE FROM activity WHERE id=$1 AND w_id=$2;
+
+-- name: AddPhoto :exec
+INSERT INTO item_photos(id, c_id, content_type, ts) VALUES ($1, $2, $3, $4);
+
+-- name: GetPhoto :one
+SELECT * FROM item_photos WHERE id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2);
+
+-- name: ListPhotos :many
+SELECT * FROM item_photos WHERE c_id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2) ORDER BY ts ASC;
+
+-- name: ListAllPhotos :many
+SELECT * FROM item_photos WHERE c_id IN (SELECT id FROM catalog WHERE w_id=$1) ORDER BY ts ASC;
+
+-- name: DeletePhoto :exec
+DELETE FROM item_photos WHERE id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2);
+
+-- name: DeleteItemPhotos :exec
+DELETE FROM item_photos WHERE c_id=$1 AND c_id IN (SELECT id FROM catalog WHERE w_id=$2);
+
+-- name: PutOutfit :exec
+INSERT INTO outfits (id, name, w_id) VALUES ($1, $2, $3)
+ON CONFLICT (id) DO UPDATE SET name=$2 WHERE outfits.w_id=$3;
+
+-- name: GetOutfit :one
+SELECT * FROM outfits WHERE id=$1 AND w_id=$2;
+
+-- name: ListOutfits :many
+SELECT * FROM outfits WHERE w_id=$1 ORDER BY last_activity DESC NULLS LAST, name ASC;
+
+-- name: DeleteOutfit :exec
+DELETE FROM outfits WHERE id=$1 AND w_id=$2;
+
+-- name: AddOutfitItem :exec
+INSERT INTO outfit_items (o_id, c_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: ClearOutfitItems :exec
+DELETE FROM outfit_items WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2);
+
+-- name: DeleteItemOutfits :exec
+DELETE FROM outfit_items WHERE c_id IN (SELECT id FROM catalog WHERE id=$1 AND w_id=$2);
+
+-- name: ListAllOutfitItems :many
+SELECT * FROM outfit_items WHERE o_id IN (SELECT id FROM outfits WHERE w_id=$1);
+
+-- name: LogOutfitUsage :exec
+INSERT INTO outfit_activity (id, o_id, ts) VALUES ($1, $2, $3);
+
+-- name: LogOutfitItemUsage :exec
+INSERT INTO activity (id, c_id, ts, oa_id, w_id) VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetOutfitUsage :one
+SELECT * FROM outfit_activity WHERE id=$1 AND o_id IN (SELECT id FROM outfits WHERE w_id=$2);
+
+-- name: ListOutfitUsage :many
+SELECT * FROM outfit_activity WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2) ORDER BY ts DESC;
+
+-- name: PutOutfitUsage :exec
+UPDATE outfit_activity SET note=$1, ts=$2 WHERE id=$3 AND o_id IN (SELECT id FROM outfits WHERE w_id=$4);
+
+-- name: ListOutfitItemUsage :many
+SELECT * FROM activity WHERE oa_id=$1 AND w_id=$2;
+
+-- name: PutOutfitItemUsage :exec
+UPDATE activity SET note=$1, ts=$2 WHERE oa_id=$3 AND w_id=$4;
+
+-- name: DeleteOutfitItemUsage :exec
+DELETE FROM activity WHERE oa_id=$1 AND w_id=$2;
+
+-- name: DeleteOutfitUsage :exec
+DELETE FROM outfit_activity WHERE id=$1 AND o_id IN (SELECT id FROM outfits WHERE w_id=$2);
+
+-- name: DetachOutfitUsage :exec
+UPDATE activity SET oa_id=NULL WHERE w_id=$2 AND oa_id IN (SELECT id FROM outfit_activity WHERE o_id=$1);
+
+-- name: DeleteAllOutfitUsage :exec
+DELETE FROM outfit_activity WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2);
+
+-- name: RefreshOutfitLastUsed :exec
+UPDATE outfits SET last_activity=(SELECT MAX(ts) FROM outfit_activity WHERE o_id=$1) WHERE id=$1 AND w_id=$2;
+
+-- name: ListOutfitItems :many
+SELECT * FROM outfit_items WHERE o_id IN (SELECT id FROM outfits WHERE id=$1 AND w_id=$2);
+
+-- name: ItemStats :many
+SELECT catalog.id, catalog.title, catalog.category, catalog.brand, catalog.price, catalog.last_activity, catalog.hidden,
+	catalog.lifecycle, catalog.resale_price, catalog.currency, COUNT(activity.id) AS wears
+FROM catalog LEFT JOIN activity ON activity.c_id = catalog.id
+WHERE catalog.w_id=$1
+GROUP BY catalog.id
+ORDER BY wears DESC, catalog.last_activity DESC NULLS LAST;
+
+-- name: WearsByMonth :many
+SELECT SUBSTR(CAST(ts AS TEXT), 1, 7) AS month, COUNT(*) AS wears
+FROM activity WHERE w_id=$1 GROUP BY month ORDER BY month ASC;
+
+-- name: CreateUser :exec
+INSERT INTO users (id, username, password_hash, created) VALUES ($1, $2, $3, $4);
+
+-- name: GetUser :one
+SELECT * FROM users WHERE id=$1;
+
+-- name: GetUserByName :one
+SELECT * FROM users WHERE username=$1;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: SetPassword :exec
+UPDATE users SET password_hash=$1 WHERE id=$2;
+
+-- name: CreateSession :exec
+INSERT INTO sessions (id, u_id, created, expires, w_id) VALUES ($1, $2, $3, $4, $5);
+
+-- name: SetSessionWardrobe :exec
+UPDATE sessions SET w_id=$1 WHERE id=$2;
+
+-- name: GetSession :one
+SELECT * FROM sessions WHERE id=$1;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id=$1;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions WHERE u_id=$1;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires < $1;
+
+-- name: CreateWardrobe :exec
+INSERT INTO wardrobes (id, name, created) VALUES ($1, $2, $3);
+
+-- name: RenameWardrobe :exec
+UPDATE wardrobes SET name=$1 WHERE id=$2;
+
+-- name: SetWardrobeCurrency :exec
+UPDATE wardrobes SET currency=$1 WHERE id=$2;
+
+-- name: GetWardrobe :one
+SELECT * FROM wardrobes WHERE id=$1;
+
+-- name: ListUserWardrobes :many
+SELECT wardrobes.* FROM wardrobes JOIN wardrobe_members ON wardrobe_members.w_id = wardrobes.id
+WHERE wardrobe_members.u_id=$1
+ORDER BY wardrobes.created ASC;
+
+-- name: AddWardrobeMember :exec
+INSERT INTO wardrobe_members (w_id, u_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: RemoveWardrobeMember :exec
+DELETE FROM wardrobe_members WHERE w_id=$1 AND u_id=$2;
+
+-- name: IsWardrobeMember :one
+SELECT COUNT(*) > 0 FROM wardrobe_members WHERE w_id=$1 AND u_id=$2;
+
+-- name: ListWardrobeMembers :many
+SELECT users.id, users.username FROM users JOIN wardrobe_members ON wardrobe_members.u_id = users.id
+WHERE wardrobe_members.w_id=$1
+ORDER BY users.username ASC;
+
+-- name: ClaimOrphanWardrobes :exec
+INSERT INTO wardrobe_members (w_id, u_id)
+SELECT id, $1 FROM wardrobes WHERE id NOT IN (SELECT w_id FROM wardrobe_members);
+
+-- name: ListAllOutfitUsage :many
+SELECT * FROM outfit_activity WHERE o_id IN (SELECT id FROM outfits WHERE w_id=$1) ORDER BY ts ASC;
+
+-- name: RestoreItem :exec
+INSERT INTO catalog (id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden, w_id,
+	size, material, seasons, purchased, retailer, condition, skip_suggest, min_temp, max_temp, rain_ok, state, wears_since_clean,
+	disposed, lifecycle, resale_price, lifecycle_note, currency)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29);
+
+-- name: RestoreUsage :exec
+INSERT INTO activity (id, c_id, ts, note, oa_id, w_id) VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: RestoreOutfit :exec
+INSERT INTO outfits (id, name, last_activity, w_id) VALUES ($1, $2, $3, $4);
+
+-- name: RestoreOutfitUsage :exec
+INSERT INTO outfit_activity (id, o_id, ts, note) VALUES ($1, $2, $3, $4);
+
+-- name: CreateTag :exec
+INSERT INTO tags (id, name, w_id) VALUES ($1, $2, $3) ON CONFLICT (w_id, name) DO NOTHING;
+
+-- name: GetTagByName :one
+SELECT * FROM tags WHERE name=$1 AND w_id=$2;
+
+-- name: ListTags :many
+SELECT * FROM tags WHERE w_id=$1 ORDER BY name ASC;
+
+-- name: AddItemTag :exec
+INSERT INTO catalog_tags (c_id, t_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: ClearItemTags :exec
+DELETE FROM catalog_tags WHERE c_id IN (SELECT id FROM catalog WHERE id=$1 AND w_id=$2);
+
+-- name: ListItemTags :many
+SELECT tags.name FROM tags JOIN catalog_tags ON catalog_tags.t_id = tags.id
+WHERE catalog_tags.c_id=$1 AND tags.w_id=$2
+ORDER BY tags.name ASC;
+
+-- name: ListAllItemTags :many
+SELECT catalog_tags.c_id, tags.name FROM tags JOIN catalog_tags ON catalog_tags.t_id = tags.id
+WHERE tags.w_id=$1
+ORDER BY tags.name ASC;
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags WHERE w_id=$1 AND id NOT IN (SELECT t_id FROM catalog_tags);
+
+-- name: ListAllCatalogTags :many
+SELECT catalog_tags.* FROM catalog_tags JOIN tags ON tags.id = catalog_tags.t_id WHERE tags.w_id=$1;
+
+-- name: ListCategories :many
+SELECT * FROM categories WHERE w_id=$1 ORDER BY sort_order ASC, name ASC;
+
+-- name: CreateCategory :exec
+INSERT INTO categories (w_id, name, icon, sort_order, wears_before_wash, monthly_budget, yearly_budget, slot) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: UpdateCategory :execresult
+UPDATE categories SET name=sqlc.arg(name), icon=sqlc.arg(icon), sort_order=sqlc.arg(sort_order),
+	wears_before_wash=sqlc.arg(wears_before_wash), monthly_budget=sqlc.arg(monthly_budget), yearly_budget=sqlc.arg(yearly_budget),
+	slot=sqlc.arg(slot)
+WHERE w_id=sqlc.arg(w_id) AND name=sqlc.arg(old_name);
+
+-- name: DeleteCategory :exec
+DELETE FROM categories WHERE w_id=$1 AND name=$2;
+
+-- name: DeleteAllCategories :exec
+DELETE FROM categories WHERE w_id=$1;
+
+-- name: RenameItemCategory :exec
+UPDATE catalog SET category=sqlc.arg(new_name) WHERE w_id=sqlc.arg(w_id) AND TRIM(category)=sqlc.arg(old_name);
+
+-- name: CountCategoryItems :one
+SELECT COUNT(*) FROM catalog WHERE w_id=sqlc.arg(w_id) AND TRIM(category)=sqlc.arg(name);
+
+-- name: PutPlan :exec
+INSERT INTO planned_usage (id, w_id, day, c_id, o_id, note) VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetPlan :one
+SELECT * FROM planned_usage WHERE id=$1 AND w_id=$2;
+
+-- name: ListPlans :many
+SELECT * FROM planned_usage WHERE w_id=sqlc.arg(w_id) AND day >= sqlc.arg(from_day) AND day < sqlc.arg(to_day)
+ORDER BY day ASC;
+
+-- name: ListAllPlans :many
+SELECT * FROM planned_usage WHERE w_id=$1 ORDER BY day ASC;
+
+-- name: DeletePlan :exec
+DELETE FROM planned_usage WHERE id=$1 AND w_id=$2;
+
+-- name: DeleteItemPlans :exec
+DELETE FROM planned_usage WHERE c_id=$1 AND w_id=$2;
+
+-- name: DeleteOutfitPlans :exec
+DELETE FROM planned_usage WHERE o_id=$1 AND w_id=$2;
+
+-- name: GetCategory :one
+SELECT * FROM categories WHERE w_id=$1 AND name=$2;
+
+-- name: CountWear :exec
+UPDATE catalog SET wears_since_clean = wears_since_clean + 1 WHERE id=$1 AND w_id=$2;
+
+-- name: SetItemState :exec
+UPDATE catalog SET state=$1, wears_since_clean=$2 WHERE id=$3 AND w_id=$4;
+
+-- name: LogStateChange :exec
+INSERT INTO state_changes (id, c_id, w_id, ts, from_state, to_state) VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListStateChanges :many
+SELECT * FROM state_changes WHERE c_id=$1 AND w_id=$2 ORDER BY ts DESC;
+
+-- name: ListAllStateChanges :many
+SELECT * FROM state_changes WHERE w_id=$1 ORDER BY ts ASC;
+
+-- name: DeleteItemStateChanges :exec
+DELETE FROM state_changes WHERE c_id=$1 AND w_id=$2;
+
+-- name: LendItem :exec
+INSERT INTO loans (id, c_id, w_id, borrower, lent, due) VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: RestoreLoan :exec
+INSERT INTO loans (id, c_id, w_id, borrower, lent, due, returned) VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetLoan :one
+SELECT * FROM loans WHERE id=$1 AND w_id=$2;
+
+-- name: ReturnItemLoans :exec
+UPDATE loans SET returned=$1 WHERE c_id=$2 AND w_id=$3 AND returned IS NULL;
+
+-- name: ListOpenLoans :many
+SELECT * FROM loans WHERE w_id=$1 AND returned IS NULL ORDER BY lent ASC;
+
+-- name: ListLoans :many
+SELECT * FROM loans WHERE w_id=$1 ORDER BY lent DESC;
+
+-- name: ListItemLoans :many
+SELECT * FROM loans WHERE c_id=$1 AND w_id=$2 ORDER BY lent DESC;
+
+-- name: DeleteItemLoans :exec
+DELETE FROM loans WHERE c_id=$1 AND w_id=$2;
+
+-- name: PutWish :exec
+INSERT INTO wishlist (id, w_id, title, category, brand, target_price, link, priority, notes, added)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (id) DO UPDATE
+SET title=$3, category=$4, brand=$5, target_price=$6, link=$7, priority=$8, notes=$9
+WHERE wishlist.w_id=$2;
+
+-- name: RestoreWish :exec
+INSERT INTO wishlist (id, w_id, title, category, brand, target_price, link, priority, notes, added, bought, c_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetWish :one
+SELECT * FROM wishlist WHERE id=$1 AND w_id=$2;
+
+-- name: ListWishlist :many
+SELECT * FROM wishlist WHERE w_id=$1 ORDER BY priority ASC, added ASC;
+
+-- name: MarkWishBought :exec
+UPDATE wishlist SET bought=$1, c_id=$2 WHERE id=$3 AND w_id=$4;
+
+-- name: DeleteWish :exec
+DELETE FROM wishlist WHERE id=$1 AND w_id=$2;
+
+-- name: ForgetItemWishes :exec
+UPDATE wishlist SET c_id=NULL WHERE c_id=$1 AND w_id=$2;
+
+-- name: ListPurchases :many
+SELECT * FROM catalog WHERE w_id=sqlc.arg(w_id) AND purchased >= sqlc.arg(from_day) AND purchased < sqlc.arg(to_day)
+ORDER BY purchased ASC;
+
+-- name: PutRate :exec
+INSERT INTO exchange_rates (w_id, currency, rate, updated) VALUES ($1, $2, $3, $4)
+ON CONFLICT (w_id, currency) DO UPDATE SET rate=$3, updated=$4;
+
+-- name: ListRates :many
+SELECT * FROM exchange_rates WHERE w_id=$1 ORDER BY currency ASC;
+
+-- name: DeleteRate :exec
+DELETE FROM exchange_rates WHERE w_id=$1 AND currency=$2;
+
+-- name: DeleteAllRates :exec
+DELETE FROM exchange_rates WHERE w_id=$1;
+
+-- name: ConvertBudgets :exec
+UPDATE categories SET monthly_budget=monthly_budget*$1, yearly_budget=yearly_budget*$1 WHERE w_id=$2;
+
+-- name: ConvertWishPrices :exec
+UPDATE wishlist SET target_price=target_price*$1 WHERE w_id=$2;
+
+-- name: GetCalendarFeed :one
+SELECT * FROM calendar_feeds WHERE token=$1;
+
+-- name: GetUserCalendarFeed :one
+SELECT * FROM calendar_feeds WHERE u_id=$1 AND w_id=$2;
+
+-- name: PutCalendarFeed :exec
+INSERT INTO calendar_feeds (token, u_id, w_id, created) VALUES ($1, $2, $3, $4)
+ON CONFLICT (u_id, w_id) DO UPDATE SET token=$1, created=$4;
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Items not worn for this many months are suggested for decluttering, unless asked otherwise.
+const defaultDeclutterMonths = 6
+
+// Items which could be let go, along with those which were.
+type declutterView struct {
+	Months int
+	// The day dispositions are recorded for, unless changed.
+	Today string
+	// Kept items not worn in Months, or never, the priciest and longest unworn first. Prices in
+	// currencies without a rate count as nothing.
+	Unworn []catalogView
+	// Items let go, most recently first.
+	Gone []catalogView
+}
+
+func loadDeclutter(ctx context.Context, months int) (declutterView, error) {
+	v := declutterView{Months: months, Today: dayOf(time.Now()).Format(dateFormat)}
+	cs, err := store.ListCatalog(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return v, fmt.Errorf("list catalog: %w", err)
+	}
+	vs, err := catalogViews(ctx, cs)
+	if err != nil {
+		return v, fmt.Errorf("load catalog details: %w", err)
+	}
+	x, err := loadExchange(ctx)
+	if err != nil {
+		return v, err
+	}
+	cutoff := time.Now().AddDate(0, -months, 0)
+	for _, c := range vs {
+		switch {
+		case c.Lifecycle != lifecycleActive:
+			v.Gone = append(v.Gone, c)
+		case !c.LastActivity.Valid || c.LastActivity.Time.Before(cutoff):
+			v.Unworn = append(v.Unworn, c)
+		}
+	}
+	sort.SliceStable(v.Unworn, func(i, j int) bool {
+		a, b := v.Unworn[i], v.Unworn[j]
+		pa, pb := x.toHome(a.Price.Float64, a.Currency), x.toHome(b.Price.Float64, b.Currency)
+		if pa != pb {
+			return pa > pb
+		}
+		if a.LastActivity.Valid != b.LastActivity.Valid {
+			return !a.LastActivity.Valid
+		}
+		return a.LastActivity.Time.Before(b.LastActivity.Time)
+	})
+	sort.SliceStable(v.Gone, func(i, j int) bool {
+		return v.Gone[i].Disposed.Time.After(v.Gone[j].Disposed.Time)
+	})
+	return v, nil
+}
+
+// Lets each of the items go, recording what became of them and when.
+func disposeItems(ctx context.Context, ids []string, stage string, day time.Time) error {
+	if stage == lifecycleActive {
+		return fmt.Errorf("pick what became of them")
+	}
+	return store.InTx(ctx, func(store Store) error {
+		for _, id := range ids {
+			err := setLifecycle(ctx, store, id, lifecycleChange{Stage: stage, Date: sql.NullTime{Valid: true, Time: day}})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Renders the declutter report for the request's months, e.g months=12.
+func renderDeclutter(response http.ResponseWriter, req *http.Request) error {
+	months := defaultDeclutterMonths
+	if s := req.FormValue("months"); s != "" {
+		m, err := strconv.Atoi(s)
+		if err != nil || m < 0 {
+			return fmt.Errorf("months must be a whole number, got %q", s)
+		}
+		months = m
+	}
+	v, err := loadDeclutter(req.Context(), months)
+	if err != nil {
+		return err
+	}
+	r, err := listDeclutter(v)
+	if err != nil {
+		return fmt.Errorf("render declutter: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleDeclutter(response http.ResponseWriter, req *http.Request) error {
+	return renderDeclutter(response, req)
+}
+
+// Lets the checked items go. e.g api/declutter?lifecycle=donated&date=2022-05-01&id=...&id=...
+func handleDispose(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	ids := req.Form["id"]
+	if len(ids) == 0 {
+		return fmt.Errorf("tick the items to let go")
+	}
+	day, err := parseDate(req.Form.Get("date"))
+	if err != nil {
+		return err
+	}
+	if !day.Valid {
+		day = sql.NullTime{Valid: true, Time: dayOf(time.Now())}
+	}
+	stage := req.Form.Get("lifecycle")
+	err = disposeItems(req.Context(), ids, stage, day.Time)
+	if err != nil {
+		return fmt.Errorf("mark %v: %w", stage, err)
+	}
+	return renderDeclutter(response, req)
+}
+
+// Brings a let go item back into the wardrobe. e.g api/declutter/keep?id=...
+func handleKeep(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	err := setHidden(req.Context(), id, false)
+	if err != nil {
+		return fmt.Errorf("keep %v: %w", id, err)
+	}
+	return renderDeclutter(response, req)
+}
@@ -0,0 +1,563 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Columns of the csv files written by handleCatalog and handleActivity.
+var (
+	catalogColumns = []string{"id", "category", "brand", "color", "pattern", "title", "description", "price", "last_activity", "tags",
+		"size", "material", "seasons", "purchased", "retailer", "condition",
+		"min_temp", "max_temp", "rain_ok", "currency"}
+	activityColumns = []string{"id", "cid", "time", "note"}
+)
+
+// What happened, or would happen, to one line of an import.
+type importLine struct {
+	File   string
+	Line   int
+	ID     string
+	Action string
+	Err    string
+}
+
+// The outcome of importing a catalog.csv and/or activity.csv. Nothing is written unless every
+// line is valid.
+type importReport struct {
+	DryRun    bool
+	Committed bool
+	Lines     []importLine
+	Created   int
+	Updated   int
+	Errors    int
+}
+
+// Records a problem with the line, keeping any found before it.
+func (l *importLine) fail(format string, args ...interface{}) {
+	if l.Err != "" {
+		l.Err += "; "
+	}
+	l.Err += fmt.Sprintf(format, args...)
+}
+
+func (r *importReport) add(l importLine) {
+	switch {
+	case l.Err != "":
+		r.Errors++
+	case l.Action == "create":
+		r.Created++
+	case l.Action == "update":
+		r.Updated++
+	}
+	r.Lines = append(r.Lines, l)
+}
+
+// A row of a csv, with the line it started on.
+type csvRecord struct {
+	Line   int
+	Fields map[string]string
+}
+
+// Reads a csv with a header row. Columns may be in any order, or left out, but must be known.
+func readCSV(r io.Reader, known []string) ([]csvRecord, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	for _, h := range header {
+		if !containsString(known, h) {
+			return nil, fmt.Errorf("line 1: unknown column %q, expected some of %v", h, strings.Join(known, ","))
+		}
+	}
+	var rs []csvRecord
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		fields := make(map[string]string, len(header))
+		for i, h := range header {
+			fields[h] = row[i]
+		}
+		rs = append(rs, csvRecord{Line: line, Fields: fields})
+	}
+	return rs, nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses a unix millisecond timestamp as written by the exports. Missing or pre-epoch times,
+// which is how the catalog export writes items never used, are null.
+func parseMillis(s string) (sql.NullTime, error) {
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("parse time %q: expected unix milliseconds", s)
+	}
+	if ms <= 0 {
+		return sql.NullTime{}, nil
+	}
+	return sql.NullTime{Valid: true, Time: time.UnixMilli(ms).UTC()}, nil
+}
+
+// Checks and, unless it's a dry run or a line is invalid, applies the given exports to the current
+// wardrobe in one transaction. Either file may be nil. Catalog rows are upserted by id, and
+// activity rows are logged, or updated if their id exists. Rows without an id are created.
+// Updates keep the columns a file leaves out.
+func importCSV(ctx context.Context, catalog io.Reader, activity io.Reader, dryRun bool) (importReport, error) {
+	wid := currentWardrobe(ctx)
+	report := importReport{DryRun: dryRun}
+	home, err := homeCurrency(ctx)
+	if err != nil {
+		return report, err
+	}
+	var items []persist.PutItemParams
+	var lastUsed []sql.NullTime
+	// The tags of each item, or nil to leave them as they are when the column is missing.
+	var tags [][]string
+	var uses []persist.Activity
+	var usesExist []bool
+	// Items created by this import, which activity may refer to.
+	created := make(map[string]bool)
+
+	if catalog != nil {
+		rs, err := readCSV(catalog, catalogColumns)
+		if err != nil {
+			return report, fmt.Errorf("catalog.csv: %w", err)
+		}
+		// The line each id was first seen on.
+		seen := make(map[string]int)
+		for _, r := range rs {
+			f := r.Fields
+			l := importLine{File: "catalog.csv", Line: r.Line, ID: f["id"], Action: "update"}
+			// Updates start from the item as it is, so columns left out of the file are kept.
+			p := persist.PutItemParams{ID: f["id"], WID: wid, Currency: home}
+			if p.ID == "" {
+				p.ID = uuid.NewString()
+				l.ID = p.ID
+				l.Action = "create"
+			} else if c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: p.ID, WID: wid}); errors.Is(err, sql.ErrNoRows) {
+				l.Action = "create"
+				taken, err := store.CatalogIDTaken(ctx, p.ID)
+				if err != nil {
+					return report, fmt.Errorf("check catalog id %v: %w", p.ID, err)
+				}
+				if taken {
+					l.fail("id %q belongs to another wardrobe's item", p.ID)
+				}
+			} else if err != nil {
+				return report, fmt.Errorf("get catalog %v: %w", p.ID, err)
+			} else {
+				p = itemParams(c)
+			}
+			if first, ok := seen[p.ID]; ok {
+				l.fail("id %q is also on line %v", p.ID, first)
+			} else {
+				seen[p.ID] = r.Line
+			}
+			for col, dst := range map[string]*sql.NullString{
+				"category":    &p.Category,
+				"brand":       &p.Brand,
+				"color":       &p.Color,
+				"pattern":     &p.Pattern,
+				"title":       &p.Title,
+				"description": &p.Description,
+				"size":        &p.Size,
+				"material":    &p.Material,
+				"retailer":    &p.Retailer,
+				"condition":   &p.Condition,
+			} {
+				if v, ok := f[col]; ok {
+					*dst = ns([]string{v})
+				}
+			}
+			if v, ok := f["seasons"]; ok {
+				p.Seasons = joinSeasons(strings.Split(v, ","))
+			}
+			var err error
+			if v, ok := f["purchased"]; ok {
+				p.Purchased, err = parseDate(v)
+				if err != nil {
+					l.fail("%v", err)
+				}
+			}
+			if v, ok := f["min_temp"]; ok {
+				p.MinTemp, err = parseTemp(v)
+				if err != nil {
+					l.fail("min_temp: %v", err)
+				}
+			}
+			if v, ok := f["max_temp"]; ok {
+				p.MaxTemp, err = parseTemp(v)
+				if err != nil {
+					l.fail("max_temp: %v", err)
+				}
+			}
+			if v, ok := f["rain_ok"]; ok {
+				p.RainOk, err = parseRain(v)
+				if err != nil {
+					l.fail("%v", err)
+				}
+			}
+			if v, ok := f["price"]; ok {
+				p.Price, err = parsePrice(v)
+				if err != nil {
+					l.fail("%v", err)
+				}
+			}
+			if v, ok := f["currency"]; ok {
+				p.Currency, err = parseCurrency(v, home)
+				if err != nil {
+					l.fail("%v", err)
+				}
+			}
+			t, err := parseMillis(f["last_activity"])
+			if err != nil {
+				l.fail("%v", err)
+			}
+			if l.Action == "create" {
+				created[p.ID] = true
+			}
+			report.add(l)
+			items = append(items, p)
+			lastUsed = append(lastUsed, t)
+			if ts, ok := f["tags"]; ok {
+				tags = append(tags, append([]string{}, parseTags(ts)...))
+			} else {
+				tags = append(tags, nil)
+			}
+		}
+	}
+
+	if activity != nil {
+		rs, err := readCSV(activity, activityColumns)
+		if err != nil {
+			return report, fmt.Errorf("activity.csv: %w", err)
+		}
+		// The line each id was first seen on.
+		seen := make(map[string]int)
+		for _, r := range rs {
+			f := r.Fields
+			l := importLine{File: "activity.csv", Line: r.Line, ID: f["id"], Action: "update"}
+			a := persist.Activity{ID: f["id"], CID: f["cid"], Note: ns([]string{f["note"]}), WID: wid}
+			t, err := parseMillis(f["time"])
+			if err != nil {
+				l.fail("%v", err)
+			} else if !t.Valid {
+				l.fail("time is required")
+			}
+			a.Ts = t.Time
+			if !created[a.CID] {
+				_, err = store.GetCatalog(ctx, persist.GetCatalogParams{ID: a.CID, WID: wid})
+				if errors.Is(err, sql.ErrNoRows) {
+					l.fail("no catalog item %q", a.CID)
+				} else if err != nil {
+					return report, fmt.Errorf("get catalog %v: %w", a.CID, err)
+				}
+			}
+			if a.ID == "" {
+				a.ID = uuid.NewString()
+				l.ID = a.ID
+				l.Action = "create"
+			} else if old, err := store.GetUsage(ctx, persist.GetUsageParams{ID: a.ID, WID: wid}); errors.Is(err, sql.ErrNoRows) {
+				l.Action = "create"
+				taken, err := store.UsageIDTaken(ctx, a.ID)
+				if err != nil {
+					return report, fmt.Errorf("check usage id %v: %w", a.ID, err)
+				}
+				if taken {
+					l.fail("id %q belongs to another wardrobe's usage", a.ID)
+				}
+			} else if err != nil {
+				return report, fmt.Errorf("get usage %v: %w", a.ID, err)
+			} else {
+				// Updates only change the time and note.
+				if strings.TrimSpace(old.CID) != strings.TrimSpace(a.CID) {
+					l.fail("usage is of item %q, it can't be moved to %q", old.CID, a.CID)
+				}
+				if _, ok := f["note"]; !ok {
+					a.Note = old.Note
+				}
+			}
+			if first, ok := seen[a.ID]; ok {
+				l.fail("id %q is also on line %v", a.ID, first)
+			} else {
+				seen[a.ID] = r.Line
+			}
+			report.add(l)
+			uses = append(uses, a)
+			usesExist = append(usesExist, l.Action == "update")
+		}
+	}
+
+	if dryRun || report.Errors > 0 {
+		return report, nil
+	}
+	err = store.InTx(ctx, func(store Store) error {
+		for i, p := range items {
+			res, err := store.PutItem(ctx, p)
+			if err != nil {
+				return fmt.Errorf("put item %v: %w", p.ID, err)
+			}
+			// Nothing is written when the id belongs to another wardrobe.
+			if n, err := res.RowsAffected(); err == nil && n == 0 {
+				return fmt.Errorf("put item %v: id is taken", p.ID)
+			}
+			if lastUsed[i].Valid {
+				_, err = store.UpdateLastUsed(ctx, persist.UpdateLastUsedParams{LastActivity: lastUsed[i], ID: p.ID, WID: wid})
+				if err != nil {
+					return fmt.Errorf("update last used %v: %w", p.ID, err)
+				}
+			}
+			if tags[i] != nil {
+				err = setItemTags(ctx, store, p.ID, tags[i])
+				if err != nil {
+					return fmt.Errorf("set tags %v: %w", p.ID, err)
+				}
+			}
+		}
+		touched := make(map[string]bool)
+		for i, a := range uses {
+			var err error
+			if usesExist[i] {
+				_, err = store.PutUsage(ctx, persist.PutUsageParams{Note: a.Note, Ts: a.Ts, ID: a.ID, WID: wid})
+			} else {
+				_, err = store.LogUsage(ctx, persist.LogUsageParams{ID: a.ID, CID: a.CID, Ts: a.Ts, WID: wid})
+				if err == nil && a.Note.Valid {
+					_, err = store.SetUsageNote(ctx, persist.SetUsageNoteParams{Note: a.Note, ID: a.ID, WID: wid})
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("put usage %v: %w", a.ID, err)
+			}
+			touched[a.CID] = true
+		}
+		for cid := range touched {
+			err := refreshLastUse(ctx, store, cid)
+			if err != nil {
+				return fmt.Errorf("refresh last use %v: %w", cid, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// The columns of an item which an import may change, as they are now.
+func itemParams(c persist.Catalog) persist.PutItemParams {
+	return persist.PutItemParams{
+		ID:          c.ID,
+		Category:    c.Category,
+		Brand:       c.Brand,
+		Color:       c.Color,
+		Pattern:     c.Pattern,
+		Title:       c.Title,
+		Description: c.Description,
+		Price:       c.Price,
+		WID:         c.WID,
+		Size:        c.Size,
+		Material:    c.Material,
+		Seasons:     c.Seasons,
+		Purchased:   c.Purchased,
+		Retailer:    c.Retailer,
+		Condition:   c.Condition,
+		MinTemp:     c.MinTemp,
+		MaxTemp:     c.MaxTemp,
+		RainOk:      c.RainOk,
+		Currency:    c.Currency,
+	}
+}
+
+// Opens an uploaded file from the form, or returns nil if there isn't one.
+func formFile(req *http.Request, name string) (multipart.File, error) {
+	f, _, err := req.FormFile(name)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("open %v: %w", name, err)
+	}
+	return f, nil
+}
+
+// Imports uploaded catalog and activity csvs, rendering a report of each line.
+// e.g api/import?dryRun=true to preview without saving.
+func handleImport(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseMultipartForm(maxUploadBytes)
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	cf, err := formFile(req, "catalog")
+	if err != nil {
+		return err
+	}
+	af, err := formFile(req, "activity")
+	if err != nil {
+		return err
+	}
+	var catalog, activity io.Reader
+	if cf != nil {
+		defer cf.Close()
+		catalog = cf
+	}
+	if af != nil {
+		defer af.Close()
+		activity = af
+	}
+	dryRun := strings.Join(req.URL.Query()["dryRun"], "") == "true"
+	report, err := importCSV(req.Context(), catalog, activity, dryRun)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	r, err := renderImportReport(report)
+	if err != nil {
+		return fmt.Errorf("render report: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+// Exports and imports of the current wardrobe.
+func handleDataComponent(response http.ResponseWriter, req *http.Request) error {
+	_, err := response.Write([]byte(dataComponent))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+// Entry point for 'gorgina import -user <username> [-wardrobe <name>] [-dry-run] [-catalog file] [-activity file]'
+func runImport(args []string) error {
+	ctx := context.Background()
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	username := fs.String("user", "", "account to import as")
+	wardrobe := fs.String("wardrobe", "", "name of the wardrobe to import into, defaults to the account's oldest")
+	dryRun := fs.Bool("dry-run", false, "check the files and print what would change, without saving")
+	catalogPath := fs.String("catalog", "", "catalog.csv to import")
+	activityPath := fs.String("activity", "", "activity.csv to import")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *username == "" || (*catalogPath == "" && *activityPath == "") {
+		fs.Usage()
+		return fmt.Errorf("-user and one of -catalog or -activity are required")
+	}
+
+	ctx, s, err := openCLIWardrobe(ctx, *username, *wardrobe)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	var catalog, activity io.Reader
+	if *catalogPath != "" {
+		f, err := os.Open(*catalogPath)
+		if err != nil {
+			return fmt.Errorf("open catalog: %w", err)
+		}
+		defer f.Close()
+		catalog = f
+	}
+	if *activityPath != "" {
+		f, err := os.Open(*activityPath)
+		if err != nil {
+			return fmt.Errorf("open activity: %w", err)
+		}
+		defer f.Close()
+		activity = f
+	}
+	report, err := importCSV(ctx, catalog, activity, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, l := range report.Lines {
+		if l.Err != "" {
+			fmt.Printf("%v:%v\terror\t%v\n", l.File, l.Line, l.Err)
+		} else {
+			fmt.Printf("%v:%v\t%v\t%v\n", l.File, l.Line, l.Action, l.ID)
+		}
+	}
+	fmt.Printf("%v created, %v updated, %v errors\n", report.Created, report.Updated, report.Errors)
+	switch {
+	case report.Errors > 0:
+		return fmt.Errorf("nothing imported, fix the errors above first")
+	case report.DryRun:
+		fmt.Println("dry run, nothing imported")
+	}
+	return nil
+}
+
+// Opens the database for a command, acting as the user in their wardrobe with the given name,
+// or their oldest if it's empty.
+func openCLIWardrobe(ctx context.Context, username string, wardrobe string) (context.Context, *sqlStore, error) {
+	s, err := openStore(os.Getenv(dbUrlKey))
+	if err != nil {
+		return ctx, nil, err
+	}
+	ms, err := s.migrations()
+	if err != nil {
+		s.Close()
+		return ctx, nil, err
+	}
+	err = migrateUp(ctx, s.db, ms)
+	if err != nil {
+		s.Close()
+		return ctx, nil, fmt.Errorf("migrate DB: %w", err)
+	}
+	store = s
+
+	u, err := store.GetUserByName(ctx, username)
+	if err != nil {
+		s.Close()
+		return ctx, nil, fmt.Errorf("get user %v: %w", username, err)
+	}
+	ws, err := store.ListUserWardrobes(ctx, u.ID)
+	if err != nil {
+		s.Close()
+		return ctx, nil, fmt.Errorf("list wardrobes: %w", err)
+	}
+	for _, w := range ws {
+		if wardrobe == "" || w.Name == wardrobe {
+			return withWardrobe(ctx, u, w.ID), s, nil
+		}
+	}
+	s.Close()
+	return ctx, nil, fmt.Errorf("%v has no wardrobe named %q", username, wardrobe)
+}
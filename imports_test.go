@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Runs an export handler, returning the csv it wrote.
+func exportCSV(t *testing.T, ctx context.Context, h func(http.ResponseWriter, *http.Request) error) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	err := h(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec.Body.String()
+}
+
+// Importing the exports of a wardrobe back into it changes nothing.
+func TestImportCSVRoundTrip(t *testing.T) {
+	ctx := openTestStore(t)
+	catalog := `id,title,brand,price,currency,tags,size,material,seasons,purchased,min_temp,rain_ok
+coat,Coat,Barbour,120.50,GBP,"outer,waxed",M,cotton,"autumn,winter",2021-10-02,5,true
+scarf,Scarf,,,,,,wool,,,,
+`
+	activity := `id,cid,time,note
+a1,coat,1650000000000,rainy
+a2,scarf,1650000100000,
+`
+	report, err := importCSV(ctx, strings.NewReader(catalog), strings.NewReader(activity), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || report.Created != 4 {
+		t.Fatalf("import: got %+v, want 4 lines created", report)
+	}
+	before, err := store.ListCatalog(ctx, currentWardrobe(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range before {
+		if strings.TrimSpace(c.ID) == "scarf" && c.Price.Valid {
+			t.Fatalf("scarf has price %v, want none", c.Price.Float64)
+		}
+	}
+
+	catalog = exportCSV(t, ctx, handleCatalog)
+	activity = exportCSV(t, ctx, handleActivity)
+	report, err = importCSV(ctx, strings.NewReader(catalog), strings.NewReader(activity), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || report.Updated != 4 || report.Created != 0 || report.Errors != 0 {
+		t.Fatalf("re-import: got %+v, want 4 lines updated", report)
+	}
+	after, err := store.ListCatalog(ctx, currentWardrobe(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Fatalf("re-import changed the catalog\nbefore: %+v\nafter:  %+v", before, after)
+	}
+	if again := exportCSV(t, ctx, handleCatalog); again != catalog {
+		t.Fatalf("re-import changed the export\nbefore: %v\nafter:  %v", catalog, again)
+	}
+}
+
+// Nothing is written when any line is invalid.
+func TestImportCSVInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		catalog  string
+		activity string
+		wantErr  string
+	}{
+		{name: "price", catalog: "id,price\nshirt,cheap\n", wantErr: "cheap"},
+		{name: "currency", catalog: "id,currency\nshirt,euros\n", wantErr: "expected a code"},
+		{name: "usage of an unknown item", activity: "id,cid,time\nnew,gone,1650000000000\n", wantErr: `no catalog item "gone"`},
+		{name: "usage without a time", activity: "id,cid,time\nnew,shirt,\n", wantErr: "time is required"},
+		{name: "usage moved to another item", catalog: "id\ntrousers\n", activity: "id,cid,time\nworn,trousers,1650000000000\n", wantErr: "can't be moved"},
+		{name: "item id of another wardrobe", catalog: "id\ntheirs\n", wantErr: `"theirs" belongs to another wardrobe's item`},
+		{name: "usage id of another wardrobe", activity: "id,cid,time\nworn-by-them,shirt,1650000000000\n", wantErr: `"worn-by-them" belongs to another wardrobe's usage`},
+		{name: "repeated item id", catalog: "id,title\ntrousers,Jeans\ntrousers,Chinos\n", wantErr: `"trousers" is also on line 2`},
+		{name: "repeated usage id", activity: "id,cid,time\nnew,shirt,1650000000000\nnew,shirt,1650000100000\n", wantErr: `"new" is also on line 2`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			wid := currentWardrobe(ctx)
+			_, err := store.PutItem(ctx, persist.PutItemParams{ID: "shirt", WID: wid, Currency: "USD"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = store.LogUsage(ctx, persist.LogUsageParams{ID: "worn", CID: "shirt", Ts: time.Now().UTC(), WID: wid})
+			if err != nil {
+				t.Fatal(err)
+			}
+			other := testWardrobe(t, "them")
+			_, err = store.PutItem(other, persist.PutItemParams{ID: "theirs", WID: currentWardrobe(other), Currency: "USD"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = store.LogUsage(other, persist.LogUsageParams{ID: "worn-by-them", CID: "theirs", Ts: time.Now().UTC(), WID: currentWardrobe(other)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A dry run finds the same problems the import does.
+			for _, dryRun := range []bool{true, false} {
+				var catalog, activity io.Reader
+				if tc.catalog != "" {
+					catalog = strings.NewReader(tc.catalog)
+				}
+				if tc.activity != "" {
+					activity = strings.NewReader(tc.activity)
+				}
+				report, err := importCSV(ctx, catalog, activity, dryRun)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if report.Committed || report.Errors != 1 {
+					t.Fatalf("got %+v, want one invalid line", report)
+				}
+				var errs []string
+				for _, l := range report.Lines {
+					errs = append(errs, l.Err)
+				}
+				if !strings.Contains(strings.Join(errs, "; "), tc.wantErr) {
+					t.Fatalf("got errors %q, want one containing %q", errs, tc.wantErr)
+				}
+			}
+			cs, err := store.ListCatalog(ctx, wid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cs) != 1 {
+				t.Fatalf("catalog has %v items, want only the shirt", len(cs))
+			}
+		})
+	}
+}
+
+// Updating items with a file which leaves columns out keeps what's in them.
+func TestImportCSVMissingColumns(t *testing.T) {
+	ctx := openTestStore(t)
+	wid := currentWardrobe(ctx)
+	catalog := `id,title,price,currency,size,material,seasons,purchased,retailer,condition,min_temp,max_temp,rain_ok
+coat,Coat,120.50,GBP,M,cotton,"autumn,winter",2021-10-02,Barbour,good,5,15,true
+`
+	activity := `id,cid,time,note
+a1,coat,1650000000000,rainy
+`
+	_, err := importCSV(ctx, strings.NewReader(catalog), strings.NewReader(activity), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: "coat", WID: wid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := importCSV(ctx, strings.NewReader("id,title\ncoat,Waxed coat\n"), strings.NewReader("id,cid,time\na1,coat,1650000200000\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || report.Updated != 2 {
+		t.Fatalf("got %+v, want 2 lines updated", report)
+	}
+	after, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: "coat", WID: wid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := before
+	want.Title.String = "Waxed coat"
+	want.LastActivity = after.LastActivity
+	if !reflect.DeepEqual(after, want) {
+		t.Fatalf("got %+v\nwant %+v", after, want)
+	}
+	a, err := store.GetUsage(ctx, persist.GetUsageParams{ID: "a1", WID: wid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Note.String != "rainy" || a.Ts.UnixMilli() != 1650000200000 {
+		t.Fatalf("got usage at %v noting %q, want the new time and the old note", a.Ts.UnixMilli(), a.Note.String)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// The states an item can be in. Only clean items are available to wear.
+const (
+	stateClean   = "clean"
+	stateWorn    = "worn"
+	stateLaundry = "laundry"
+	stateCleaner = "cleaner"
+	stateLent    = "lent"
+	stateRepair  = "repair"
+)
+
+// Every state, in the order they're shown, along with how they read.
+var states = []struct{ Name, Label string }{
+	{stateClean, "clean"},
+	{stateWorn, "worn"},
+	{stateLaundry, "in laundry"},
+	{stateCleaner, "at dry cleaner"},
+	{stateLent, "lent out"},
+	{stateRepair, "in repair"},
+}
+
+// The states an item can move to from each state.
+var stateTransitions = map[string][]string{
+	stateClean:   {stateWorn, stateLaundry, stateCleaner, stateLent, stateRepair},
+	stateWorn:    {stateClean, stateLaundry, stateCleaner, stateLent, stateRepair},
+	stateLaundry: {stateClean},
+	stateCleaner: {stateClean},
+	stateLent:    {stateClean, stateWorn, stateLaundry},
+	stateRepair:  {stateClean, stateLaundry},
+}
+
+// The states an item can be moved to by hand, leaving out lent, which needs a loan.
+func nextStates(state string) []string {
+	var next []string
+	for _, s := range stateTransitions[state] {
+		if s != stateLent {
+			next = append(next, s)
+		}
+	}
+	return next
+}
+
+func stateLabel(state string) string {
+	for _, s := range states {
+		if s.Name == state {
+			return s.Label
+		}
+	}
+	return state
+}
+
+// Whether an item can be worn now. Lent items aren't, until they're returned.
+func available(c persist.Catalog) bool {
+	return c.State == stateClean
+}
+
+// Moves an item to another state, if the move is allowed, and logs the move. Items coming back
+// clean start counting their wears again, and items coming back from a loan close it. Should
+// run in a transaction.
+func setItemState(ctx context.Context, store Store, c persist.Catalog, to string, t time.Time) error {
+	if c.State == to {
+		return nil
+	}
+	if !containsString(stateTransitions[c.State], to) {
+		return fmt.Errorf("%v can't go from %v to %v", strings.TrimSpace(c.Title.String), stateLabel(c.State), stateLabel(to))
+	}
+	if c.State == stateLent {
+		err := closeLoans(ctx, store, c, t)
+		if err != nil {
+			return err
+		}
+	}
+	wears := c.WearsSinceClean
+	if to == stateClean {
+		wears = 0
+	}
+	err := store.SetItemState(ctx, persist.SetItemStateParams{State: to, WearsSinceClean: wears, ID: c.ID, WID: c.WID})
+	if err != nil {
+		return fmt.Errorf("set state: %w", err)
+	}
+	err = store.LogStateChange(ctx, persist.LogStateChangeParams{
+		ID:        uuid.NewString(),
+		CID:       c.ID,
+		WID:       c.WID,
+		Ts:        t,
+		FromState: c.State,
+		ToState:   to,
+	})
+	if err != nil {
+		return fmt.Errorf("log state change: %w", err)
+	}
+	return nil
+}
+
+// Counts a wear of the item, and marks it worn once it's been worn as often as its category
+// allows between washes. Should run in a transaction.
+func countWear(ctx context.Context, store Store, id string, t time.Time) error {
+	wid := currentWardrobe(ctx)
+	err := store.CountWear(ctx, persist.CountWearParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("count wear: %w", err)
+	}
+	c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	if c.State != stateClean {
+		return nil
+	}
+	cat, err := store.GetCategory(ctx, persist.GetCategoryParams{WID: wid, Name: strings.TrimSpace(c.Category.String)})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Items outside of any category are never marked worn.
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("get category: %w", err)
+	}
+	if cat.WearsBeforeWash.Valid && c.WearsSinceClean >= cat.WearsBeforeWash.Int32 {
+		return setItemState(ctx, store, c, stateWorn, t)
+	}
+	return nil
+}
+
+// Moves each of the items to a state, at the same moment. Items are only lent out by lending
+// them, so there's someone to get them back from.
+func moveItems(ctx context.Context, ids []string, to string) error {
+	wid := currentWardrobe(ctx)
+	if to == stateLent {
+		return fmt.Errorf("lend items from their card, so there's a borrower")
+	}
+	return store.InTx(ctx, func(store Store) error {
+		t := time.Now().UTC()
+		for _, id := range ids {
+			c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: id, WID: wid})
+			if err != nil {
+				return fmt.Errorf("get catalog %v: %w", id, err)
+			}
+			err = setItemState(ctx, store, c, to, t)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Items which need washing, or are away being washed or mended.
+type laundryBasket struct {
+	Worn    []catalogView
+	Laundry []catalogView
+	Cleaner []catalogView
+	Repair  []catalogView
+	Lent    []catalogView
+}
+
+func renderLaundry(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	cs, err := store.ListCatalog(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	vs, err := catalogViews(ctx, cs)
+	if err != nil {
+		return fmt.Errorf("load catalog details: %w", err)
+	}
+	var b laundryBasket
+	for _, v := range vs {
+		switch v.State {
+		case stateWorn:
+			b.Worn = append(b.Worn, v)
+		case stateLaundry:
+			b.Laundry = append(b.Laundry, v)
+		case stateCleaner:
+			b.Cleaner = append(b.Cleaner, v)
+		case stateRepair:
+			b.Repair = append(b.Repair, v)
+		case stateLent:
+			b.Lent = append(b.Lent, v)
+		}
+	}
+	r, err := listLaundry(b)
+	if err != nil {
+		return fmt.Errorf("render laundry: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleLaundry(response http.ResponseWriter, req *http.Request) error {
+	return renderLaundry(response, req)
+}
+
+// Moves items in the laundry basket to another state, and renders the basket.
+// e.g api/laundry/move?to=clean&id=...&id=...
+func handleMoveLaundry(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	to := req.Form.Get("to")
+	err = moveItems(req.Context(), req.Form["id"], to)
+	if err != nil {
+		return fmt.Errorf("move to %v: %w", stateLabel(to), err)
+	}
+	return renderLaundry(response, req)
+}
+
+// Moves a single item to another state, and renders the item. e.g api/state?id=...&to=laundry
+func handleSetState(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	to := req.FormValue("to")
+	err := moveItems(req.Context(), []string{id}, to)
+	if err != nil {
+		return fmt.Errorf("move to %v: %w", stateLabel(to), err)
+	}
+	return renderItem(response, req, id)
+}
+
+// Writes the card of a single catalog item.
+func renderItem(response http.ResponseWriter, req *http.Request, id string) error {
+	ctx := req.Context()
+	c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: id, WID: currentWardrobe(ctx)})
+	if err != nil {
+		return fmt.Errorf("fetch catalog entry: %w", err)
+	}
+	vs, err := catalogViews(ctx, []persist.Catalog{c})
+	if err != nil {
+		return fmt.Errorf("load catalog details: %w", err)
+	}
+	r, err := renderCatalogItem(vs[0])
+	if err != nil {
+		return fmt.Errorf("render item: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("write result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+func TestSetItemState(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		from, to  string
+		wantErr   bool
+		wantWears int32
+		// Whether the item starts out on a loan, which should be closed once it's back.
+		lent bool
+	}{
+		{name: "worn", from: stateClean, to: stateWorn, wantWears: 2},
+		{name: "washed", from: stateLaundry, to: stateClean},
+		{name: "worn then washed", from: stateWorn, to: stateClean},
+		{name: "repaired into the laundry", from: stateRepair, to: stateLaundry, wantWears: 2},
+		{name: "staying put", from: stateWorn, to: stateWorn, wantWears: 2},
+		{name: "worn from the laundry", from: stateLaundry, to: stateWorn, wantErr: true},
+		{name: "lent from the cleaner", from: stateCleaner, to: stateLent, wantErr: true},
+		{name: "worn from repair", from: stateRepair, to: stateWorn, wantErr: true},
+		{name: "unknown state", from: stateClean, to: "stolen", wantErr: true},
+		{name: "back from a loan", from: stateLent, to: stateClean, lent: true},
+		{name: "back from a loan dirty", from: stateLent, to: stateLaundry, lent: true, wantWears: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			wid := currentWardrobe(ctx)
+			putTestItem(t, ctx, "item", "")
+			err := store.SetItemState(ctx, persist.SetItemStateParams{State: tc.from, WearsSinceClean: 2, ID: "item", WID: wid})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.lent {
+				err = store.LendItem(ctx, persist.LendItemParams{ID: "loan", CID: "item", WID: wid, Borrower: "Sam", Lent: time.Now().UTC()})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: "item", WID: wid})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = setItemState(ctx, store, c, tc.to, time.Now().UTC())
+			c, cerr := store.GetCatalog(ctx, persist.GetCatalogParams{ID: "item", WID: wid})
+			if cerr != nil {
+				t.Fatal(cerr)
+			}
+			changes, cerr := store.ListStateChanges(ctx, persist.ListStateChangesParams{CID: "item", WID: wid})
+			if cerr != nil {
+				t.Fatal(cerr)
+			}
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("setItemState: want an error moving from %v to %v", tc.from, tc.to)
+				}
+				if c.State != tc.from || len(changes) != 0 {
+					t.Fatalf("a refused move left the item %v, with %v changes logged", c.State, len(changes))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setItemState: %v", err)
+			}
+			if c.State != tc.to || c.WearsSinceClean != tc.wantWears {
+				t.Fatalf("got %v after %v wears, want %v after %v", c.State, c.WearsSinceClean, tc.to, tc.wantWears)
+			}
+			wantChanges := 1
+			if tc.from == tc.to {
+				wantChanges = 0
+			}
+			if len(changes) != wantChanges {
+				t.Fatalf("logged %v changes, want %v", len(changes), wantChanges)
+			}
+			if tc.lent {
+				l, err := store.GetLoan(ctx, persist.GetLoanParams{ID: "loan", WID: wid})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !l.Returned.Valid {
+					t.Fatal("the loan is still open")
+				}
+			}
+		})
+	}
+}
+
+func TestCountWear(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// Wears allowed between washes by the item's category.
+		wearsBeforeWash sql.NullInt32
+		// Leaves the item outside of any category.
+		uncategorized bool
+		wears         int
+		wantState     string
+	}{
+		{name: "below the limit", wearsBeforeWash: sql.NullInt32{Valid: true, Int32: 2}, wears: 1, wantState: stateClean},
+		{name: "at the limit", wearsBeforeWash: sql.NullInt32{Valid: true, Int32: 2}, wears: 2, wantState: stateWorn},
+		{name: "every wear", wearsBeforeWash: sql.NullInt32{Valid: true, Int32: 1}, wears: 1, wantState: stateWorn},
+		{name: "no limit", wears: 5, wantState: stateClean},
+		{name: "no category", uncategorized: true, wears: 5, wantState: stateClean},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			wid := currentWardrobe(ctx)
+			err := store.CreateCategory(ctx, persist.CreateCategoryParams{WID: wid, Name: "coats", Icon: "🧥", WearsBeforeWash: tc.wearsBeforeWash})
+			if err != nil {
+				t.Fatal(err)
+			}
+			category := "coats"
+			if tc.uncategorized {
+				category = ""
+			}
+			putTestItem(t, ctx, "coat", category)
+			for i := 0; i < tc.wears; i++ {
+				err = store.InTx(ctx, func(store Store) error {
+					return countWear(ctx, store, "coat", time.Now().UTC())
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: "coat", WID: wid})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.State != tc.wantState || c.WearsSinceClean != int32(tc.wears) {
+				t.Fatalf("got %v after %v wears, want %v after %v", c.State, c.WearsSinceClean, tc.wantState, tc.wears)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Where an item is in its life. Only active items are kept in the wardrobe, every other stage
+// hides the item.
+const (
+	lifecycleActive    = "active"
+	lifecycleArchived  = "archived"
+	lifecycleSold      = "sold"
+	lifecycleDonated   = "donated"
+	lifecycleDiscarded = "discarded"
+	lifecycleLost      = "lost"
+)
+
+// Every stage, in the order they're shown. Each reads as it's named.
+var lifecycles = []string{lifecycleActive, lifecycleArchived, lifecycleSold, lifecycleDonated, lifecycleDiscarded, lifecycleLost}
+
+func validLifecycle(stage string) bool {
+	return containsString(lifecycles, stage)
+}
+
+// Where an item is moving to in its life, and what came of it.
+type lifecycleChange struct {
+	Stage string
+	// When the item left the wardrobe, if it did.
+	Date   sql.NullTime
+	Resale sql.NullFloat64
+	Note   sql.NullString
+}
+
+// Moves an item to another stage of its life. Items brought back to active forget where they
+// went. Should run in a transaction.
+func setLifecycle(ctx context.Context, store Store, id string, l lifecycleChange) error {
+	if !validLifecycle(l.Stage) {
+		return fmt.Errorf("unknown lifecycle stage %q", l.Stage)
+	}
+	if l.Stage == lifecycleActive {
+		l = lifecycleChange{Stage: lifecycleActive}
+	}
+	wid := currentWardrobe(ctx)
+	_, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", id, err)
+	}
+	err = store.SetLifecycle(ctx, persist.SetLifecycleParams{
+		Hidden:        l.Stage != lifecycleActive,
+		Lifecycle:     l.Stage,
+		Disposed:      l.Date,
+		ResalePrice:   l.Resale,
+		LifecycleNote: l.Note,
+		ID:            id,
+		WID:           wid,
+	})
+	if err != nil {
+		return fmt.Errorf("set lifecycle of %v: %w", id, err)
+	}
+	return nil
+}
+
+// Hides an item by archiving it today, or brings it back.
+func setHidden(ctx context.Context, id string, hidden bool) error {
+	l := lifecycleChange{Stage: lifecycleActive}
+	if hidden {
+		l = lifecycleChange{Stage: lifecycleArchived, Date: sql.NullTime{Valid: true, Time: dayOf(time.Now())}}
+	}
+	return setLifecycle(ctx, store, id, l)
+}
+
+// They changed where an item is in its life, from its edit form.
+// e.g api/lifecycle?id=...&lifecycle=sold&disposed=2022-05-01&resale_price=20&lifecycle_note=...
+func handleSetLifecycle(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	id := req.Form.Get("id")
+	l := lifecycleChange{
+		Stage: req.Form.Get("lifecycle"),
+		Note:  ns(req.Form["lifecycle_note"]),
+	}
+	l.Date, err = parseDate(req.Form.Get("disposed"))
+	if err != nil {
+		return err
+	}
+	if !l.Date.Valid && l.Stage != lifecycleActive {
+		l.Date = sql.NullTime{Valid: true, Time: dayOf(time.Now())}
+	}
+	l.Resale, err = parsePrice(req.Form.Get("resale_price"))
+	if err != nil {
+		return err
+	}
+	err = store.InTx(req.Context(), func(store Store) error {
+		return setLifecycle(req.Context(), store, id, l)
+	})
+	if err != nil {
+		return fmt.Errorf("mark %v %v: %w", id, l.Stage, err)
+	}
+	return handleList(response, req)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+func TestSetLifecycle(t *testing.T) {
+	day := sql.NullTime{Valid: true, Time: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)}
+	resale := sql.NullFloat64{Valid: true, Float64: 20}
+	for _, tc := range []struct {
+		name       string
+		change     lifecycleChange
+		wantHidden bool
+		wantResale sql.NullFloat64
+		wantErr    bool
+	}{
+		{name: "sold", change: lifecycleChange{Stage: lifecycleSold, Date: day, Resale: resale}, wantHidden: true, wantResale: resale},
+		{name: "archived", change: lifecycleChange{Stage: lifecycleArchived, Date: day}, wantHidden: true},
+		{name: "back to active forgets the sale", change: lifecycleChange{Stage: lifecycleActive, Date: day, Resale: resale}},
+		{name: "unknown stage", change: lifecycleChange{Stage: "stolen"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			wid := currentWardrobe(ctx)
+			_, err := store.PutItem(ctx, persist.PutItemParams{ID: "item", WID: wid, Currency: "USD"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = setLifecycle(ctx, store, "item", tc.change)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("setLifecycle: want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setLifecycle: %v", err)
+			}
+			c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: "item", WID: wid})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Lifecycle != tc.change.Stage || c.Hidden != tc.wantHidden || c.ResalePrice != tc.wantResale {
+				t.Fatalf("got %v, hidden %v, resale %v; want %v, hidden %v, resale %v",
+					c.Lifecycle, c.Hidden, c.ResalePrice, tc.change.Stage, tc.wantHidden, tc.wantResale)
+			}
+			if tc.wantHidden != c.Disposed.Valid {
+				t.Fatalf("disposed %v, want it set only when hidden", c.Disposed)
+			}
+		})
+	}
+}
+
+// Backups from before lifecycles only say whether an item is hidden.
+func TestRestoreBackupLifecycle(t *testing.T) {
+	ctx := openTestStore(t)
+	b := backup{
+		Version: 6,
+		Catalog: []persist.Catalog{{ID: "worn"}, {ID: "hidden", Hidden: true}},
+	}
+	err := restoreBackup(ctx, zipBackup(t, b, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{"worn": lifecycleActive, "hidden": lifecycleArchived} {
+		c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: id, WID: currentWardrobe(ctx)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Lifecycle != want {
+			t.Errorf("%v is %v, want %v", id, c.Lifecycle, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Lends an item to someone, who's expected to bring it back by due, if set.
+func lendItem(ctx context.Context, id string, borrower string, due sql.NullTime) error {
+	wid := currentWardrobe(ctx)
+	borrower = strings.TrimSpace(borrower)
+	if borrower == "" {
+		return fmt.Errorf("say who it's lent to")
+	}
+	return store.InTx(ctx, func(store Store) error {
+		t := time.Now().UTC()
+		c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get catalog %v: %w", id, err)
+		}
+		if c.State == stateLent {
+			return fmt.Errorf("%v is already lent out", strings.TrimSpace(c.Title.String))
+		}
+		err = setItemState(ctx, store, c, stateLent, t)
+		if err != nil {
+			return err
+		}
+		err = store.LendItem(ctx, persist.LendItemParams{ID: uuid.NewString(), CID: id, WID: wid, Borrower: borrower, Lent: t, Due: due})
+		if err != nil {
+			return fmt.Errorf("lend item: %w", err)
+		}
+		return nil
+	})
+}
+
+// Marks a loan returned, and the item back and clean.
+func returnLoan(ctx context.Context, id string) error {
+	wid := currentWardrobe(ctx)
+	return store.InTx(ctx, func(store Store) error {
+		l, err := store.GetLoan(ctx, persist.GetLoanParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get loan %v: %w", id, err)
+		}
+		if l.Returned.Valid {
+			return fmt.Errorf("already returned")
+		}
+		c, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: l.CID, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get catalog %v: %w", l.CID, err)
+		}
+		if c.State != stateLent {
+			// Its state moved on without the loan, which only needs closing.
+			return closeLoans(ctx, store, c, time.Now().UTC())
+		}
+		return setItemState(ctx, store, c, stateClean, time.Now().UTC())
+	})
+}
+
+// Closes the item's open loans, once it's no longer lent out. Should run in a transaction.
+func closeLoans(ctx context.Context, store Store, c persist.Catalog, t time.Time) error {
+	err := store.ReturnItemLoans(ctx, persist.ReturnItemLoansParams{Returned: sql.NullTime{Valid: true, Time: t}, CID: c.ID, WID: c.WID})
+	if err != nil {
+		return fmt.Errorf("return loans: %w", err)
+	}
+	return nil
+}
+
+// Whether the loan should have been returned by now.
+func overdue(l persist.Loan) bool {
+	return !l.Returned.Valid && l.Due.Valid && l.Due.Time.Before(dayOf(time.Now()))
+}
+
+// A loan along with the item lent.
+type loanView struct {
+	persist.Loan
+	Item *catalogView
+}
+
+// The loans still out, most overdue first, and those which were returned.
+type loanList struct {
+	Open     []loanView
+	Returned []loanView
+}
+
+func renderLoans(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	wid := currentWardrobe(ctx)
+	ls, err := store.ListLoans(ctx, wid)
+	if err != nil {
+		return fmt.Errorf("list loans: %w", err)
+	}
+	cs, err := store.ListCatalog(ctx, wid)
+	if err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	vs, err := catalogViews(ctx, cs)
+	if err != nil {
+		return fmt.Errorf("load catalog details: %w", err)
+	}
+	items := make(map[string]*catalogView)
+	for i := range vs {
+		items[vs[i].ID] = &vs[i]
+	}
+	var l loanList
+	for _, loan := range ls {
+		v := loanView{Loan: loan, Item: items[loan.CID]}
+		if loan.Returned.Valid {
+			l.Returned = append(l.Returned, v)
+		} else {
+			l.Open = append(l.Open, v)
+		}
+	}
+	// Loans without a due date go last.
+	sort.SliceStable(l.Open, func(i, j int) bool {
+		a, b := l.Open[i].Due, l.Open[j].Due
+		return a.Valid && (!b.Valid || a.Time.Before(b.Time))
+	})
+	r, err := listLoans(l)
+	if err != nil {
+		return fmt.Errorf("render loans: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleLoans(response http.ResponseWriter, req *http.Request) error {
+	return renderLoans(response, req)
+}
+
+// They lent an item, and it's re-rendered. e.g api/loan/lend?id=...&borrower=Sam&due=2022-06-01
+func handleLend(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	due, err := parseDate(req.FormValue("due"))
+	if err != nil {
+		return err
+	}
+	err = lendItem(req.Context(), id, req.FormValue("borrower"), due)
+	if err != nil {
+		return fmt.Errorf("lend %v: %w", id, err)
+	}
+	return renderItem(response, req, id)
+}
+
+// A loan came back. Renders the loans, or just the item when asked from its card.
+// e.g api/loan/return?id=...&item=...
+func handleReturnLoan(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	err := returnLoan(req.Context(), id)
+	if err != nil {
+		return fmt.Errorf("return loan %v: %w", id, err)
+	}
+	if item := req.FormValue("item"); item != "" {
+		return renderItem(response, req, item)
+	}
+	return renderLoans(response, req)
+}
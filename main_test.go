@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// Points store at a fresh SQLite database, and photoDir at an empty directory, for the length of
+// the test. Returns a context acting in the wardrobe of a new user.
+func openTestStore(t *testing.T) context.Context {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := openSQLite(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, err := s.migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrateUp(context.Background(), s.db, ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStore, oldPhotoDir := store, photoDir
+	store, photoDir = s, filepath.Join(dir, "photos")
+	t.Cleanup(func() {
+		store, photoDir = oldStore, oldPhotoDir
+		s.Close()
+	})
+	return testWardrobe(t, "me")
+}
+
+// Adds a user, returning a context acting in their wardrobe.
+func testWardrobe(t *testing.T, username string) context.Context {
+	t.Helper()
+	ctx := context.Background()
+	u, err := addUser(ctx, store, username, "password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := store.ListUserWardrobes(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return withWardrobe(ctx, u, ws[0].ID)
+}
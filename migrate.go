@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Numbered schema migrations for each backend, named like 0001_init.up.sql and 0001_init.down.sql.
+//
+//go:embed db/migrations/*/*.sql
+var migrationFiles embed.FS
+
+// A single versioned schema change.
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Tracks which migrations have been applied to the database.
+const createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations
+(
+	version integer NOT NULL PRIMARY KEY,
+	applied_at timestamp NOT NULL
+);`
+
+// Reads all migrations in the given directory, sorted by version.
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir: %w", err)
+	}
+	byVersion := make(map[int]*migration)
+	for _, e := range entries {
+		name := e.Name()
+		var up bool
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			up = true
+		case strings.HasSuffix(name, ".down.sql"):
+		default:
+			continue
+		}
+		prefix, rest, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %v: expected name like 0001_description.up.sql", name)
+		}
+		v, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %v: parse version: %w", name, err)
+		}
+		contents, err := fs.ReadFile(fsys, dir+"/"+name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %v: %w", name, err)
+		}
+		m, ok := byVersion[v]
+		if !ok {
+			m = &migration{Version: v}
+			byVersion[v] = m
+		}
+		if up {
+			m.Name = strings.TrimSuffix(rest, ".up.sql")
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+	var ms []migration
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %v has no up file", m.Version)
+		}
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	return ms, nil
+}
+
+// Returns the applied migration versions and when they were applied.
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[int]time.Time, error) {
+	_, err := db.ExecContext(ctx, createMigrationsTable)
+	if err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var v int
+		var t time.Time
+		err = rows.Scan(&v, &t)
+		if err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[v] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// Fails if the database has migrations applied which this binary does not know about,
+// which means it was migrated by a newer version of gorgina.
+func checkNotAhead(ms []migration, applied map[int]time.Time) error {
+	known := make(map[int]bool)
+	for _, m := range ms {
+		known[m.Version] = true
+	}
+	for v := range applied {
+		if !known[v] {
+			return fmt.Errorf("database has migration %v applied, which is newer than this binary supports (latest %v); refusing to run", v, ms[len(ms)-1].Version)
+		}
+	}
+	return nil
+}
+
+// Applies every pending migration, in order, each in its own transaction.
+func migrateUp(ctx context.Context, db *sql.DB, ms []migration) error {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = checkNotAhead(ms, applied)
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err = applyMigration(ctx, db, m.Version, m.Up, true)
+		if err != nil {
+			return fmt.Errorf("apply migration %v_%v: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// Reverts the n most recently applied migrations.
+func migrateDown(ctx context.Context, db *sql.DB, ms []migration, n int) error {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = checkNotAhead(ms, applied)
+	if err != nil {
+		return err
+	}
+	for i := len(ms) - 1; i >= 0 && n > 0; i-- {
+		m := ms[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return fmt.Errorf("migration %v_%v cannot be reverted: no down file", m.Version, m.Name)
+		}
+		err = applyMigration(ctx, db, m.Version, m.Down, false)
+		if err != nil {
+			return fmt.Errorf("revert migration %v_%v: %w", m.Version, m.Name, err)
+		}
+		n--
+	}
+	return nil
+}
+
+// Executes the migration script and records the new state in schema_migrations, atomically.
+func applyMigration(ctx context.Context, db *sql.DB, version int, script string, up bool) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("open transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, script)
+	if err != nil {
+		return fmt.Errorf("execute: %w", err)
+	}
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations(version, applied_at) VALUES ($1, $2)", version, time.Now().UTC())
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version=$1", version)
+	}
+	if err != nil {
+		return fmt.Errorf("record migration: %w", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Entry point for 'gorgina migrate <status|up|down [n]>'
+func runMigrate(args []string) error {
+	ctx := context.Background()
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate <status|up|down [n]>")
+	}
+	s, err := openStore(os.Getenv(dbUrlKey))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	db := s.db
+	ms, err := s.migrations()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "status":
+		applied, err := appliedMigrations(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, m := range ms {
+			if t, ok := applied[m.Version]; ok {
+				fmt.Printf("%04d_%v\tapplied %v\n", m.Version, m.Name, t.Format(time.RFC3339))
+			} else {
+				fmt.Printf("%04d_%v\tpending\n", m.Version, m.Name)
+			}
+		}
+		return checkNotAhead(ms, applied)
+	case "up":
+		return migrateUp(ctx, db, ms)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("parse migration count: %w", err)
+			}
+		}
+		return migrateDown(ctx, db, ms, n)
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected status, up or down", args[0])
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Executes a transaction which saves the outfit's name and replaces its items.
+func putOutfit(ctx context.Context, id string, name string, cids []string) error {
+	wid := currentWardrobe(ctx)
+	return store.InTx(ctx, func(store Store) error {
+		err := store.PutOutfit(ctx, persist.PutOutfitParams{ID: id, Name: name, WID: wid})
+		if err != nil {
+			return fmt.Errorf("put outfit: %w", err)
+		}
+		// Nothing is written when the id belongs to another wardrobe.
+		_, err = store.GetOutfit(ctx, persist.GetOutfitParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get outfit: %w", err)
+		}
+		err = store.ClearOutfitItems(ctx, persist.ClearOutfitItemsParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("clear outfit items: %w", err)
+		}
+		for _, cid := range cids {
+			_, err = store.GetCatalog(ctx, persist.GetCatalogParams{ID: cid, WID: wid})
+			if err != nil {
+				return fmt.Errorf("get catalog %v: %w", cid, err)
+			}
+			err = store.AddOutfitItem(ctx, persist.AddOutfitItemParams{OID: id, CID: cid})
+			if err != nil {
+				return fmt.Errorf("add outfit item %v: %w", cid, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Executes a transaction which deletes the outfit. The usage logged for its items is kept.
+func deleteOutfit(ctx context.Context, id string) error {
+	wid := currentWardrobe(ctx)
+	return store.InTx(ctx, func(store Store) error {
+		err := store.DetachOutfitUsage(ctx, persist.DetachOutfitUsageParams{OID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("detach item usage: %w", err)
+		}
+		err = store.DeleteAllOutfitUsage(ctx, persist.DeleteAllOutfitUsageParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("delete outfit usage: %w", err)
+		}
+		err = store.ClearOutfitItems(ctx, persist.ClearOutfitItemsParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("clear outfit items: %w", err)
+		}
+		err = store.DeleteOutfitPlans(ctx, persist.DeleteOutfitPlansParams{OID: ns([]string{id}), WID: wid})
+		if err != nil {
+			return fmt.Errorf("delete plans: %w", err)
+		}
+		err = store.DeleteOutfit(ctx, persist.DeleteOutfitParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("delete outfit: %w", err)
+		}
+		return nil
+	})
+}
+
+// Executes a transaction which marks the outfit, and every item in it, as used now.
+func wearOutfit(ctx context.Context, store Store, id string) error {
+	wid := currentWardrobe(ctx)
+	return store.InTx(ctx, func(store Store) error {
+		_, err := store.GetOutfit(ctx, persist.GetOutfitParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get outfit: %w", err)
+		}
+		items, err := store.ListOutfitItems(ctx, persist.ListOutfitItemsParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("list outfit items: %w", err)
+		}
+		t := time.Now().UTC()
+		oaid := uuid.NewString()
+		err = store.LogOutfitUsage(ctx, persist.LogOutfitUsageParams{ID: oaid, OID: id, Ts: t})
+		if err != nil {
+			return fmt.Errorf("log outfit usage: %w", err)
+		}
+		for _, item := range items {
+			err = logWear(ctx, store, item.CID, t, sql.NullString{Valid: true, String: oaid})
+			if err != nil {
+				return err
+			}
+		}
+		err = store.RefreshOutfitLastUsed(ctx, persist.RefreshOutfitLastUsedParams{OID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("update outfit last used: %w", err)
+		}
+		return nil
+	})
+}
+
+// Executes a transaction which changes the time and note of a use of an outfit, along with the
+// matching usage of its items.
+func putOutfitUsage(ctx context.Context, id string, t time.Time, note string) error {
+	wid := currentWardrobe(ctx)
+	return store.InTx(ctx, func(store Store) error {
+		oa, err := store.GetOutfitUsage(ctx, persist.GetOutfitUsageParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get outfit usage: %w", err)
+		}
+		err = store.PutOutfitUsage(ctx, persist.PutOutfitUsageParams{Note: ns([]string{note}), Ts: t, ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("put outfit usage: %w", err)
+		}
+		oaid := sql.NullString{Valid: true, String: id}
+		err = store.PutOutfitItemUsage(ctx, persist.PutOutfitItemUsageParams{Note: ns([]string{note}), Ts: t, OaID: oaid, WID: wid})
+		if err != nil {
+			return fmt.Errorf("put item usage: %w", err)
+		}
+		return refreshOutfitUsage(ctx, store, oa.OID, oaid)
+	})
+}
+
+// Executes a transaction which deletes a use of an outfit, along with the matching usage of its items.
+func deleteOutfitUsage(ctx context.Context, id string) (persist.OutfitActivity, error) {
+	wid := currentWardrobe(ctx)
+	var oa persist.OutfitActivity
+	err := store.InTx(ctx, func(store Store) error {
+		var err error
+		oa, err = store.GetOutfitUsage(ctx, persist.GetOutfitUsageParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get outfit usage: %w", err)
+		}
+		oaid := sql.NullString{Valid: true, String: id}
+		as, err := store.ListOutfitItemUsage(ctx, persist.ListOutfitItemUsageParams{OaID: oaid, WID: wid})
+		if err != nil {
+			return fmt.Errorf("list item usage: %w", err)
+		}
+		err = store.DeleteOutfitItemUsage(ctx, persist.DeleteOutfitItemUsageParams{OaID: oaid, WID: wid})
+		if err != nil {
+			return fmt.Errorf("delete item usage: %w", err)
+		}
+		err = store.DeleteOutfitUsage(ctx, persist.DeleteOutfitUsageParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("delete outfit usage: %w", err)
+		}
+		for _, a := range as {
+			err = refreshLastUse(ctx, store, a.CID)
+			if err != nil {
+				return fmt.Errorf("refreshing catalog usage %v: %w", a.CID, err)
+			}
+		}
+		err = store.RefreshOutfitLastUsed(ctx, persist.RefreshOutfitLastUsedParams{OID: oa.OID, WID: wid})
+		if err != nil {
+			return fmt.Errorf("update outfit last used: %w", err)
+		}
+		return nil
+	})
+	return oa, err
+}
+
+// Recomputes the last use of the outfit, and of the items used as part of the given outfit usage.
+func refreshOutfitUsage(ctx context.Context, store Store, oid string, oaid sql.NullString) error {
+	wid := currentWardrobe(ctx)
+	as, err := store.ListOutfitItemUsage(ctx, persist.ListOutfitItemUsageParams{OaID: oaid, WID: wid})
+	if err != nil {
+		return fmt.Errorf("list item usage: %w", err)
+	}
+	for _, a := range as {
+		err = refreshLastUse(ctx, store, a.CID)
+		if err != nil {
+			return fmt.Errorf("refreshing catalog usage %v: %w", a.CID, err)
+		}
+	}
+	err = store.RefreshOutfitLastUsed(ctx, persist.RefreshOutfitLastUsedParams{OID: oid, WID: wid})
+	if err != nil {
+		return fmt.Errorf("update outfit last used: %w", err)
+	}
+	return nil
+}
+
+// Gathers each outfit along with its items.
+func outfitViews(ctx context.Context) ([]outfitView, error) {
+	wid := currentWardrobe(ctx)
+	outfits, err := store.ListOutfits(ctx, wid)
+	if err != nil {
+		return nil, fmt.Errorf("list outfits: %w", err)
+	}
+	cs, err := store.ListCatalog(ctx, wid)
+	if err != nil {
+		return nil, fmt.Errorf("list catalog: %w", err)
+	}
+	cvs, err := catalogViews(ctx, cs)
+	if err != nil {
+		return nil, fmt.Errorf("load catalog details: %w", err)
+	}
+	byID := make(map[string]catalogView)
+	for _, c := range cvs {
+		byID[c.ID] = c
+	}
+	ois, err := store.ListAllOutfitItems(ctx, wid)
+	if err != nil {
+		return nil, fmt.Errorf("list outfit items: %w", err)
+	}
+	members := make(map[string][]catalogView)
+	for _, oi := range ois {
+		members[oi.OID] = append(members[oi.OID], byID[oi.CID])
+	}
+	vs := make([]outfitView, len(outfits))
+	for i, o := range outfits {
+		vs[i] = outfitView{Outfit: o, Items: members[o.ID]}
+	}
+	return vs, nil
+}
+
+// Renders all outfits.
+func handleOutfits(response http.ResponseWriter, req *http.Request) error {
+	vs, err := outfitViews(req.Context())
+	if err != nil {
+		return err
+	}
+	r, err := listOutfits(vs)
+	if err != nil {
+		return fmt.Errorf("render outfits: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+// Component for building a new outfit, or editing an existing one.
+func handlePutOutfitComponent(response http.ResponseWriter, req *http.Request) error {
+	wid := currentWardrobe(req.Context())
+	id := strings.Join(req.URL.Query()["id"], "")
+	var o persist.Outfit
+	selected := make(map[string]bool)
+	if id != "" {
+		var err error
+		o, err = store.GetOutfit(req.Context(), persist.GetOutfitParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("loading outfit: %w", err)
+		}
+		ois, err := store.ListOutfitItems(req.Context(), persist.ListOutfitItemsParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("loading outfit items: %w", err)
+		}
+		for _, oi := range ois {
+			selected[oi.CID] = true
+		}
+	}
+	cs, err := store.ListCatalog(req.Context(), wid)
+	if err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	// Hidden items can't be added to an outfit, but stay in the ones they're already in.
+	var options []persist.Catalog
+	for _, c := range cs {
+		if !c.Hidden || selected[c.ID] {
+			options = append(options, c)
+		}
+	}
+	cvs, err := catalogViews(req.Context(), options)
+	if err != nil {
+		return fmt.Errorf("load catalog details: %w", err)
+	}
+	r, err := putOutfitForm(o, cvs, selected)
+	if err != nil {
+		return fmt.Errorf("render outfit form: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+// They saved an outfit
+func handlePutOutfit(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form data: %w", err)
+	}
+	id := strings.Join(req.Form["id"], "")
+	if id == "" {
+		id = uuid.NewString()
+	}
+	name := strings.TrimSpace(strings.Join(req.Form["name"], " "))
+	if name == "" {
+		name = "Untitled outfit"
+	}
+	err = putOutfit(req.Context(), id, name, req.Form["item"])
+	if err != nil {
+		return fmt.Errorf("saving outfit %v: %w", id, err)
+	}
+	return handleOutfits(response, req)
+}
+
+func handleDeleteOutfit(response http.ResponseWriter, req *http.Request) error {
+	id := strings.Join(req.URL.Query()["id"], "")
+	err := deleteOutfit(req.Context(), id)
+	if err != nil {
+		return fmt.Errorf("delete outfit %v: %w", id, err)
+	}
+	return handleOutfits(response, req)
+}
+
+// Mark every item in an outfit as used, and render the outfits.
+func handleWearOutfit(response http.ResponseWriter, req *http.Request) error {
+	id := strings.Join(req.URL.Query()["id"], "")
+	err := wearOutfit(req.Context(), store, id)
+	if err != nil {
+		return fmt.Errorf("wear outfit %v: %w", id, err)
+	}
+	return handleOutfits(response, req)
+}
+
+// Renders the history of the requested outfit
+func handleOutfitHistoryComponent(response http.ResponseWriter, req *http.Request) error {
+	id := strings.Join(req.URL.Query()["id"], "")
+	return renderOutfitHistory(response, req, id)
+}
+
+func renderOutfitHistory(response http.ResponseWriter, req *http.Request, id string) error {
+	wid := currentWardrobe(req.Context())
+	o, err := store.GetOutfit(req.Context(), persist.GetOutfitParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("loading outfit: %w", err)
+	}
+	oas, err := store.ListOutfitUsage(req.Context(), persist.ListOutfitUsageParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("load outfit history %v: %w", id, err)
+	}
+	r, err := renderEditableOutfitHistory(o, oas)
+	if err != nil {
+		return fmt.Errorf("render outfit history %v: %w", id, err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+// Updates the time and note of a use of an outfit
+func handlePutOutfitUse(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	id := req.PostFormValue("id")
+	t, err := parseFormTime(req)
+	if err != nil {
+		return err
+	}
+	err = putOutfitUsage(req.Context(), id, t, req.PostFormValue("note"))
+	if err != nil {
+		return fmt.Errorf("put outfit usage %v: %w", id, err)
+	}
+	return nil
+}
+
+// Deletes a use of an outfit, and renders the remaining history of the outfit.
+func handleDeleteOutfitUse(response http.ResponseWriter, req *http.Request) error {
+	id := strings.Join(req.URL.Query()["id"], "")
+	oa, err := deleteOutfitUsage(req.Context(), id)
+	if err != nil {
+		return fmt.Errorf("delete outfit usage %v: %w", id, err)
+	}
+	return renderOutfitHistory(response, req, oa.OID)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+const (
+	photoDirKey = "PHOTO_DIR"
+
+	// Largest upload we'll accept, across all photos in a request.
+	maxUploadBytes = 32 << 20
+	// Largest photo we'll decode, in pixels. Small files can unpack into huge images.
+	maxPhotoPixels = 40 << 20
+	// Thumbnails are scaled to fit in a square of this many pixels.
+	thumbnailSize = 256
+)
+
+// Directory where photo blobs are kept. Each photo is saved as <id> along with a jpeg <id>.thumb.
+var photoDir = "photos"
+
+func photoPath(id string, thumb bool) string {
+	if thumb {
+		return filepath.Join(photoDir, id+".thumb")
+	}
+	return filepath.Join(photoDir, id)
+}
+
+// Saves an uploaded photo and its thumbnail, and records it against the catalog item.
+func addPhoto(ctx context.Context, cid string, fh *multipart.FileHeader) error {
+	_, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: cid, WID: currentWardrobe(ctx)})
+	if err != nil {
+		return fmt.Errorf("get catalog %v: %w", cid, err)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return fmt.Errorf("open upload: %w", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("read upload: %w", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode %v: %w", fh.Filename, err)
+	}
+	if cfg.Width*cfg.Height > maxPhotoPixels {
+		return fmt.Errorf("%v is %vx%v, which is too many pixels", fh.Filename, cfg.Width, cfg.Height)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode %v: %w", fh.Filename, err)
+	}
+	var thumb bytes.Buffer
+	err = jpeg.Encode(&thumb, thumbnail(img, thumbnailSize), &jpeg.Options{Quality: 85})
+	if err != nil {
+		return fmt.Errorf("encode thumbnail: %w", err)
+	}
+
+	id := uuid.NewString()
+	err = os.MkdirAll(photoDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("create photo dir: %w", err)
+	}
+	err = os.WriteFile(photoPath(id, false), data, 0o644)
+	if err != nil {
+		return fmt.Errorf("write photo: %w", err)
+	}
+	err = os.WriteFile(photoPath(id, true), thumb.Bytes(), 0o644)
+	if err != nil {
+		return fmt.Errorf("write thumbnail: %w", err)
+	}
+	err = store.AddPhoto(ctx, persist.AddPhotoParams{
+		ID:          id,
+		CID:         cid,
+		ContentType: "image/" + format,
+		Ts:          time.Now().UTC(),
+	})
+	if err != nil {
+		removePhotoFiles(id)
+		return fmt.Errorf("save photo: %w", err)
+	}
+	return nil
+}
+
+// Best effort cleanup of a photo's blobs, for after its row is gone.
+func removePhotoFiles(id string) {
+	_ = os.Remove(photoPath(id, false))
+	_ = os.Remove(photoPath(id, true))
+}
+
+// Scales img down to fit within a size x size square, averaging the pixels which land in each
+// destination pixel. Images which already fit are returned as is.
+func thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= size && sh <= size {
+		return img
+	}
+	dw, dh := size, sh*size/sw
+	if sh > sw {
+		dw, dh = sw*size/sh, size
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := b.Min.Y+y*sh/dh, b.Min.Y+(y+1)*sh/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := b.Min.X+x*sw/dw, b.Min.X+(x+1)*sw/dw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// Serves a photo, or its thumbnail. e.g data/photo?id=...&thumb=true
+func handlePhoto(response http.ResponseWriter, req *http.Request) error {
+	id := strings.Join(req.URL.Query()["id"], "")
+	thumb := strings.Join(req.URL.Query()["thumb"], "") == "true"
+	p, err := store.GetPhoto(req.Context(), persist.GetPhotoParams{ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("get photo %v: %w", id, err)
+	}
+	f, err := os.Open(photoPath(strings.TrimSpace(p.ID), thumb))
+	if err != nil {
+		return fmt.Errorf("open photo %v: %w", id, err)
+	}
+	defer f.Close()
+	if thumb {
+		response.Header().Set("Content-Type", "image/jpeg")
+	} else {
+		response.Header().Set("Content-Type", p.ContentType)
+	}
+	// Photos never change once uploaded.
+	response.Header().Set("Cache-Control", "max-age=31536000, immutable")
+	_, err = io.Copy(response, f)
+	if err != nil {
+		return fmt.Errorf("write photo: %w", err)
+	}
+	return nil
+}
+
+// Deletes a photo, and renders the history of its item.
+func handleDeletePhoto(response http.ResponseWriter, req *http.Request) error {
+	wid := currentWardrobe(req.Context())
+	id := strings.Join(req.URL.Query()["id"], "")
+	p, err := store.GetPhoto(req.Context(), persist.GetPhotoParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("get photo %v: %w", id, err)
+	}
+	err = store.DeletePhoto(req.Context(), persist.DeletePhotoParams{ID: id, WID: wid})
+	if err != nil {
+		return fmt.Errorf("delete photo %v: %w", id, err)
+	}
+	removePhotoFiles(strings.TrimSpace(p.ID))
+	return renderHistory(response, req, p.CID)
+}
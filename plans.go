@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// A plan to wear something, along with what it's for. Exactly one of Item and Outfit is set,
+// unless what was planned has since gone.
+type planView struct {
+	persist.PlannedUsage
+	Item   *catalogView
+	Outfit *outfitView
+}
+
+// What the plan is for, e.g the item's title or the outfit's name.
+func (p planView) Title() string {
+	switch {
+	case p.Item != nil:
+		return strings.TrimSpace(p.Item.Title.String)
+	case p.Outfit != nil:
+		return p.Outfit.Name
+	}
+	return "Something deleted"
+}
+
+// A day shown on the calendar.
+type calendarDay struct {
+	Day   time.Time
+	Plans []planView
+	Today bool
+	// Before today, so there's nothing left to plan but what was planned can still be worn.
+	Past bool
+	// In the weeks shown to fill out the month, but not part of it.
+	Outside bool
+}
+
+// A week or a month of plans, along with what can be planned.
+type calendar struct {
+	// "week" or "month".
+	View string
+	// The first day of the week or month shown.
+	Start time.Time
+	// The start of the week or month before and after this one.
+	Prev, Next time.Time
+	Weeks      [][]calendarDay
+	Items      []catalogView
+	Outfits    []outfitView
+	// The secret link calendar apps can subscribe to the plans with.
+	Feed string
+}
+
+// The date of t in its own time zone, as midnight UTC, which is how plans store their day.
+func dayOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// The Monday on or before the day.
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Loads the plans for the week or month around the day.
+func loadCalendar(ctx context.Context, view string, day time.Time) (calendar, error) {
+	wid := currentWardrobe(ctx)
+	c := calendar{View: view}
+	var from, to time.Time
+	if view == "month" {
+		c.Start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		c.Prev, c.Next = c.Start.AddDate(0, -1, 0), c.Start.AddDate(0, 1, 0)
+		from = startOfWeek(c.Start)
+		to = startOfWeek(c.Next.AddDate(0, 0, 6))
+	} else {
+		c.View = "week"
+		c.Start = startOfWeek(day)
+		c.Prev, c.Next = c.Start.AddDate(0, 0, -7), c.Start.AddDate(0, 0, 7)
+		from, to = c.Start, c.Next
+	}
+
+	ps, err := store.ListPlans(ctx, persist.ListPlansParams{WID: wid, FromDay: from, ToDay: to})
+	if err != nil {
+		return c, fmt.Errorf("list plans: %w", err)
+	}
+	pvs, items, outfits, err := planViews(ctx, ps)
+	if err != nil {
+		return c, err
+	}
+	for _, i := range items {
+		if !i.Hidden {
+			c.Items = append(c.Items, i)
+		}
+	}
+	c.Outfits = outfits
+	byDay := make(map[time.Time][]planView)
+	for _, pv := range pvs {
+		d := dayOf(pv.Day)
+		byDay[d] = append(byDay[d], pv)
+	}
+
+	today := dayOf(time.Now())
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 7) {
+		week := make([]calendarDay, 7)
+		for i := range week {
+			day := d.AddDate(0, 0, i)
+			week[i] = calendarDay{
+				Day:     day,
+				Plans:   byDay[day],
+				Today:   day.Equal(today),
+				Past:    day.Before(today),
+				Outside: view == "month" && day.Month() != c.Start.Month(),
+			}
+		}
+		c.Weeks = append(c.Weeks, week)
+	}
+	return c, nil
+}
+
+// Finds what each plan is for, returning the plans along with every item and outfit.
+func planViews(ctx context.Context, ps []persist.PlannedUsage) ([]planView, []catalogView, []outfitView, error) {
+	cs, err := store.ListCatalog(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list catalog: %w", err)
+	}
+	items, err := catalogViews(ctx, cs)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("load catalog details: %w", err)
+	}
+	outfits, err := outfitViews(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	itemsByID := make(map[string]*catalogView)
+	for i := range items {
+		itemsByID[items[i].ID] = &items[i]
+	}
+	outfitsByID := make(map[string]*outfitView)
+	for i := range outfits {
+		outfitsByID[outfits[i].ID] = &outfits[i]
+	}
+	pvs := make([]planView, len(ps))
+	for i, p := range ps {
+		pvs[i] = planView{PlannedUsage: p, Item: itemsByID[p.CID.String], Outfit: outfitsByID[p.OID.String]}
+	}
+	return pvs, items, outfits, nil
+}
+
+// Plans to wear an item or an outfit, written as item:<id> or outfit:<id>, on a day.
+func addPlan(ctx context.Context, day time.Time, what string, note string) error {
+	wid := currentWardrobe(ctx)
+	p := persist.PutPlanParams{ID: uuid.NewString(), WID: wid, Day: day, Note: ns([]string{note})}
+	kind, id, _ := strings.Cut(what, ":")
+	switch kind {
+	case "item":
+		_, err := store.GetCatalog(ctx, persist.GetCatalogParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get catalog %v: %w", id, err)
+		}
+		p.CID = ns([]string{id})
+	case "outfit":
+		_, err := store.GetOutfit(ctx, persist.GetOutfitParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get outfit %v: %w", id, err)
+		}
+		p.OID = ns([]string{id})
+	default:
+		return fmt.Errorf("pick an item or outfit to plan")
+	}
+	err := store.PutPlan(ctx, p)
+	if err != nil {
+		return fmt.Errorf("put plan: %w", err)
+	}
+	return nil
+}
+
+// Executes a transaction which logs a use of what was planned, now, and drops the plan.
+func confirmPlan(ctx context.Context, id string) error {
+	wid := currentWardrobe(ctx)
+	return store.InTx(ctx, func(store Store) error {
+		p, err := store.GetPlan(ctx, persist.GetPlanParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get plan: %w", err)
+		}
+		if p.CID.Valid {
+			err = addUsage(ctx, store, p.CID.String)
+		} else {
+			err = wearOutfit(ctx, store, p.OID.String)
+		}
+		if err != nil {
+			return err
+		}
+		err = store.DeletePlan(ctx, persist.DeletePlanParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("delete plan: %w", err)
+		}
+		return nil
+	})
+}
+
+// Renders the calendar picked by the request's view and date, e.g view=month&date=2022-05-01.
+// Without a date it shows today.
+func renderCalendar(response http.ResponseWriter, req *http.Request) error {
+	day := dayOf(time.Now())
+	if s := req.FormValue("date"); s != "" {
+		d, err := parseDate(s)
+		if err != nil {
+			return err
+		}
+		day = d.Time
+	}
+	c, err := loadCalendar(req.Context(), req.FormValue("view"), day)
+	if err != nil {
+		return err
+	}
+	token, err := calendarFeedToken(req.Context())
+	if err != nil {
+		return err
+	}
+	scheme := "http"
+	if isHTTPS(req) {
+		scheme = "https"
+	}
+	c.Feed = fmt.Sprintf("%v://%v/calendar/%v.ics", scheme, req.Host, token)
+	r, err := renderPlans(c)
+	if err != nil {
+		return fmt.Errorf("render calendar: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleCalendar(response http.ResponseWriter, req *http.Request) error {
+	return renderCalendar(response, req)
+}
+
+// They planned something
+func handlePutPlan(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	day, err := parseDate(req.Form.Get("day"))
+	if err != nil {
+		return err
+	}
+	if !day.Valid {
+		return fmt.Errorf("pick a day to plan for")
+	}
+	err = addPlan(req.Context(), day.Time, req.Form.Get("plan"), req.Form.Get("note"))
+	if err != nil {
+		return fmt.Errorf("add plan: %w", err)
+	}
+	// Show the week or month planned for.
+	req.Form.Set("date", req.Form.Get("day"))
+	return renderCalendar(response, req)
+}
+
+// e.g api/plan/delete?id=...
+func handleDeletePlan(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	err := store.DeletePlan(req.Context(), persist.DeletePlanParams{ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("delete plan %v: %w", id, err)
+	}
+	return renderCalendar(response, req)
+}
+
+// They wore what they planned. e.g api/plan/wear?id=...
+func handleConfirmPlan(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	err := confirmPlan(req.Context(), id)
+	if err != nil {
+		return fmt.Errorf("confirm plan %v: %w", id, err)
+	}
+	return renderCalendar(response, req)
+}
+
+// The token of the user's calendar link for the wardrobe, made the first time it's asked for.
+func calendarFeedToken(ctx context.Context) (string, error) {
+	f, err := store.GetUserCalendarFeed(ctx, persist.GetUserCalendarFeedParams{UID: currentUser(ctx).ID, WID: currentWardrobe(ctx)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return resetCalendarFeed(ctx)
+	} else if err != nil {
+		return "", fmt.Errorf("get calendar feed: %w", err)
+	}
+	return f.Token, nil
+}
+
+// Gives the user a new calendar link for the wardrobe, so the old one stops working.
+func resetCalendarFeed(ctx context.Context) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	err = store.PutCalendarFeed(ctx, persist.PutCalendarFeedParams{
+		Token:   token,
+		UID:     currentUser(ctx).ID,
+		WID:     currentWardrobe(ctx),
+		Created: time.Now().UTC(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("put calendar feed: %w", err)
+	}
+	return token, nil
+}
+
+// They think their calendar link leaked. e.g api/calendar/reset?view=week&date=2022-05-01
+func handleResetCalendarFeed(response http.ResponseWriter, req *http.Request) error {
+	_, err := resetCalendarFeed(req.Context())
+	if err != nil {
+		return err
+	}
+	return renderCalendar(response, req)
+}
+
+// Serves every plan as an iCalendar file, with an all day event for each, to whoever has the
+// secret link. e.g calendar/<token>.ics
+func handleCalendarFeed(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	token := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/calendar/"), ".ics")
+	f, err := store.GetCalendarFeed(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(response, req)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("get calendar feed: %w", err)
+	}
+	// Leaving the wardrobe ends access to its plans.
+	member, err := store.IsWardrobeMember(ctx, persist.IsWardrobeMemberParams{WID: f.WID, UID: f.UID})
+	if err != nil {
+		return fmt.Errorf("check wardrobe membership: %w", err)
+	}
+	if !member {
+		http.NotFound(response, req)
+		return nil
+	}
+	u, err := store.GetUser(ctx, f.UID)
+	if err != nil {
+		return fmt.Errorf("get user %v: %w", f.UID, err)
+	}
+	ctx = withWardrobe(ctx, u, f.WID)
+	ps, err := store.ListAllPlans(ctx, f.WID)
+	if err != nil {
+		return fmt.Errorf("list plans: %w", err)
+	}
+	pvs, _, _, err := planViews(ctx, ps)
+	if err != nil {
+		return err
+	}
+	response.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	response.Header().Set("Content-Disposition", `attachment; filename="plans.ics"`)
+	return writeICS(response, pvs, time.Now().UTC())
+}
+
+func writeICS(w io.Writer, ps []planView, stamp time.Time) error {
+	var b strings.Builder
+	line := func(s string) {
+		// Lines longer than 75 bytes are folded onto lines starting with a space.
+		for len(s) > 75 {
+			cut := 75
+			for cut > 0 && !utf8.RuneStart(s[cut]) {
+				cut--
+			}
+			b.WriteString(s[:cut] + "\r\n")
+			s = " " + s[cut:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//gorgina//plans//EN")
+	line("X-WR-CALNAME:Outfit plans")
+	for _, p := range ps {
+		day := dayOf(p.Day)
+		line("BEGIN:VEVENT")
+		line("UID:" + strings.TrimSpace(p.ID) + "@gorgina")
+		line("DTSTAMP:" + stamp.Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + day.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + icsEscape("Wear "+p.Title()))
+		if p.Note.Valid {
+			line("DESCRIPTION:" + icsEscape(p.Note.String))
+		}
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	if err != nil {
+		return fmt.Errorf("write calendar: %w", err)
+	}
+	return nil
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icsEscape(s string) string {
+	return icsEscaper.Replace(s)
+}
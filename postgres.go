@@ -0,0 +1,24 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hherman1/gorgina/db/persist"
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+// Opens a Postgres backed store.
+func openPostgres(url string) (*sqlStore, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	return &sqlStore{
+		Queries:       persist.New(db),
+		db:            db,
+		name:          "postgres",
+		migrationsDir: "db/migrations/postgres",
+		search:        searchPostgres,
+	}, nil
+}
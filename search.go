@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// A parsed search box query, e.g `black dress brand:uniqlo hidden:true tag:work`.
+//
+// Free words must all match somewhere in the item. The qualifiers brand:, color:, category:,
+// pattern:, material:, season: and retailer: must match within that field, size: and condition:
+// must match it exactly, hidden: picks hidden or visible items, and each tag: must be one of the
+// item's tags. state: picks items in a state, e.g state:laundry, and available:true those which
+// are clean. lifecycle: picks items at a stage of their life, e.g lifecycle:sold. weather:today
+// picks items suited to today's forecast. Values with spaces may be quoted, e.g
+// brand:"banana republic".
+type searchQuery struct {
+	Terms     []string
+	Brand     string
+	Color     string
+	Category  string
+	Pattern   string
+	Size      string
+	Material  string
+	Season    string
+	Retailer  string
+	Condition string
+	// Nil matches both hidden and visible items.
+	Hidden *bool
+	Tags   []string
+	State  string
+	// Nil matches items whatever their state, see available.
+	Available *bool
+	// e.g sold, see lifecycles.
+	Lifecycle string
+	// Only items suited to today's weather. Not part of the SQL query, see searchCatalog.
+	Weather bool
+}
+
+func parseSearch(s string) searchQuery {
+	var q searchQuery
+	for _, tok := range splitSearch(s) {
+		key, val, ok := strings.Cut(tok, ":")
+		if !ok || val == "" {
+			q.Terms = append(q.Terms, tok)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "brand":
+			q.Brand = val
+		case "color", "colour":
+			q.Color = val
+		case "category":
+			q.Category = val
+		case "pattern":
+			q.Pattern = val
+		case "size":
+			q.Size = val
+		case "material", "fabric":
+			q.Material = val
+		case "season":
+			q.Season = val
+		case "retailer", "source":
+			q.Retailer = val
+		case "condition":
+			q.Condition = val
+		case "tag":
+			q.Tags = append(q.Tags, parseTags(val)...)
+		case "weather":
+			if strings.ToLower(val) == "today" {
+				q.Weather = true
+			} else {
+				q.Terms = append(q.Terms, tok)
+			}
+		case "hidden":
+			q.Hidden = parseSearchBool(val)
+		case "state":
+			q.State = val
+		case "available":
+			q.Available = parseSearchBool(val)
+		case "lifecycle":
+			q.Lifecycle = val
+		default:
+			q.Terms = append(q.Terms, tok)
+		}
+	}
+	return q
+}
+
+// Parses the value of a yes or no qualifier, e.g hidden:true. Anything else is nil.
+func parseSearchBool(val string) *bool {
+	var b bool
+	switch strings.ToLower(val) {
+	case "true", "yes":
+		b = true
+	case "false", "no":
+		b = false
+	default:
+		return nil
+	}
+	return &b
+}
+
+// Searches the catalog, then drops items unsuited to today's weather if the query asks for it.
+// Without a weather provider, weather:today matches everything. If the weather can't be fetched
+// it's ignored too, and weatherErr says why.
+func searchCatalog(ctx context.Context, wID string, q searchQuery) (cs []persist.Catalog, weatherErr string, err error) {
+	cs, err = store.SearchCatalog(ctx, wID, q)
+	if err != nil || !q.Weather {
+		return cs, "", err
+	}
+	f, err := todaysWeather(ctx)
+	if err != nil {
+		return cs, err.Error(), nil
+	}
+	if f == nil {
+		return cs, "", nil
+	}
+	var kept []persist.Catalog
+	for _, c := range cs {
+		if suitsWeather(c, *f) {
+			kept = append(kept, c)
+		}
+	}
+	return kept, "", nil
+}
+
+// Splits on whitespace, except within double quotes, which are dropped.
+func splitSearch(s string) []string {
+	var toks []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if cur.Len() > 0 {
+				toks = append(toks, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		toks = append(toks, cur.String())
+	}
+	return toks
+}
+
+// Builds a Postgres tsquery requiring every term, each as a prefix so results
+// narrow as the user types. Characters with meaning to tsquery are dropped.
+func tsquery(terms []string) string {
+	var parts []string
+	for _, t := range terms {
+		for _, w := range strings.FieldsFunc(t, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			parts = append(parts, w+":*")
+		}
+	}
+	return strings.Join(parts, " & ")
+}
+
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{Valid: s != "", String: s}
+}
+
+// Ranked full text search, using the index from the 0020_search_attributes migration.
+func searchPostgres(ctx context.Context, db persist.DBTX, wID string, q searchQuery) ([]persist.Catalog, error) {
+	arg := persist.SearchCatalogParams{
+		WID:       wID,
+		Query:     tsquery(q.Terms),
+		Brand:     nullIfEmpty(q.Brand),
+		Color:     nullIfEmpty(q.Color),
+		Category:  nullIfEmpty(q.Category),
+		Pattern:   nullIfEmpty(q.Pattern),
+		Size:      nullIfEmpty(q.Size),
+		Material:  nullIfEmpty(q.Material),
+		Season:    nullIfEmpty(q.Season),
+		Retailer:  nullIfEmpty(q.Retailer),
+		Condition: nullIfEmpty(q.Condition),
+	}
+	if q.Hidden != nil {
+		arg.Hidden = sql.NullBool{Valid: true, Bool: *q.Hidden}
+	}
+	arg.State = nullIfEmpty(q.State)
+	if q.Available != nil {
+		arg.Available = sql.NullBool{Valid: true, Bool: *q.Available}
+	}
+	arg.Lifecycle = nullIfEmpty(q.Lifecycle)
+	arg.Tags = nullIfEmpty(strings.Join(q.Tags, ","))
+	return persist.New(db).SearchCatalog(ctx, arg)
+}
+
+// Escapes the characters with meaning to LIKE, so they match themselves.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Matches each term as a substring of any field, since SQLite has no equivalent of
+// the Postgres text search. Results are not ranked.
+func searchSQLite(ctx context.Context, db persist.DBTX, wID string, q searchQuery) ([]persist.Catalog, error) {
+	where := []string{"w_id = $1"}
+	args := []interface{}{wID}
+	like := func(format string, val string) {
+		args = append(args, likeEscaper.Replace(val))
+		where = append(where, strings.ReplaceAll(format, "?", fmt.Sprintf(`'%%' || LOWER($%d) || '%%' ESCAPE '\'`, len(args))))
+	}
+	for _, t := range q.Terms {
+		like(`(LOWER(title) LIKE ?
+		OR LOWER(description) LIKE ?
+		OR LOWER(color) LIKE ?
+		OR LOWER(category) LIKE ?
+		OR LOWER(brand) LIKE ?
+		OR LOWER(pattern) LIKE ?
+		OR LOWER(size) LIKE ?
+		OR LOWER(material) LIKE ?
+		OR LOWER(seasons) LIKE ?
+		OR LOWER(retailer) LIKE ?)`, t)
+	}
+	if q.Brand != "" {
+		like("LOWER(brand) LIKE ?", q.Brand)
+	}
+	if q.Color != "" {
+		like("LOWER(color) LIKE ?", q.Color)
+	}
+	if q.Category != "" {
+		like("LOWER(category) LIKE ?", q.Category)
+	}
+	if q.Pattern != "" {
+		like("LOWER(pattern) LIKE ?", q.Pattern)
+	}
+	if q.Size != "" {
+		args = append(args, q.Size)
+		where = append(where, fmt.Sprintf("LOWER(size) = LOWER($%d)", len(args)))
+	}
+	if q.Material != "" {
+		like("LOWER(material) LIKE ?", q.Material)
+	}
+	if q.Season != "" {
+		like("LOWER(seasons) LIKE ?", q.Season)
+	}
+	if q.Retailer != "" {
+		like("LOWER(retailer) LIKE ?", q.Retailer)
+	}
+	if q.Condition != "" {
+		args = append(args, q.Condition)
+		where = append(where, fmt.Sprintf("LOWER(condition) = LOWER($%d)", len(args)))
+	}
+	if q.Hidden != nil {
+		args = append(args, *q.Hidden)
+		where = append(where, fmt.Sprintf("hidden = $%d", len(args)))
+	}
+	if q.State != "" {
+		args = append(args, q.State)
+		where = append(where, fmt.Sprintf("state = LOWER($%d)", len(args)))
+	}
+	if q.Available != nil {
+		args = append(args, *q.Available)
+		where = append(where, fmt.Sprintf("(state = 'clean') = $%d", len(args)))
+	}
+	if q.Lifecycle != "" {
+		args = append(args, q.Lifecycle)
+		where = append(where, fmt.Sprintf("lifecycle = LOWER($%d)", len(args)))
+	}
+	for _, t := range q.Tags {
+		args = append(args, t)
+		where = append(where, fmt.Sprintf("id IN (SELECT catalog_tags.c_id FROM catalog_tags JOIN tags ON tags.id = catalog_tags.t_id WHERE tags.name = $%d)", len(args)))
+	}
+	query := "SELECT id, category, brand, color, pattern, title, description, price, last_activity, last_note, hidden, w_id,"
+	query += " size, material, seasons, purchased, retailer, condition, skip_suggest, min_temp, max_temp, rain_ok,"
+	query += " state, wears_since_clean, disposed, lifecycle, resale_price, lifecycle_note, currency FROM catalog"
+	query += "\nWHERE " + strings.Join(where, "\n\tAND ")
+	query += "\nORDER BY hidden ASC, last_activity DESC NULLS LAST"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []persist.Catalog
+	for rows.Next() {
+		var i persist.Catalog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Category,
+			&i.Brand,
+			&i.Color,
+			&i.Pattern,
+			&i.Title,
+			&i.Description,
+			&i.Price,
+			&i.LastActivity,
+			&i.LastNote,
+			&i.Hidden,
+			&i.WID,
+			&i.Size,
+			&i.Material,
+			&i.Seasons,
+			&i.Purchased,
+			&i.Retailer,
+			&i.Condition,
+			&i.SkipSuggest,
+			&i.MinTemp,
+			&i.MaxTemp,
+			&i.RainOk,
+			&i.State,
+			&i.WearsSinceClean,
+			&i.Disposed,
+			&i.Lifecycle,
+			&i.ResalePrice,
+			&i.LifecycleNote,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
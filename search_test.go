@@ -0,0 +1,54 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Characters with meaning to LIKE match only themselves.
+func TestSearchSQLiteLike(t *testing.T) {
+	ctx := openTestStore(t)
+	wid := currentWardrobe(ctx)
+	for id, title := range map[string]string{
+		"wool":      "100% wool",
+		"blend":     "1000 wool blend",
+		"snake":     "snake_case tee",
+		"dash":      "snake-case tee",
+		"backslash": `back\slash`,
+		"plain":     "backslash",
+	} {
+		_, err := store.PutItem(ctx, persist.PutItemParams{ID: id, WID: wid, Title: sql.NullString{Valid: true, String: title}, Currency: "USD"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		search string
+		want   []string
+	}{
+		{search: "100%", want: []string{"wool"}},
+		{search: "wool", want: []string{"blend", "wool"}},
+		{search: "snake_case", want: []string{"snake"}},
+		{search: `back\slash`, want: []string{"backslash"}},
+		{search: "brand:%", want: nil},
+	} {
+		t.Run(tc.search, func(t *testing.T) {
+			cs, err := store.SearchCatalog(ctx, wid, parseSearch(tc.search))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range cs {
+				got = append(got, c.ID)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hherman1/gorgina/db/persist"
+	_ "modernc.org/sqlite"
+)
+
+// Opens a SQLite backed store in the given file, creating it if necessary.
+func openSQLite(path string) (*sqlStore, error) {
+	dsn := path
+	if !strings.HasPrefix(dsn, "file:") {
+		dsn = "file:" + dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	// Enforce the references between tables, and wait on locks instead of failing.
+	dsn += sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	// SQLite allows a single writer at a time, so serialize access rather than contend for the lock.
+	db.SetMaxOpenConns(1)
+	return &sqlStore{
+		Queries:       persist.New(db),
+		db:            db,
+		name:          "sqlite",
+		migrationsDir: "db/migrations/sqlite",
+		search:        searchSQLite,
+	}, nil
+}
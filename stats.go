@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// How many items to show in the most and least worn lists.
+const statsTopN = 5
+
+// Usage and value for money of a single catalog item.
+type itemStats struct {
+	persist.ItemStatsRow
+	// The price less what the item was sold for.
+	NetCost sql.NullFloat64
+	// The net cost divided by the number of wears, when the item has both.
+	CostPerWear sql.NullFloat64
+}
+
+// What was spent on the items of a category or brand.
+type spendRow struct {
+	Name  string
+	Items int64
+	Spend float64
+}
+
+// Everything shown on the stats component. Totals are in Currency, leaving out prices in the
+// Unrated currencies, while each item's figures are in its own currency.
+type statsView struct {
+	Currency string
+	Unrated  []string
+
+	Items int
+	Wears int64
+	Spend float64
+	// Made back by selling items.
+	Resale float64
+	// Spend less resale.
+	NetCost float64
+	// Net cost divided by total wears.
+	CostPerWear sql.NullFloat64
+
+	// Active items, most worn first.
+	ByItem []itemStats
+	// Items no longer active, e.g sold, most worn first.
+	Gone      []itemStats
+	MostWorn  []itemStats
+	LeastWorn []itemStats
+
+	Months []persist.WearsByMonthRow
+	// Wears in the busiest month, for scaling the chart.
+	MaxMonthWears int64
+
+	Categories []spendRow
+	Brands     []spendRow
+}
+
+// Adds the spend on an item to its row, by name, keeping the rows in the order they were added.
+func addSpend(rows []spendRow, name string, spend float64) []spendRow {
+	name = strings.TrimSpace(name)
+	for i := range rows {
+		if rows[i].Name == name {
+			rows[i].Items++
+			rows[i].Spend += spend
+			return rows
+		}
+	}
+	return append(rows, spendRow{Name: name, Items: 1, Spend: spend})
+}
+
+func loadStats(ctx context.Context) (statsView, error) {
+	wid := currentWardrobe(ctx)
+	var v statsView
+	x, err := loadExchange(ctx)
+	if err != nil {
+		return v, err
+	}
+	rows, err := store.ItemStats(ctx, wid)
+	if err != nil {
+		return v, fmt.Errorf("item stats: %w", err)
+	}
+	for _, r := range rows {
+		v.Items++
+		v.Wears += r.Wears
+		spend := x.toHome(r.Price.Float64, r.Currency)
+		v.Spend += spend
+		v.Resale += x.toHome(r.ResalePrice.Float64, r.Currency)
+		v.Categories = addSpend(v.Categories, r.Category.String, spend)
+		v.Brands = addSpend(v.Brands, r.Brand.String, spend)
+		s := itemStats{ItemStatsRow: r}
+		if r.Price.Valid {
+			s.NetCost = sql.NullFloat64{Valid: true, Float64: r.Price.Float64 - r.ResalePrice.Float64}
+		}
+		if s.NetCost.Valid && r.Wears > 0 {
+			s.CostPerWear = sql.NullFloat64{Valid: true, Float64: s.NetCost.Float64 / float64(r.Wears)}
+		}
+		if r.Lifecycle != lifecycleActive {
+			v.Gone = append(v.Gone, s)
+			continue
+		}
+		v.ByItem = append(v.ByItem, s)
+	}
+	v.Currency, v.Unrated = x.Home, x.Unrated
+	v.NetCost = v.Spend - v.Resale
+	if v.Wears > 0 {
+		v.CostPerWear = sql.NullFloat64{Valid: true, Float64: v.NetCost / float64(v.Wears)}
+	}
+
+	for _, s := range v.ByItem {
+		if len(v.MostWorn) == statsTopN || s.Wears == 0 {
+			break
+		}
+		v.MostWorn = append(v.MostWorn, s)
+	}
+	least := append([]itemStats(nil), v.ByItem...)
+	sort.SliceStable(least, func(i, j int) bool {
+		if least[i].Wears != least[j].Wears {
+			return least[i].Wears < least[j].Wears
+		}
+		return least[i].LastActivity.Time.Before(least[j].LastActivity.Time)
+	})
+	if len(least) > statsTopN {
+		least = least[:statsTopN]
+	}
+	v.LeastWorn = least
+
+	v.Months, err = store.WearsByMonth(ctx, wid)
+	if err != nil {
+		return v, fmt.Errorf("wears by month: %w", err)
+	}
+	for _, m := range v.Months {
+		if m.Wears > v.MaxMonthWears {
+			v.MaxMonthWears = m.Wears
+		}
+	}
+	for _, sp := range [][]spendRow{v.Categories, v.Brands} {
+		sort.SliceStable(sp, func(i, j int) bool { return sp[i].Spend > sp[j].Spend })
+	}
+	return v, nil
+}
+
+// Renders usage and cost-per-wear analytics.
+func handleStats(response http.ResponseWriter, req *http.Request) error {
+	v, err := loadStats(req.Context())
+	if err != nil {
+		return fmt.Errorf("load stats: %w", err)
+	}
+	r, err := renderStats(v)
+	if err != nil {
+		return fmt.Errorf("render stats: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// The storage operations the handlers rely on.
+type Store interface {
+	GetCatalog(ctx context.Context, arg persist.GetCatalogParams) (persist.Catalog, error)
+	CatalogIDTaken(ctx context.Context, id string) (bool, error)
+	ListCatalog(ctx context.Context, wID string) ([]persist.Catalog, error)
+	SearchCatalog(ctx context.Context, wID string, q searchQuery) ([]persist.Catalog, error)
+	PutItem(ctx context.Context, arg persist.PutItemParams) (sql.Result, error)
+	SetLifecycle(ctx context.Context, arg persist.SetLifecycleParams) error
+	SetSkipSuggest(ctx context.Context, arg persist.SetSkipSuggestParams) error
+	SetItemState(ctx context.Context, arg persist.SetItemStateParams) error
+	CountWear(ctx context.Context, arg persist.CountWearParams) error
+	UpdateLastUsed(ctx context.Context, arg persist.UpdateLastUsedParams) (sql.Result, error)
+	UpdateLastNote(ctx context.Context, arg persist.UpdateLastNoteParams) (sql.Result, error)
+	DeleteItem(ctx context.Context, arg persist.DeleteItemParams) error
+
+	GetUsage(ctx context.Context, arg persist.GetUsageParams) (persist.Activity, error)
+	UsageIDTaken(ctx context.Context, id string) (bool, error)
+	GetLastUsage(ctx context.Context, arg persist.GetLastUsageParams) (persist.Activity, error)
+	GetAllUsage(ctx context.Context, arg persist.GetAllUsageParams) ([]persist.Activity, error)
+	ListUsage(ctx context.Context, wID string) ([]persist.Activity, error)
+	LogUsage(ctx context.Context, arg persist.LogUsageParams) (sql.Result, error)
+	SetUsageNote(ctx context.Context, arg persist.SetUsageNoteParams) (sql.Result, error)
+	PutUsage(ctx context.Context, arg persist.PutUsageParams) (sql.Result, error)
+	DeleteUsage(ctx context.Context, arg persist.DeleteUsageParams) error
+	DeleteItemUsage(ctx context.Context, arg persist.DeleteItemUsageParams) error
+
+	AddPhoto(ctx context.Context, arg persist.AddPhotoParams) error
+	GetPhoto(ctx context.Context, arg persist.GetPhotoParams) (persist.ItemPhoto, error)
+	ListPhotos(ctx context.Context, arg persist.ListPhotosParams) ([]persist.ItemPhoto, error)
+	ListAllPhotos(ctx context.Context, wID string) ([]persist.ItemPhoto, error)
+	DeletePhoto(ctx context.Context, arg persist.DeletePhotoParams) error
+	DeleteItemPhotos(ctx context.Context, arg persist.DeleteItemPhotosParams) error
+
+	PutOutfit(ctx context.Context, arg persist.PutOutfitParams) error
+	GetOutfit(ctx context.Context, arg persist.GetOutfitParams) (persist.Outfit, error)
+	ListOutfits(ctx context.Context, wID string) ([]persist.Outfit, error)
+	DeleteOutfit(ctx context.Context, arg persist.DeleteOutfitParams) error
+	AddOutfitItem(ctx context.Context, arg persist.AddOutfitItemParams) error
+	ClearOutfitItems(ctx context.Context, arg persist.ClearOutfitItemsParams) error
+	DeleteItemOutfits(ctx context.Context, arg persist.DeleteItemOutfitsParams) error
+	ListOutfitItems(ctx context.Context, arg persist.ListOutfitItemsParams) ([]persist.OutfitItem, error)
+	ListAllOutfitItems(ctx context.Context, wID string) ([]persist.OutfitItem, error)
+	RefreshOutfitLastUsed(ctx context.Context, arg persist.RefreshOutfitLastUsedParams) error
+
+	LogOutfitUsage(ctx context.Context, arg persist.LogOutfitUsageParams) error
+	LogOutfitItemUsage(ctx context.Context, arg persist.LogOutfitItemUsageParams) error
+	GetOutfitUsage(ctx context.Context, arg persist.GetOutfitUsageParams) (persist.OutfitActivity, error)
+	ListOutfitUsage(ctx context.Context, arg persist.ListOutfitUsageParams) ([]persist.OutfitActivity, error)
+	ListOutfitItemUsage(ctx context.Context, arg persist.ListOutfitItemUsageParams) ([]persist.Activity, error)
+	PutOutfitUsage(ctx context.Context, arg persist.PutOutfitUsageParams) error
+	PutOutfitItemUsage(ctx context.Context, arg persist.PutOutfitItemUsageParams) error
+	DeleteOutfitUsage(ctx context.Context, arg persist.DeleteOutfitUsageParams) error
+	DeleteOutfitItemUsage(ctx context.Context, arg persist.DeleteOutfitItemUsageParams) error
+	DeleteAllOutfitUsage(ctx context.Context, arg persist.DeleteAllOutfitUsageParams) error
+	DetachOutfitUsage(ctx context.Context, arg persist.DetachOutfitUsageParams) error
+
+	ItemStats(ctx context.Context, wID string) ([]persist.ItemStatsRow, error)
+	WearsByMonth(ctx context.Context, wID string) ([]persist.WearsByMonthRow, error)
+
+	CreateUser(ctx context.Context, arg persist.CreateUserParams) error
+	GetUser(ctx context.Context, id string) (persist.User, error)
+	GetUserByName(ctx context.Context, username string) (persist.User, error)
+	CountUsers(ctx context.Context) (int64, error)
+	SetPassword(ctx context.Context, arg persist.SetPasswordParams) error
+	CreateSession(ctx context.Context, arg persist.CreateSessionParams) error
+	GetSession(ctx context.Context, id string) (persist.Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	DeleteUserSessions(ctx context.Context, uID string) error
+	DeleteExpiredSessions(ctx context.Context, expires time.Time) error
+	SetSessionWardrobe(ctx context.Context, arg persist.SetSessionWardrobeParams) error
+
+	CreateWardrobe(ctx context.Context, arg persist.CreateWardrobeParams) error
+	RenameWardrobe(ctx context.Context, arg persist.RenameWardrobeParams) error
+	GetWardrobe(ctx context.Context, id string) (persist.Wardrobe, error)
+	ListUserWardrobes(ctx context.Context, uID string) ([]persist.Wardrobe, error)
+	AddWardrobeMember(ctx context.Context, arg persist.AddWardrobeMemberParams) error
+	RemoveWardrobeMember(ctx context.Context, arg persist.RemoveWardrobeMemberParams) error
+	IsWardrobeMember(ctx context.Context, arg persist.IsWardrobeMemberParams) (bool, error)
+	ListWardrobeMembers(ctx context.Context, wID string) ([]persist.ListWardrobeMembersRow, error)
+	ClaimOrphanWardrobes(ctx context.Context, uID string) error
+
+	SetWardrobeCurrency(ctx context.Context, arg persist.SetWardrobeCurrencyParams) error
+	PutRate(ctx context.Context, arg persist.PutRateParams) error
+	ListRates(ctx context.Context, wID string) ([]persist.ExchangeRate, error)
+	DeleteRate(ctx context.Context, arg persist.DeleteRateParams) error
+	DeleteAllRates(ctx context.Context, wID string) error
+	ConvertBudgets(ctx context.Context, arg persist.ConvertBudgetsParams) error
+	ConvertWishPrices(ctx context.Context, arg persist.ConvertWishPricesParams) error
+
+	CreateTag(ctx context.Context, arg persist.CreateTagParams) error
+	GetTagByName(ctx context.Context, arg persist.GetTagByNameParams) (persist.Tag, error)
+	ListTags(ctx context.Context, wID string) ([]persist.Tag, error)
+	AddItemTag(ctx context.Context, arg persist.AddItemTagParams) error
+	ClearItemTags(ctx context.Context, arg persist.ClearItemTagsParams) error
+	ListItemTags(ctx context.Context, arg persist.ListItemTagsParams) ([]string, error)
+	ListAllItemTags(ctx context.Context, wID string) ([]persist.ListAllItemTagsRow, error)
+	ListAllCatalogTags(ctx context.Context, wID string) ([]persist.CatalogTag, error)
+	DeleteUnusedTags(ctx context.Context, wID string) error
+
+	ListCategories(ctx context.Context, wID string) ([]persist.Category, error)
+	CreateCategory(ctx context.Context, arg persist.CreateCategoryParams) error
+	UpdateCategory(ctx context.Context, arg persist.UpdateCategoryParams) (sql.Result, error)
+	DeleteCategory(ctx context.Context, arg persist.DeleteCategoryParams) error
+	DeleteAllCategories(ctx context.Context, wID string) error
+	RenameItemCategory(ctx context.Context, arg persist.RenameItemCategoryParams) error
+	CountCategoryItems(ctx context.Context, arg persist.CountCategoryItemsParams) (int64, error)
+	GetCategory(ctx context.Context, arg persist.GetCategoryParams) (persist.Category, error)
+
+	LogStateChange(ctx context.Context, arg persist.LogStateChangeParams) error
+	ListStateChanges(ctx context.Context, arg persist.ListStateChangesParams) ([]persist.StateChange, error)
+	ListAllStateChanges(ctx context.Context, wID string) ([]persist.StateChange, error)
+	DeleteItemStateChanges(ctx context.Context, arg persist.DeleteItemStateChangesParams) error
+
+	PutPlan(ctx context.Context, arg persist.PutPlanParams) error
+	GetPlan(ctx context.Context, arg persist.GetPlanParams) (persist.PlannedUsage, error)
+	ListPlans(ctx context.Context, arg persist.ListPlansParams) ([]persist.PlannedUsage, error)
+	ListAllPlans(ctx context.Context, wID string) ([]persist.PlannedUsage, error)
+	DeletePlan(ctx context.Context, arg persist.DeletePlanParams) error
+	DeleteItemPlans(ctx context.Context, arg persist.DeleteItemPlansParams) error
+	DeleteOutfitPlans(ctx context.Context, arg persist.DeleteOutfitPlansParams) error
+	GetCalendarFeed(ctx context.Context, token string) (persist.CalendarFeed, error)
+	GetUserCalendarFeed(ctx context.Context, arg persist.GetUserCalendarFeedParams) (persist.CalendarFeed, error)
+	PutCalendarFeed(ctx context.Context, arg persist.PutCalendarFeedParams) error
+
+	LendItem(ctx context.Context, arg persist.LendItemParams) error
+	GetLoan(ctx context.Context, arg persist.GetLoanParams) (persist.Loan, error)
+	ReturnItemLoans(ctx context.Context, arg persist.ReturnItemLoansParams) error
+	ListOpenLoans(ctx context.Context, wID string) ([]persist.Loan, error)
+	ListLoans(ctx context.Context, wID string) ([]persist.Loan, error)
+	ListItemLoans(ctx context.Context, arg persist.ListItemLoansParams) ([]persist.Loan, error)
+	DeleteItemLoans(ctx context.Context, arg persist.DeleteItemLoansParams) error
+
+	ListPurchases(ctx context.Context, arg persist.ListPurchasesParams) ([]persist.Catalog, error)
+
+	PutWish(ctx context.Context, arg persist.PutWishParams) error
+	GetWish(ctx context.Context, arg persist.GetWishParams) (persist.Wish, error)
+	ListWishlist(ctx context.Context, wID string) ([]persist.Wish, error)
+	MarkWishBought(ctx context.Context, arg persist.MarkWishBoughtParams) error
+	DeleteWish(ctx context.Context, arg persist.DeleteWishParams) error
+	ForgetItemWishes(ctx context.Context, arg persist.ForgetItemWishesParams) error
+
+	ListAllOutfitUsage(ctx context.Context, wID string) ([]persist.OutfitActivity, error)
+	RestoreItem(ctx context.Context, arg persist.RestoreItemParams) error
+	RestoreUsage(ctx context.Context, arg persist.RestoreUsageParams) error
+	RestoreOutfit(ctx context.Context, arg persist.RestoreOutfitParams) error
+	RestoreOutfitUsage(ctx context.Context, arg persist.RestoreOutfitUsageParams) error
+	RestoreLoan(ctx context.Context, arg persist.RestoreLoanParams) error
+	RestoreWish(ctx context.Context, arg persist.RestoreWishParams) error
+
+	// Runs f against a store bound to a single transaction, which is committed if f succeeds
+	// and rolled back otherwise. Calling InTx on the store passed to f reuses the transaction.
+	InTx(ctx context.Context, f func(Store) error) error
+}
+
+// A Store on top of database/sql. The queries in db/query.sql are written to run on
+// every backend, so the backends only differ in how they connect, their schema migrations,
+// and catalog search.
+type sqlStore struct {
+	*persist.Queries
+	db *sql.DB
+	tx *sql.Tx
+
+	// Name of this backend, for messages.
+	name string
+	// Directory of this backend's migrations inside migrationFiles.
+	migrationsDir string
+	// This backend's implementation of SearchCatalog.
+	search func(ctx context.Context, db persist.DBTX, wID string, q searchQuery) ([]persist.Catalog, error)
+}
+
+func (s *sqlStore) SearchCatalog(ctx context.Context, wID string, q searchQuery) ([]persist.Catalog, error) {
+	if s.tx != nil {
+		return s.search(ctx, s.tx, wID, q)
+	}
+	return s.search(ctx, s.db, wID, q)
+}
+
+func (s *sqlStore) InTx(ctx context.Context, f func(Store) error) error {
+	if s.tx != nil {
+		return f(s)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("open transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	txs := *s
+	txs.Queries = s.Queries.WithTx(tx)
+	txs.tx = tx
+	err = f(&txs)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Loads the migrations for this backend.
+func (s *sqlStore) migrations() ([]migration, error) {
+	ms, err := loadMigrations(migrationFiles, s.migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("load %v migrations: %w", s.name, err)
+	}
+	return ms, nil
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// Opens the store for the given database url, picking the backend by its scheme:
+//
+//	postgres://... or postgresql://...  Postgres
+//	sqlite:path/to/file.db or file:...  SQLite
+//
+// Anything else is handed to Postgres, which also accepts "key=value" connection strings.
+func openStore(url string) (*sqlStore, error) {
+	switch {
+	case strings.HasPrefix(url, "sqlite:"):
+		path := strings.TrimPrefix(strings.TrimPrefix(url, "sqlite:"), "//")
+		return openSQLite(path)
+	case strings.HasPrefix(url, "file:"):
+		return openSQLite(url)
+	default:
+		return openPostgres(url)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// The outfits suggestions are made of, as an item for each slot. Items fill the slot of their
+// category.
+var outfitShapes = [][]string{
+	{slotTop, slotBottom, slotShoes},
+	{slotDress, slotShoes},
+}
+
+const (
+	// Items last worn this long ago are as good a pick as those never worn.
+	restPeriod = 30 * 24 * time.Hour
+	// Usages this close together are taken to be worn together.
+	togetherWindow = 4 * time.Hour
+	// How many of the freshest items of each slot are tried in outfits.
+	maxCandidates = 12
+	// How much weight having been worn together before carries, next to freshness.
+	togetherWeight = 0.25
+)
+
+// A suggested outfit.
+type suggestion struct {
+	Items []catalogView
+	// Higher is better.
+	Score float64
+	// How many pairs of the items have been worn together before.
+	Together int
+}
+
+// Suggestions for what to wear next.
+type suggestions struct {
+	Outfits []suggestion
+	// The freshest items on their own, whatever their category.
+	Items []catalogView
+	// Items the user asked never to have suggested.
+	Skipped []catalogView
+	// The weather the suggestions are for, if known.
+	Weather *forecast
+	// Why the weather isn't known, when a provider is configured but failed.
+	WeatherErr string
+}
+
+// Between 0, for worn just now, and 1, for rested or never worn.
+func freshness(c persist.Catalog, now time.Time) float64 {
+	if !c.LastActivity.Valid {
+		return 1
+	}
+	rest := now.Sub(c.LastActivity.Time)
+	if rest >= restPeriod {
+		return 1
+	}
+	if rest < 0 {
+		return 0
+	}
+	return float64(rest) / float64(restPeriod)
+}
+
+func pairKey(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// Counts how often each pair of items has been worn together.
+func wornTogether(as []persist.Activity) map[[2]string]int {
+	sort.Slice(as, func(i, j int) bool { return as[i].Ts.Before(as[j].Ts) })
+	pairs := make(map[[2]string]int)
+	for i := range as {
+		for j := i + 1; j < len(as) && as[j].Ts.Sub(as[i].Ts) <= togetherWindow; j++ {
+			if as[i].CID != as[j].CID {
+				pairs[pairKey(as[i].CID, as[j].CID)]++
+			}
+		}
+	}
+	return pairs
+}
+
+// Suggests up to n outfits and n items from the current wardrobe, favoring what hasn't been
+// worn lately, and outfits whose items have been worn together before. Hidden, skipped and
+// unavailable items are left out, as are any which keep returns false for. No item is in more
+// than one outfit.
+func suggest(ctx context.Context, n int, keep func(persist.Catalog) bool) (suggestions, error) {
+	wid := currentWardrobe(ctx)
+	var res suggestions
+	cs, err := store.ListCatalog(ctx, wid)
+	if err != nil {
+		return res, fmt.Errorf("list catalog: %w", err)
+	}
+	as, err := store.ListUsage(ctx, wid)
+	if err != nil {
+		return res, fmt.Errorf("list usage: %w", err)
+	}
+	vs, err := catalogViews(ctx, cs)
+	if err != nil {
+		return res, fmt.Errorf("load catalog details: %w", err)
+	}
+	cats, err := store.ListCategories(ctx, wid)
+	if err != nil {
+		return res, fmt.Errorf("list categories: %w", err)
+	}
+	slotOf := make(map[string]string, len(cats))
+	for _, c := range cats {
+		slotOf[c.Name] = c.Slot
+	}
+	now := time.Now()
+	together := wornTogether(as)
+
+	var candidates []catalogView
+	for _, v := range vs {
+		switch {
+		case v.Hidden:
+		case v.SkipSuggest:
+			res.Skipped = append(res.Skipped, v)
+		case !available(v.Catalog):
+		case keep == nil || keep(v.Catalog):
+			candidates = append(candidates, v)
+		}
+	}
+	// Freshest first, then the longest since worn, so the order is stable.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		fi, fj := freshness(candidates[i].Catalog, now), freshness(candidates[j].Catalog, now)
+		if fi != fj {
+			return fi > fj
+		}
+		return candidates[i].LastActivity.Time.Before(candidates[j].LastActivity.Time)
+	})
+	for i := 0; i < n && i < len(candidates); i++ {
+		res.Items = append(res.Items, candidates[i])
+	}
+
+	bySlot := make(map[string][]catalogView)
+	for _, c := range candidates {
+		slot := slotOf[strings.TrimSpace(c.Category.String)]
+		if slot != "" && len(bySlot[slot]) < maxCandidates {
+			bySlot[slot] = append(bySlot[slot], c)
+		}
+	}
+	var all []suggestion
+	for _, shape := range outfitShapes {
+		picks := make([][]catalogView, len(shape))
+		for i, slot := range shape {
+			picks[i] = bySlot[slot]
+		}
+		combine(picks, nil, func(items []catalogView) {
+			all = append(all, scoreOutfit(items, together, now))
+		})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Score > all[j].Score })
+	used := make(map[string]bool)
+	for _, s := range all {
+		if len(res.Outfits) >= n {
+			break
+		}
+		free := true
+		for _, i := range s.Items {
+			free = free && !used[i.ID]
+		}
+		if !free {
+			continue
+		}
+		for _, i := range s.Items {
+			used[i.ID] = true
+		}
+		res.Outfits = append(res.Outfits, s)
+	}
+	return res, nil
+}
+
+// Calls f with every way of picking one item from each slot.
+func combine(slots [][]catalogView, picked []catalogView, f func([]catalogView)) {
+	if len(slots) == 0 {
+		f(append([]catalogView{}, picked...))
+		return
+	}
+	for _, item := range slots[0] {
+		combine(slots[1:], append(picked, item), f)
+	}
+}
+
+func scoreOutfit(items []catalogView, together map[[2]string]int, now time.Time) suggestion {
+	s := suggestion{Items: items}
+	var fresh float64
+	pairs := 0
+	for i, a := range items {
+		fresh += freshness(a.Catalog, now)
+		for _, b := range items[i+1:] {
+			pairs++
+			if together[pairKey(a.ID, b.ID)] > 0 {
+				s.Together++
+			}
+		}
+	}
+	s.Score = fresh / float64(len(items))
+	if pairs > 0 {
+		s.Score += togetherWeight * float64(s.Together) / float64(pairs)
+	}
+	return s
+}
+
+// Logs a use of each of the items at the same moment, e.g when a suggestion is worn.
+func wearItems(ctx context.Context, ids []string) error {
+	return store.InTx(ctx, func(store Store) error {
+		t := time.Now().UTC()
+		for _, id := range ids {
+			err := logWear(ctx, store, id, t, sql.NullString{})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Suggests n outfits and items suited to today's weather. If the weather can't be fetched
+// the suggestions ignore it, and say why.
+func suggestForWeather(ctx context.Context, n int) (suggestions, error) {
+	f, werr := todaysWeather(ctx)
+	var keep func(persist.Catalog) bool
+	if f != nil {
+		keep = func(c persist.Catalog) bool { return suitsWeather(c, *f) }
+	}
+	s, err := suggest(ctx, n, keep)
+	if err != nil {
+		return s, err
+	}
+	s.Weather = f
+	if werr != nil {
+		s.WeatherErr = werr.Error()
+	}
+	return s, nil
+}
+
+func renderSuggestions(response http.ResponseWriter, req *http.Request) error {
+	s, err := suggestForWeather(req.Context(), 5)
+	if err != nil {
+		return fmt.Errorf("suggest: %w", err)
+	}
+	r, err := listSuggestions(s)
+	if err != nil {
+		return fmt.Errorf("render suggestions: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleSuggest(response http.ResponseWriter, req *http.Request) error {
+	return renderSuggestions(response, req)
+}
+
+// Wears every item of a suggestion. e.g api/suggest/wear?item=...&item=...
+func handleWearSuggestion(response http.ResponseWriter, req *http.Request) error {
+	ids := req.URL.Query()["item"]
+	if len(ids) == 0 {
+		return fmt.Errorf("no items to wear")
+	}
+	err := wearItems(req.Context(), ids)
+	if err != nil {
+		return fmt.Errorf("wear suggestion: %w", err)
+	}
+	return renderSuggestions(response, req)
+}
+
+// Pins an item out of suggestions, or back in. e.g api/suggest/skip?id=...&skip=true
+func handleSkipSuggest(response http.ResponseWriter, req *http.Request) error {
+	id := strings.Join(req.URL.Query()["id"], "")
+	skip := strings.Join(req.URL.Query()["skip"], "") == "true"
+	err := store.SetSkipSuggest(req.Context(), persist.SetSkipSuggestParams{SkipSuggest: skip, ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("set %v skipped (%v): %w", id, skip, err)
+	}
+	return renderSuggestions(response, req)
+}
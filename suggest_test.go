@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Adds an item to a category of the wardrobe.
+func putTestItem(t *testing.T, ctx context.Context, id, category string) {
+	t.Helper()
+	_, err := store.PutItem(ctx, persist.PutItemParams{
+		ID:       id,
+		WID:      currentWardrobe(ctx),
+		Category: sql.NullString{Valid: category != "", String: category},
+		Currency: "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Logs a wear of each of the items, at the given time.
+func wearTestItems(t *testing.T, ctx context.Context, at time.Time, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		err := logWear(ctx, store, id, at, sql.NullString{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	day := 24 * time.Hour
+	for _, tc := range []struct {
+		name string
+		// Run after adding t1, b1, d1 and s1, never worn, s2, worn yesterday, and an accessory.
+		setup       func(t *testing.T, ctx context.Context)
+		wantOutfits [][]string
+		wantSkipped []string
+	}{
+		{
+			name:        "shapes",
+			setup:       func(t *testing.T, ctx context.Context) {},
+			wantOutfits: [][]string{{"t1", "b1", "s1"}, {"d1", "s2"}},
+		},
+		{
+			name: "skipped items",
+			setup: func(t *testing.T, ctx context.Context) {
+				err := store.SetSkipSuggest(ctx, persist.SetSkipSuggestParams{SkipSuggest: true, ID: "s1", WID: currentWardrobe(ctx)})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantOutfits: [][]string{{"t1", "b1", "s2"}},
+			wantSkipped: []string{"s1"},
+		},
+		{
+			name: "unavailable items",
+			setup: func(t *testing.T, ctx context.Context) {
+				err := store.SetItemState(ctx, persist.SetItemStateParams{State: stateWorn, ID: "s1", WID: currentWardrobe(ctx)})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantOutfits: [][]string{{"t1", "b1", "s2"}},
+		},
+		{
+			name: "category renamed, keeping its slot",
+			setup: func(t *testing.T, ctx context.Context) {
+				wid := currentWardrobe(ctx)
+				_, err := store.UpdateCategory(ctx, persist.UpdateCategoryParams{WID: wid, OldName: "tops", Name: "oberteile", Icon: "👚", Slot: slotTop})
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = store.PutItem(ctx, persist.PutItemParams{ID: "t1", WID: wid, Category: sql.NullString{Valid: true, String: "oberteile"}, Currency: "USD"})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantOutfits: [][]string{{"t1", "b1", "s1"}, {"d1", "s2"}},
+		},
+		{
+			name: "worn together before",
+			setup: func(t *testing.T, ctx context.Context) {
+				// Long enough ago that t1 is as fresh as t2, which would otherwise come first.
+				putTestItem(t, ctx, "t2", "tops")
+				wearTestItems(t, ctx, time.Now().Add(-40*day), "t1", "b1", "s1")
+			},
+			wantOutfits: [][]string{{"t1", "b1", "s1"}, {"d1", "s2"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := openTestStore(t)
+			for id, category := range map[string]string{"t1": "tops", "b1": "bottoms", "d1": "dresses", "s1": "shoes", "s2": "shoes", "a1": "accessories"} {
+				putTestItem(t, ctx, id, category)
+			}
+			wearTestItems(t, ctx, time.Now().Add(-day), "s2")
+			tc.setup(t, ctx)
+
+			res, err := suggest(ctx, 5, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var outfits [][]string
+			for _, o := range res.Outfits {
+				var ids []string
+				for _, i := range o.Items {
+					ids = append(ids, i.ID)
+				}
+				outfits = append(outfits, ids)
+			}
+			if !reflect.DeepEqual(outfits, tc.wantOutfits) {
+				t.Fatalf("got outfits %v, want %v", outfits, tc.wantOutfits)
+			}
+			var skipped []string
+			for _, i := range res.Skipped {
+				skipped = append(skipped, i.ID)
+			}
+			if !reflect.DeepEqual(skipped, tc.wantSkipped) {
+				t.Fatalf("got skipped %v, want %v", skipped, tc.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestScoreOutfit(t *testing.T) {
+	now := time.Now()
+	item := func(id string, rested time.Duration) catalogView {
+		c := persist.Catalog{ID: id}
+		if rested >= 0 {
+			c.LastActivity = sql.NullTime{Valid: true, Time: now.Add(-rested)}
+		}
+		return catalogView{Catalog: c}
+	}
+	for _, tc := range []struct {
+		name         string
+		items        []catalogView
+		together     map[[2]string]int
+		wantScore    float64
+		wantTogether int
+	}{
+		{name: "never worn", items: []catalogView{item("a", -1), item("b", -1)}, wantScore: 1},
+		{name: "rested", items: []catalogView{item("a", restPeriod), item("b", 2*restPeriod)}, wantScore: 1},
+		{name: "just worn", items: []catalogView{item("a", 0), item("b", -1)}, wantScore: 0.5},
+		{name: "half rested", items: []catalogView{item("a", restPeriod/2)}, wantScore: 0.5},
+		{
+			name:         "worn together",
+			items:        []catalogView{item("a", -1), item("b", -1), item("c", -1)},
+			together:     map[[2]string]int{pairKey("b", "a"): 2},
+			wantScore:    1 + togetherWeight/3,
+			wantTogether: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := scoreOutfit(tc.items, tc.together, now)
+			if !near(s.Score, tc.wantScore) || s.Together != tc.wantTogether {
+				t.Fatalf("got score %v with %v pairs worn together, want %v with %v", s.Score, s.Together, tc.wantScore, tc.wantTogether)
+			}
+		})
+	}
+}
+
+func TestWornTogether(t *testing.T) {
+	at := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	as := []persist.Activity{
+		{CID: "coat", Ts: at.Add(togetherWindow + time.Hour)},
+		{CID: "shirt", Ts: at},
+		{CID: "jeans", Ts: at.Add(time.Hour)},
+		{CID: "shirt", Ts: at.Add(2 * time.Hour)},
+	}
+	got := wornTogether(as)
+	want := map[[2]string]int{
+		pairKey("shirt", "jeans"): 2,
+		pairKey("jeans", "coat"):  1,
+		pairKey("shirt", "coat"):  1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
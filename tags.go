@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Splits a comma separated list of tags, e.g "Work, summer", into their lower case names.
+func parseTags(s string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// Replaces the tags of a catalog item, creating any which are new to the wardrobe and deleting
+// any no longer in use. Should run in a transaction.
+func setItemTags(ctx context.Context, store Store, cid string, tags []string) error {
+	wid := currentWardrobe(ctx)
+	err := store.ClearItemTags(ctx, persist.ClearItemTagsParams{ID: cid, WID: wid})
+	if err != nil {
+		return fmt.Errorf("clear tags: %w", err)
+	}
+	for _, name := range tags {
+		err = store.CreateTag(ctx, persist.CreateTagParams{ID: uuid.NewString(), Name: name, WID: wid})
+		if err != nil {
+			return fmt.Errorf("create tag %v: %w", name, err)
+		}
+		t, err := store.GetTagByName(ctx, persist.GetTagByNameParams{Name: name, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get tag %v: %w", name, err)
+		}
+		err = store.AddItemTag(ctx, persist.AddItemTagParams{CID: cid, TID: t.ID})
+		if err != nil {
+			return fmt.Errorf("tag %v: %w", name, err)
+		}
+	}
+	err = store.DeleteUnusedTags(ctx, wid)
+	if err != nil {
+		return fmt.Errorf("delete unused tags: %w", err)
+	}
+	return nil
+}
+
+// The tag names of every item in the current wardrobe, by item id.
+func itemTags(ctx context.Context) (map[string][]string, error) {
+	rs, err := store.ListAllItemTags(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	tags := make(map[string][]string)
+	for _, r := range rs {
+		tags[r.CID] = append(tags[r.CID], r.Name)
+	}
+	return tags, nil
+}
+
+// The names of every tag in the current wardrobe, for suggestions.
+func tagNames(ctx context.Context) ([]string, error) {
+	ts, err := store.ListTags(ctx, currentWardrobe(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		names[i] = t.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// Creates a wardrobe with the user as its only member, and the default categories.
+func createWardrobe(ctx context.Context, store Store, uid string, name string) (persist.Wardrobe, error) {
+	w := persist.Wardrobe{
+		ID:      uuid.NewString(),
+		Name:    name,
+		Created: time.Now().UTC(),
+	}
+	err := store.CreateWardrobe(ctx, persist.CreateWardrobeParams{ID: w.ID, Name: w.Name, Created: w.Created})
+	if err != nil {
+		return w, fmt.Errorf("create wardrobe: %w", err)
+	}
+	err = store.AddWardrobeMember(ctx, persist.AddWardrobeMemberParams{WID: w.ID, UID: uid})
+	if err != nil {
+		return w, fmt.Errorf("add wardrobe member: %w", err)
+	}
+	err = addDefaultCategories(ctx, store, w.ID)
+	if err != nil {
+		return w, err
+	}
+	return w, nil
+}
+
+// Fails unless the signed in user belongs to the wardrobe.
+func checkMember(ctx context.Context, wid string) error {
+	member, err := store.IsWardrobeMember(ctx, persist.IsWardrobeMemberParams{WID: wid, UID: currentUser(ctx).ID})
+	if err != nil {
+		return fmt.Errorf("check wardrobe membership: %w", err)
+	}
+	if !member {
+		return fmt.Errorf("not a member of wardrobe %v", wid)
+	}
+	return nil
+}
+
+// Renders the wardrobes of the signed in user, with their members.
+func renderWardrobes(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	ws, err := store.ListUserWardrobes(ctx, currentUser(ctx).ID)
+	if err != nil {
+		return fmt.Errorf("list wardrobes: %w", err)
+	}
+	vs := make([]wardrobeView, len(ws))
+	for i, w := range ws {
+		ms, err := store.ListWardrobeMembers(ctx, w.ID)
+		if err != nil {
+			return fmt.Errorf("list members of %v: %w", w.ID, err)
+		}
+		vs[i] = wardrobeView{Wardrobe: w, Members: ms, Current: w.ID == currentWardrobe(ctx)}
+	}
+	r, err := listWardrobes(vs)
+	if err != nil {
+		return fmt.Errorf("render wardrobes: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleWardrobes(response http.ResponseWriter, req *http.Request) error {
+	return renderWardrobes(response, req)
+}
+
+// Moves the session into another of the user's wardrobes, and reloads the page. e.g api/wardrobe/switch?id=...
+func handleSwitchWardrobe(response http.ResponseWriter, req *http.Request) error {
+	id := strings.Join(req.URL.Query()["id"], "")
+	err := checkMember(req.Context(), id)
+	if err != nil {
+		return err
+	}
+	err = store.SetSessionWardrobe(req.Context(), persist.SetSessionWardrobeParams{WID: id, ID: currentSession(req.Context()).ID})
+	if err != nil {
+		return fmt.Errorf("set session wardrobe: %w", err)
+	}
+	response.Header().Set("HX-Refresh", "true")
+	return nil
+}
+
+// Creates a new wardrobe, e.g for a household, or renames one when given an id.
+func handlePutWardrobe(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	name := strings.TrimSpace(req.Form.Get("name"))
+	if name == "" {
+		return fmt.Errorf("wardrobe name is required")
+	}
+	if id := req.Form.Get("id"); id != "" {
+		err = checkMember(req.Context(), id)
+		if err != nil {
+			return err
+		}
+		err = store.RenameWardrobe(req.Context(), persist.RenameWardrobeParams{Name: name, ID: id})
+		if err != nil {
+			return fmt.Errorf("rename wardrobe: %w", err)
+		}
+		return renderWardrobes(response, req)
+	}
+	err = store.InTx(req.Context(), func(store Store) error {
+		_, err := createWardrobe(req.Context(), store, currentUser(req.Context()).ID, name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return renderWardrobes(response, req)
+}
+
+// Shares a wardrobe with another account. e.g api/wardrobe/member/add?id=...&username=...
+func handleAddWardrobeMember(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	username := strings.TrimSpace(req.FormValue("username"))
+	err := checkMember(req.Context(), id)
+	if err != nil {
+		return err
+	}
+	u, err := store.GetUserByName(req.Context(), username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no such user %v", username)
+	} else if err != nil {
+		return fmt.Errorf("get user %v: %w", username, err)
+	}
+	err = store.AddWardrobeMember(req.Context(), persist.AddWardrobeMemberParams{WID: id, UID: u.ID})
+	if err != nil {
+		return fmt.Errorf("add wardrobe member: %w", err)
+	}
+	return renderWardrobes(response, req)
+}
+
+// Removes the signed in user from a wardrobe. The last member can't leave, so that
+// nothing is left without an owner.
+func handleLeaveWardrobe(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	id := strings.Join(req.URL.Query()["id"], "")
+	err := checkMember(ctx, id)
+	if err != nil {
+		return err
+	}
+	ms, err := store.ListWardrobeMembers(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list members: %w", err)
+	}
+	if len(ms) < 2 {
+		return fmt.Errorf("can't leave a wardrobe you're the only member of")
+	}
+	err = store.RemoveWardrobeMember(ctx, persist.RemoveWardrobeMemberParams{WID: id, UID: currentUser(ctx).ID})
+	if err != nil {
+		return fmt.Errorf("remove wardrobe member: %w", err)
+	}
+	if id == currentWardrobe(ctx) {
+		// The session moves to another wardrobe on the next request.
+		response.Header().Set("HX-Refresh", "true")
+		return nil
+	}
+	return renderWardrobes(response, req)
+}
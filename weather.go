@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+const (
+	// Path of a JSON forecast to use instead of a weather service, e.g {"low": 8, "high": 15, "rain": true}.
+	// It's read on every request, so it can be edited while running.
+	weatherFileKey = "WEATHER_FILE"
+	// Where to fetch the forecast for from Open-Meteo, as latitude,longitude, e.g 52.52,13.41.
+	weatherLocationKey = "WEATHER_LOCATION"
+)
+
+// The weather, when configured. Nil otherwise.
+var weather WeatherProvider
+
+// Today's weather, in °C.
+type forecast struct {
+	Low     float64 `json:"low"`
+	High    float64 `json:"high"`
+	Rain    bool    `json:"rain"`
+	Summary string  `json:"summary"`
+}
+
+// Where suggestions get the weather from.
+type WeatherProvider interface {
+	Forecast(ctx context.Context) (forecast, error)
+}
+
+// Picks the weather provider configured by the environment, if any.
+func weatherFromEnv() (WeatherProvider, error) {
+	if path := os.Getenv(weatherFileKey); path != "" {
+		return fileWeather{path: path}, nil
+	}
+	if loc := os.Getenv(weatherLocationKey); loc != "" {
+		lat, lon, ok := strings.Cut(loc, ",")
+		if !ok {
+			return nil, fmt.Errorf("parse %v %q: expected latitude,longitude", weatherLocationKey, loc)
+		}
+		return &httpWeather{
+			url:    fmt.Sprintf(openMeteoURL, strings.TrimSpace(lat), strings.TrimSpace(lon)),
+			client: &http.Client{Timeout: 5 * time.Second},
+		}, nil
+	}
+	return nil, nil
+}
+
+// Reads the forecast from a JSON file, for use offline or in tests.
+type fileWeather struct {
+	path string
+}
+
+func (w fileWeather) Forecast(ctx context.Context) (forecast, error) {
+	var f forecast
+	bs, err := os.ReadFile(w.path)
+	if err != nil {
+		return f, fmt.Errorf("read forecast: %w", err)
+	}
+	err = json.Unmarshal(bs, &f)
+	if err != nil {
+		return f, fmt.Errorf("parse forecast %v: %w", w.path, err)
+	}
+	if f.Summary == "" {
+		f.Summary = describeWeather(f)
+	}
+	return f, nil
+}
+
+// Today's forecast from Open-Meteo, which needs no account.
+const openMeteoURL = "https://api.open-meteo.com/v1/forecast?latitude=%v&longitude=%v" +
+	"&daily=temperature_2m_min,temperature_2m_max,precipitation_probability_max&timezone=auto&forecast_days=1"
+
+// Chance of rain, in percent, from which we plan for rain.
+const rainLikely = 50
+
+// How long a fetched forecast is kept, and how long to wait before retrying a failed fetch.
+const (
+	forecastTTL     = time.Hour
+	forecastBackoff = time.Minute
+)
+
+// Fetches the forecast over HTTP, keeping it for an hour of the same day. Failures are kept for
+// a minute, so a broken service doesn't slow down every request.
+type httpWeather struct {
+	url    string
+	client *http.Client
+
+	mu sync.Mutex
+	// The day the forecast, or error, was fetched on.
+	day     string
+	cached  forecast
+	err     error
+	expires time.Time
+	// Closed when the fetch in flight, if any, finishes.
+	fetching chan struct{}
+}
+
+func (w *httpWeather) Forecast(ctx context.Context) (forecast, error) {
+	for {
+		w.mu.Lock()
+		now := time.Now()
+		day := now.Format(dateFormat)
+		if w.day == day && now.Before(w.expires) {
+			f, err := w.cached, w.err
+			w.mu.Unlock()
+			return f, err
+		}
+		if w.fetching != nil {
+			// Wait for the fetch already under way rather than starting another.
+			done := w.fetching
+			w.mu.Unlock()
+			select {
+			case <-done:
+				continue
+			case <-ctx.Done():
+				return forecast{}, ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		w.fetching = done
+		w.mu.Unlock()
+
+		f, err := w.fetch(ctx)
+
+		w.mu.Lock()
+		// A request which gave up says nothing about the service, so isn't kept.
+		if ctx.Err() == nil {
+			w.day, w.cached, w.err = day, f, err
+			w.expires = now.Add(forecastTTL)
+			if err != nil {
+				w.expires = now.Add(forecastBackoff)
+			}
+		}
+		w.fetching = nil
+		close(done)
+		w.mu.Unlock()
+		return f, err
+	}
+}
+
+// Fetches today's forecast.
+func (w *httpWeather) fetch(ctx context.Context) (forecast, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.url, nil)
+	if err != nil {
+		return forecast{}, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return forecast{}, fmt.Errorf("fetch forecast: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return forecast{}, fmt.Errorf("fetch forecast: %v", resp.Status)
+	}
+	var body struct {
+		Daily struct {
+			Min  []float64 `json:"temperature_2m_min"`
+			Max  []float64 `json:"temperature_2m_max"`
+			Rain []float64 `json:"precipitation_probability_max"`
+		} `json:"daily"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return forecast{}, fmt.Errorf("decode forecast: %w", err)
+	}
+	d := body.Daily
+	if len(d.Min) == 0 || len(d.Max) == 0 || len(d.Rain) == 0 {
+		return forecast{}, fmt.Errorf("decode forecast: no days")
+	}
+	f := forecast{Low: d.Min[0], High: d.Max[0], Rain: d.Rain[0] >= rainLikely}
+	f.Summary = fmt.Sprintf("%v, %.0f%% chance of rain", describeWeather(f), d.Rain[0])
+	return f, nil
+}
+
+func describeWeather(f forecast) string {
+	s := fmt.Sprintf("%.0f–%.0f°C", f.Low, f.High)
+	if f.Rain {
+		s += " ☔"
+	}
+	return s
+}
+
+// Today's forecast, or nil if no weather provider is configured.
+func todaysWeather(ctx context.Context) (*forecast, error) {
+	if weather == nil {
+		return nil, nil
+	}
+	f, err := weather.Forecast(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Whether an item suits the weather: its temperature range overlaps the day's, and it's not
+// one to keep dry on a rainy day.
+func suitsWeather(c persist.Catalog, f forecast) bool {
+	if f.Rain && c.RainOk.Valid && !c.RainOk.Bool {
+		return false
+	}
+	if c.MinTemp.Valid && c.MinTemp.Float64 > f.High {
+		return false
+	}
+	if c.MaxTemp.Valid && c.MaxTemp.Float64 < f.Low {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Serves a forecast, or a failure, counting the fetches. Fetches wait for release to close.
+func testForecastServer(t *testing.T, fail bool, release chan struct{}) (*httpWeather, *int32) {
+	t.Helper()
+	var fetches int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		if fail {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"daily": {"temperature_2m_min": [8], "temperature_2m_max": [15], "precipitation_probability_max": [70]}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return &httpWeather{url: srv.URL, client: &http.Client{Timeout: 5 * time.Second}}, &fetches
+}
+
+// Requests made while a fetch is under way share it.
+func TestHTTPWeatherSharesFetch(t *testing.T) {
+	release := make(chan struct{})
+	w, fetches := testForecastServer(t, false, release)
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := w.Forecast(context.Background())
+			if err == nil && (f.Low != 8 || f.High != 15 || !f.Rain) {
+				err = fmt.Errorf("got forecast %+v", f)
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(fetches); n != 1 {
+		t.Fatalf("fetched %v times, want 1", n)
+	}
+}
+
+// A failed fetch isn't retried until the backoff is up, nor is a forecast from another day used.
+func TestHTTPWeatherCachesFailures(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+	w, fetches := testForecastServer(t, true, release)
+	for i := 0; i < 2; i++ {
+		_, err := w.Forecast(context.Background())
+		if err == nil {
+			t.Fatal("Forecast: want an error")
+		}
+	}
+	if n := atomic.LoadInt32(fetches); n != 1 {
+		t.Fatalf("fetched %v times, want 1", n)
+	}
+
+	w.mu.Lock()
+	w.expires = time.Now().Add(-time.Second)
+	w.mu.Unlock()
+	w.Forecast(context.Background())
+	w.mu.Lock()
+	w.day = "2000-01-01"
+	w.expires = time.Now().Add(time.Hour)
+	w.mu.Unlock()
+	w.Forecast(context.Background())
+	if n := atomic.LoadInt32(fetches); n != 3 {
+		t.Fatalf("fetched %v times, want 3", n)
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/hherman1/gorgina/db/persist"
+)
+
+// How urgently a wishlist entry is wanted, from the top.
+var priorities = []struct {
+	Value int32
+	Label string
+}{
+	{1, "high"},
+	{2, "medium"},
+	{3, "low"},
+}
+
+func priorityLabel(p int32) string {
+	for _, pr := range priorities {
+		if pr.Value == p {
+			return pr.Label
+		}
+	}
+	return strconv.Itoa(int(p))
+}
+
+// A wishlist entry, along with the item it was bought as.
+type wishView struct {
+	persist.Wish
+	Item *catalogView
+	// What the item cost in the wardrobe's currency, when it can be converted.
+	Paid sql.NullFloat64
+}
+
+// What the item cost compared to what was planned, when both are known. Negative is a saving.
+func (w wishView) Overspend() sql.NullFloat64 {
+	if !w.Paid.Valid || !w.TargetPrice.Valid {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Valid: true, Float64: w.Paid.Float64 - w.TargetPrice.Float64}
+}
+
+// The link as a URL to follow, if it's one.
+func (w wishView) URL() string {
+	u, err := url.Parse(strings.TrimSpace(w.Link.String))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// The wishlist, along with the entry being edited. Target prices are in Currency.
+type wishlist struct {
+	Currency string
+	// The entry shown in the form, which is empty when adding one.
+	Editing    persist.Wish
+	Wanted     []wishView
+	Bought     []wishView
+	Categories []persist.Category
+}
+
+func loadWishlist(ctx context.Context) (wishlist, error) {
+	wid := currentWardrobe(ctx)
+	l := wishlist{Editing: persist.Wish{Priority: 2}}
+	x, err := loadExchange(ctx)
+	if err != nil {
+		return l, err
+	}
+	l.Currency = x.Home
+	ws, err := store.ListWishlist(ctx, wid)
+	if err != nil {
+		return l, fmt.Errorf("list wishlist: %w", err)
+	}
+	cs, err := store.ListCatalog(ctx, wid)
+	if err != nil {
+		return l, fmt.Errorf("list catalog: %w", err)
+	}
+	vs, err := catalogViews(ctx, cs)
+	if err != nil {
+		return l, fmt.Errorf("load catalog details: %w", err)
+	}
+	items := make(map[string]*catalogView)
+	for i := range vs {
+		items[vs[i].ID] = &vs[i]
+	}
+	for _, w := range ws {
+		if w.Bought.Valid {
+			v := wishView{Wish: w, Item: items[w.CID.String]}
+			if v.Item != nil && v.Item.Price.Valid {
+				v.Paid.Float64, v.Paid.Valid = x.convert(v.Item.Price.Float64, v.Item.Currency)
+			}
+			l.Bought = append(l.Bought, v)
+		} else {
+			l.Wanted = append(l.Wanted, wishView{Wish: w})
+		}
+	}
+	l.Categories, err = store.ListCategories(ctx, wid)
+	if err != nil {
+		return l, fmt.Errorf("list categories: %w", err)
+	}
+	return l, nil
+}
+
+// Adds the wishlist entry to the catalog, bought today for the price paid in the wardrobe's
+// currency, and remembers what it became. The entry keeps its target price, so what was
+// planned can be compared with what was spent.
+func buyWish(ctx context.Context, id string, price sql.NullFloat64) (string, error) {
+	wid := currentWardrobe(ctx)
+	cid := uuid.NewString()
+	home, err := homeCurrency(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = store.InTx(ctx, func(store Store) error {
+		w, err := store.GetWish(ctx, persist.GetWishParams{ID: id, WID: wid})
+		if err != nil {
+			return fmt.Errorf("get wish %v: %w", id, err)
+		}
+		if w.Bought.Valid {
+			return fmt.Errorf("%v was already bought", w.Title)
+		}
+		if !price.Valid {
+			price = w.TargetPrice
+		}
+		now := time.Now().UTC()
+		_, err = store.PutItem(ctx, persist.PutItemParams{
+			ID:          cid,
+			Category:    w.Category,
+			Brand:       w.Brand,
+			Title:       ns([]string{w.Title}),
+			Description: w.Notes,
+			Price:       price,
+			WID:         wid,
+			Purchased:   sql.NullTime{Valid: true, Time: dayOf(time.Now())},
+			Currency:    home,
+		})
+		if err != nil {
+			return fmt.Errorf("add to catalog: %w", err)
+		}
+		err = store.MarkWishBought(ctx, persist.MarkWishBoughtParams{
+			Bought: sql.NullTime{Valid: true, Time: now},
+			CID:    ns([]string{cid}),
+			ID:     id,
+			WID:    wid,
+		})
+		if err != nil {
+			return fmt.Errorf("mark bought: %w", err)
+		}
+		return nil
+	})
+	return cid, err
+}
+
+// Renders the wishlist, with the entry of the request's edit, e.g edit=<id>, in the form.
+func renderWishlist(response http.ResponseWriter, req *http.Request) error {
+	ctx := req.Context()
+	l, err := loadWishlist(ctx)
+	if err != nil {
+		return err
+	}
+	if id := req.URL.Query().Get("edit"); id != "" {
+		l.Editing, err = store.GetWish(ctx, persist.GetWishParams{ID: id, WID: currentWardrobe(ctx)})
+		if err != nil {
+			return fmt.Errorf("get wish %v: %w", id, err)
+		}
+	}
+	r, err := listWishlist(l)
+	if err != nil {
+		return fmt.Errorf("render wishlist: %w", err)
+	}
+	_, err = response.Write([]byte(r))
+	if err != nil {
+		return fmt.Errorf("writing response: %w", err)
+	}
+	return nil
+}
+
+func handleWishlist(response http.ResponseWriter, req *http.Request) error {
+	return renderWishlist(response, req)
+}
+
+// They added or edited a wishlist entry
+func handlePutWish(response http.ResponseWriter, req *http.Request) error {
+	err := req.ParseForm()
+	if err != nil {
+		return fmt.Errorf("parse form: %w", err)
+	}
+	id := req.Form.Get("id")
+	if id == "" {
+		id = uuid.NewString()
+	}
+	title := strings.TrimSpace(req.Form.Get("title"))
+	if title == "" {
+		return fmt.Errorf("give the wish a title")
+	}
+	// Both are copied to the catalog when the wish is bought, where they're at most 64 characters.
+	for _, f := range []string{"category", "brand"} {
+		if utf8.RuneCountInString(strings.Join(req.Form[f], " ")) > 64 {
+			return fmt.Errorf("the %v can be at most 64 characters", f)
+		}
+	}
+	target, err := parsePrice(req.Form.Get("target_price"))
+	if err != nil {
+		return err
+	}
+	priority, err := strconv.Atoi(req.Form.Get("priority"))
+	if err != nil {
+		return fmt.Errorf("parse priority %q: %w", req.Form.Get("priority"), err)
+	}
+	err = store.PutWish(req.Context(), persist.PutWishParams{
+		ID:          id,
+		WID:         currentWardrobe(req.Context()),
+		Title:       title,
+		Category:    ns(req.Form["category"]),
+		Brand:       ns(req.Form["brand"]),
+		TargetPrice: target,
+		Link:        ns(req.Form["link"]),
+		Priority:    int32(priority),
+		Notes:       ns(req.Form["notes"]),
+		Added:       time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("put wish: %w", err)
+	}
+	return renderWishlist(response, req)
+}
+
+// e.g api/wish/delete?id=...
+func handleDeleteWish(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	err := store.DeleteWish(req.Context(), persist.DeleteWishParams{ID: id, WID: currentWardrobe(req.Context())})
+	if err != nil {
+		return fmt.Errorf("delete wish %v: %w", id, err)
+	}
+	return renderWishlist(response, req)
+}
+
+// They bought something on the wishlist, for price if given or else the target price. Shows the
+// new item's edit form, to fill in the rest. e.g api/wish/buy?id=...&price=25
+func handleBuyWish(response http.ResponseWriter, req *http.Request) error {
+	id := req.FormValue("id")
+	price, err := parsePrice(req.FormValue("price"))
+	if err != nil {
+		return err
+	}
+	cid, err := buyWish(req.Context(), id, price)
+	if err != nil {
+		return fmt.Errorf("buy %v: %w", id, err)
+	}
+	req.URL.RawQuery = url.Values{"id": {cid}}.Encode()
+	return handlePutComponent(response, req)
+}